@@ -0,0 +1,249 @@
+package time
+
+import (
+	"time"
+)
+
+// MarketSession is a trading session of the US stock market.
+type MarketSession int
+
+// US stock market sessions.
+const (
+	SessionClosed     MarketSession = iota // market closed (overnight, weekend or holiday)
+	SessionPreMarket                       // 04:00 - 09:30 ET
+	SessionRegular                         // 09:30 - 16:00 ET, 13:00 ET on early close days
+	SessionAfterHours                      // regular close - 20:00 ET, 17:00 ET on early close days
+)
+
+var sessionNames = map[MarketSession]string{
+	SessionClosed:     "closed",
+	SessionPreMarket:  "pre_market",
+	SessionRegular:    "regular",
+	SessionAfterHours: "after_hours",
+}
+
+func (s MarketSession) String() string {
+	if name, ok := sessionNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// NYSE session boundaries as offsets from midnight Eastern Time.
+const (
+	nysePreMarketOpen    = 4 * time.Hour
+	nyseRegularOpen      = 9*time.Hour + 30*time.Minute
+	nyseRegularClose     = 16 * time.Hour
+	nyseEarlyClose       = 13 * time.Hour
+	nyseAfterHoursLength = 4 * time.Hour
+)
+
+// Juneteenth has been an NYSE holiday since 2022.
+const juneteenthFirstYear = 2022
+
+var newYork = Location("America/New_York") // New York *time.Location
+
+// NewYork returns New York *time.Location.
+func NewYork() *time.Location {
+	return newYork
+}
+
+// NYSEHolidays returns the NYSE full-day holidays of year, in New York time.
+func NYSEHolidays(year int) []time.Time {
+	holidays := make([]time.Time, 0, 10)
+
+	// New Year's Day on Saturday is not observed on the previous Friday.
+	if newYear := nyDate(year, time.January, 1); newYear.Weekday() != time.Saturday {
+		holidays = append(holidays, observed(newYear))
+	}
+	holidays = append(holidays,
+		nthWeekday(year, time.January, time.Monday, 3),  // Martin Luther King Jr. Day
+		nthWeekday(year, time.February, time.Monday, 3), // Washington's Birthday
+		easter(year).AddDate(0, 0, -2),                  // Good Friday
+		lastWeekday(year, time.May, time.Monday),        // Memorial Day
+	)
+	if year >= juneteenthFirstYear {
+		holidays = append(holidays, observed(nyDate(year, time.June, 19)))
+	}
+	holidays = append(holidays,
+		observed(nyDate(year, time.July, 4)),              // Independence Day
+		nthWeekday(year, time.September, time.Monday, 1),  // Labor Day
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving Day
+		observed(nyDate(year, time.December, 25)),         // Christmas Day
+	)
+
+	return holidays
+}
+
+// IsNYSEHoliday reports whether the New York date of t is an NYSE full-day holiday.
+func IsNYSEHoliday(t time.Time) bool {
+	day := nyDay(t)
+	for _, holiday := range NYSEHolidays(day.Year()) {
+		if holiday.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNYSETradingDay reports whether the New York date of t is an NYSE trading day.
+func IsNYSETradingDay(t time.Time) bool {
+	day := nyDay(t)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return !IsNYSEHoliday(day)
+}
+
+// IsNYSEEarlyClose reports whether the New York date of t is an NYSE early close (13:00 ET) day:
+// the day before Independence Day, the day after Thanksgiving and Christmas Eve.
+func IsNYSEEarlyClose(t time.Time) bool {
+	day := nyDay(t)
+	if !IsNYSETradingDay(day) {
+		return false
+	}
+
+	year := day.Year()
+	switch {
+	case day.Equal(nyDate(year, time.July, 3)):
+		return true
+	case day.Equal(nthWeekday(year, time.November, time.Thursday, 4).AddDate(0, 0, 1)):
+		return true
+	case day.Equal(nyDate(year, time.December, 24)):
+		return true
+	}
+	return false
+}
+
+// NYSEOpenClose returns the regular session open and close of the New York date of t.
+// ok is false if that date is not a trading day.
+func NYSEOpenClose(t time.Time) (open, close time.Time, ok bool) {
+	day := nyDay(t)
+	if !IsNYSETradingDay(day) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	open = atOffset(day, nyseRegularOpen)
+	close = atOffset(day, nyseRegularClose)
+	if IsNYSEEarlyClose(day) {
+		close = atOffset(day, nyseEarlyClose)
+	}
+	return open, close, true
+}
+
+// NYSESession returns the market session at t.
+func NYSESession(t time.Time) MarketSession {
+	open, close, ok := NYSEOpenClose(t)
+	if !ok {
+		return SessionClosed
+	}
+
+	day := nyDay(t)
+	switch {
+	case t.Before(atOffset(day, nysePreMarketOpen)):
+		return SessionClosed
+	case t.Before(open):
+		return SessionPreMarket
+	case t.Before(close):
+		return SessionRegular
+	case t.Before(close.Add(nyseAfterHoursLength)):
+		return SessionAfterHours
+	}
+	return SessionClosed
+}
+
+// NextNYSESessionChange returns the first session boundary strictly after t.
+func NextNYSESessionChange(t time.Time) time.Time {
+	day := nyDay(t)
+	for {
+		if open, close, ok := NYSEOpenClose(day); ok {
+			for _, boundary := range []time.Time{atOffset(day, nysePreMarketOpen), open, close, close.Add(nyseAfterHoursLength)} {
+				if boundary.After(t) {
+					return boundary
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// LastNYSEClose returns the most recent regular session close at or before t.
+func LastNYSEClose(t time.Time) time.Time {
+	day := nyDay(t)
+	for {
+		if _, close, ok := NYSEOpenClose(day); ok && !close.After(t) {
+			return close
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+}
+
+// AddNYSETradingDays returns the New York midnight of the trading day n trading days after
+// (or before, if n is negative) the New York date of t.
+func AddNYSETradingDays(t time.Time, n int) time.Time {
+	day := nyDay(t)
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		day = day.AddDate(0, 0, step)
+		if IsNYSETradingDay(day) {
+			n--
+		}
+	}
+	return day
+}
+
+func nyDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, newYork)
+}
+
+func nyDay(t time.Time) time.Time {
+	year, month, day := t.In(newYork).Date()
+	return nyDate(year, month, day)
+}
+
+// atOffset returns the wall clock time offset after midnight of day in New York.
+func atOffset(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(offset/time.Minute), 0, 0, newYork)
+}
+
+// observed moves a Saturday holiday to Friday and a Sunday holiday to Monday.
+func observed(day time.Time) time.Time {
+	switch day.Weekday() {
+	case time.Saturday:
+		return day.AddDate(0, 0, -1)
+	case time.Sunday:
+		return day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := nyDate(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+(n-1)*7)
+}
+
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := nyDate(year, month+1, 0)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// easter returns Easter Sunday of year (Gregorian calendar, anonymous algorithm).
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return nyDate(year, time.Month(month), day)
+}
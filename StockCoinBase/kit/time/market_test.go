@@ -0,0 +1,101 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nyTime(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, NewYork())
+}
+
+func TestNYSEHolidays(t *testing.T) {
+	assertion := assert.New(t)
+
+	expected := []string{
+		"2024-01-01", "2024-01-15", "2024-02-19", "2024-03-29", "2024-05-27",
+		"2024-06-19", "2024-07-04", "2024-09-02", "2024-11-28", "2024-12-25",
+	}
+	holidays := NYSEHolidays(2024)
+	assertion.Len(holidays, len(expected))
+	for i, holiday := range holidays {
+		assertion.Equal(expected[i], holiday.Format("2006-01-02"))
+	}
+
+	// 2022-01-01 is a Saturday and is not observed on 2021-12-31; no Juneteenth before 2022
+	assertion.False(IsNYSEHoliday(nyTime(2021, time.December, 31, 12, 0)))
+	assertion.False(IsNYSEHoliday(nyTime(2021, time.June, 18, 12, 0)))
+	// 2026-07-04 is a Saturday, observed on Friday
+	assertion.True(IsNYSEHoliday(nyTime(2026, time.July, 3, 12, 0)))
+	// 2022-12-25 is a Sunday, observed on Monday
+	assertion.True(IsNYSEHoliday(nyTime(2022, time.December, 26, 12, 0)))
+	// Good Friday 2025, Easter is April 20
+	assertion.True(IsNYSEHoliday(nyTime(2025, time.April, 18, 12, 0)))
+}
+
+func TestIsNYSETradingDay(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.True(IsNYSETradingDay(nyTime(2024, time.March, 28, 12, 0)))
+	assertion.False(IsNYSETradingDay(nyTime(2024, time.March, 29, 12, 0)))
+	assertion.False(IsNYSETradingDay(nyTime(2024, time.March, 30, 12, 0)))
+	// Saturday morning in Shanghai is still Friday in New York
+	assertion.True(IsNYSETradingDay(time.Date(2024, time.March, 9, 8, 0, 0, 0, Shanghai())))
+}
+
+func TestIsNYSEEarlyClose(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.True(IsNYSEEarlyClose(nyTime(2024, time.July, 3, 12, 0)))
+	assertion.True(IsNYSEEarlyClose(nyTime(2024, time.November, 29, 12, 0)))
+	assertion.True(IsNYSEEarlyClose(nyTime(2024, time.December, 24, 12, 0)))
+	// holidays observed on the usual early close dates are full closures
+	assertion.False(IsNYSEEarlyClose(nyTime(2026, time.July, 3, 12, 0)))
+	assertion.False(IsNYSEEarlyClose(nyTime(2021, time.December, 24, 12, 0)))
+	assertion.False(IsNYSEEarlyClose(nyTime(2024, time.July, 5, 12, 0)))
+}
+
+func TestNYSESession(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.Equal(SessionClosed, NYSESession(nyTime(2024, time.March, 28, 3, 59)))
+	assertion.Equal(SessionPreMarket, NYSESession(nyTime(2024, time.March, 28, 4, 0)))
+	assertion.Equal(SessionRegular, NYSESession(nyTime(2024, time.March, 28, 9, 30)))
+	assertion.Equal(SessionAfterHours, NYSESession(nyTime(2024, time.March, 28, 16, 0)))
+	assertion.Equal(SessionClosed, NYSESession(nyTime(2024, time.March, 28, 20, 0)))
+	assertion.Equal(SessionClosed, NYSESession(nyTime(2024, time.March, 29, 10, 0)))
+	// early close at 13:00, after hours until 17:00
+	assertion.Equal(SessionAfterHours, NYSESession(nyTime(2024, time.December, 24, 13, 0)))
+	assertion.Equal(SessionClosed, NYSESession(nyTime(2024, time.December, 24, 17, 0)))
+	// the open stays at 09:30 local time across the DST switch
+	assertion.Equal(SessionRegular, NYSESession(time.Date(2024, time.March, 11, 13, 30, 0, 0, UTC())))
+	assertion.Equal(SessionPreMarket, NYSESession(time.Date(2024, time.March, 8, 13, 30, 0, 0, UTC())))
+}
+
+func TestNextNYSESessionChange(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.True(nyTime(2024, time.March, 28, 9, 30).Equal(NextNYSESessionChange(nyTime(2024, time.March, 28, 5, 0))))
+	assertion.True(nyTime(2024, time.December, 24, 13, 0).Equal(NextNYSESessionChange(nyTime(2024, time.December, 24, 10, 0))))
+	// Good Friday skips to Monday pre-market
+	assertion.True(nyTime(2024, time.April, 1, 4, 0).Equal(NextNYSESessionChange(nyTime(2024, time.March, 28, 20, 0))))
+}
+
+func TestLastNYSEClose(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.True(nyTime(2024, time.March, 28, 16, 0).Equal(LastNYSEClose(nyTime(2024, time.April, 1, 9, 0))))
+	assertion.True(nyTime(2024, time.March, 28, 16, 0).Equal(LastNYSEClose(nyTime(2024, time.March, 28, 16, 0))))
+	assertion.True(nyTime(2024, time.March, 27, 16, 0).Equal(LastNYSEClose(nyTime(2024, time.March, 28, 15, 0))))
+	assertion.True(nyTime(2024, time.December, 24, 13, 0).Equal(LastNYSEClose(nyTime(2024, time.December, 25, 12, 0))))
+}
+
+func TestAddNYSETradingDays(t *testing.T) {
+	assertion := assert.New(t)
+
+	assertion.Equal("2024-04-01", AddNYSETradingDays(nyTime(2024, time.March, 28, 12, 0), 1).Format("2006-01-02"))
+	assertion.Equal("2024-03-20", AddNYSETradingDays(nyTime(2024, time.April, 1, 12, 0), -7).Format("2006-01-02"))
+	assertion.Equal("2024-03-28", AddNYSETradingDays(nyTime(2024, time.March, 28, 12, 0), 0).Format("2006-01-02"))
+}
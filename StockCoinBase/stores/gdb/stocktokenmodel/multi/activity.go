@@ -0,0 +1,37 @@
+package multi
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// (1:Buy,2:Sell)
+const (
+	Buy  = 1
+	Sell = 2
+)
+
+// Activity StockToken买卖记录，金额均为链上原始精度（代币18位，USDT 6位，价格18位）
+type Activity struct {
+	Id int64 `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	//1:Buy,2:Sell
+	ActivityType   int             `json:"activity_type" gorm:"column:activity_type;type:tinyint(1);not null"`
+	UserAddress    string          `json:"user_address" gorm:"column:user_address;type:varchar(42);not null"`
+	TokenAddress   string          `json:"token_address" gorm:"column:token_address;type:varchar(42);not null;default:''"`
+	TokenSymbol    string          `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	CurrentPrice   decimal.Decimal `json:"current_price" gorm:"column:current_price;type:decimal(36);not null;default:0"`
+	TokenAmount    decimal.Decimal `json:"token_amount" gorm:"column:token_amount;type:decimal(36);not null;default:0"`
+	CurrencyAmount decimal.Decimal `json:"currency_amount" gorm:"column:currency_amount;type:decimal(36);not null;default:0"`
+	FeeAmount      decimal.Decimal `json:"fee_amount" gorm:"column:fee_amount;type:decimal(36);not null;default:0;comment:手续费折合USDT数量"`
+	BlockNumber    int64           `json:"block_number" gorm:"column:block_number;type:bigint(20);not null"`
+	TxHash         string          `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);not null"`
+	LogIndex       int64           `json:"log_index" gorm:"column:log_index;type:int;not null;default:0"`
+	EventTime      int64           `json:"event_time" gorm:"column:event_time;type:bigint(20);default:0;comment:链上事件发生的时间"`
+	CreateTime     int64           `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime     int64           `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func ActivityTableName(chainName string) string {
+	return fmt.Sprintf("st_activity_%s", chainName)
+}
@@ -0,0 +1,40 @@
+package multi
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Position 用户在单个股票代币上的持仓，由买卖事件按顺序累计得到，数量及金额均为实际精度（代币、USDT）
+type Position struct {
+	Id              int64           `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	UserAddress     string          `json:"user_address" gorm:"column:user_address;type:varchar(42);not null"`
+	TokenAddress    string          `json:"token_address" gorm:"column:token_address;type:varchar(42);not null;default:''"`
+	TokenSymbol     string          `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	Quantity        decimal.Decimal `json:"quantity" gorm:"column:quantity;type:decimal(36,18);not null;default:0;comment:持仓数量"`
+	AvgCost         decimal.Decimal `json:"avg_cost" gorm:"column:avg_cost;type:decimal(36,18);not null;default:0;comment:平均成本(USDT)"`
+	CostBasis       decimal.Decimal `json:"cost_basis" gorm:"column:cost_basis;type:decimal(36,18);not null;default:0;comment:持仓总成本(USDT)"`
+	RealizedPnl     decimal.Decimal `json:"realized_pnl" gorm:"column:realized_pnl;type:decimal(36,18);not null;default:0;comment:已实现盈亏(USDT)"`
+	FeesPaid        decimal.Decimal `json:"fees_paid" gorm:"column:fees_paid;type:decimal(36,18);not null;default:0;comment:累计手续费(USDT)"`
+	BuyCount        int64           `json:"buy_count" gorm:"column:buy_count;type:bigint(20);not null;default:0"`
+	SellCount       int64           `json:"sell_count" gorm:"column:sell_count;type:bigint(20);not null;default:0"`
+	LastBlockNumber int64           `json:"last_block_number" gorm:"column:last_block_number;type:bigint(20);not null;default:0"`
+	LastEventTime   int64           `json:"last_event_time" gorm:"column:last_event_time;type:bigint(20);not null;default:0"`
+	CreateTime      int64           `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime      int64           `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+// MarketValue 按price计算的持仓市值
+func (p *Position) MarketValue(price decimal.Decimal) decimal.Decimal {
+	return p.Quantity.Mul(price)
+}
+
+// UnrealizedPnl 按price（通常为StockInfo.CurrentPrice）计算的未实现盈亏
+func (p *Position) UnrealizedPnl(price decimal.Decimal) decimal.Decimal {
+	return p.MarketValue(price).Sub(p.CostBasis)
+}
+
+func PositionTableName(chainName string) string {
+	return fmt.Sprintf("st_position_%s", chainName)
+}
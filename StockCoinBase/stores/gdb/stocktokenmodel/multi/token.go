@@ -0,0 +1,21 @@
+package multi
+
+import (
+	"fmt"
+)
+
+// Token 通过TokenFactory的TokenCreated事件发现的StockToken合约
+type Token struct {
+	Id           int64  `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	TokenAddress string `json:"token_address" gorm:"column:token_address;type:varchar(42);not null"`
+	Name         string `json:"name" gorm:"column:name;type:varchar(100);not null;default:''"`
+	Symbol       string `json:"symbol" gorm:"column:symbol;type:varchar(20);not null"`
+	BlockNumber  int64  `json:"block_number" gorm:"column:block_number;type:bigint(20);not null"`
+	TxHash       string `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);not null"`
+	CreateTime   int64  `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime   int64  `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func TokenTableName(chainName string) string {
+	return fmt.Sprintf("st_token_%s", chainName)
+}
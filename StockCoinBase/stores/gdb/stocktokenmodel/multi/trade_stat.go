@@ -0,0 +1,30 @@
+package multi
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// TradeStat 每个股票代币最近1小时、24小时、7天的链上成交统计，由买卖记录定时汇总，成交额为USDT（实际精度）
+type TradeStat struct {
+	Id           int64           `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	TokenAddress string          `json:"token_address" gorm:"column:token_address;type:varchar(42);not null;default:''"`
+	TokenSymbol  string          `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	Volume1h     decimal.Decimal `json:"volume_1h" gorm:"column:volume_1h;type:decimal(36,6);not null;default:0;comment:1小时成交额(USDT)"`
+	Volume24h    decimal.Decimal `json:"volume_24h" gorm:"column:volume_24h;type:decimal(36,6);not null;default:0;comment:24小时成交额(USDT)"`
+	Volume7d     decimal.Decimal `json:"volume_7d" gorm:"column:volume_7d;type:decimal(36,6);not null;default:0;comment:7天成交额(USDT)"`
+	Trades1h     int64           `json:"trades_1h" gorm:"column:trades_1h;type:bigint(20);not null;default:0;comment:1小时成交笔数"`
+	Trades24h    int64           `json:"trades_24h" gorm:"column:trades_24h;type:bigint(20);not null;default:0;comment:24小时成交笔数"`
+	Trades7d     int64           `json:"trades_7d" gorm:"column:trades_7d;type:bigint(20);not null;default:0;comment:7天成交笔数"`
+	Traders1h    int64           `json:"traders_1h" gorm:"column:traders_1h;type:bigint(20);not null;default:0;comment:1小时交易地址数"`
+	Traders24h   int64           `json:"traders_24h" gorm:"column:traders_24h;type:bigint(20);not null;default:0;comment:24小时交易地址数"`
+	Traders7d    int64           `json:"traders_7d" gorm:"column:traders_7d;type:bigint(20);not null;default:0;comment:7天交易地址数"`
+	StatTime     int64           `json:"stat_time" gorm:"column:stat_time;type:bigint(20);not null;default:0;comment:统计截止时间"`
+	CreateTime   int64           `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime   int64           `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func TradeStatTableName(chainName string) string {
+	return fmt.Sprintf("st_trade_stat_%s", chainName)
+}
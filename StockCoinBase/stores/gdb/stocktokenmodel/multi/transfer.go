@@ -0,0 +1,59 @@
+package multi
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Transfer StockToken的ERC-20 Transfer记录，数量为链上原始精度（18位）
+type Transfer struct {
+	Id           int64           `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	TokenAddress string          `json:"token_address" gorm:"column:token_address;type:varchar(42);not null"`
+	TokenSymbol  string          `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	FromAddress  string          `json:"from_address" gorm:"column:from_address;type:varchar(42);not null"`
+	ToAddress    string          `json:"to_address" gorm:"column:to_address;type:varchar(42);not null"`
+	Amount       decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(65);not null;default:0"`
+	BlockNumber  int64           `json:"block_number" gorm:"column:block_number;type:bigint(20);not null"`
+	TxHash       string          `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);not null"`
+	LogIndex     int64           `json:"log_index" gorm:"column:log_index;type:int;not null;default:0"`
+	EventTime    int64           `json:"event_time" gorm:"column:event_time;type:bigint(20);default:0;comment:链上事件发生的时间"`
+	CreateTime   int64           `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime   int64           `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func TransferTableName(chainName string) string {
+	return fmt.Sprintf("st_transfer_%s", chainName)
+}
+
+// Balance 由Transfer记录累计得到的持有人余额，数量为链上原始精度（18位）
+type Balance struct {
+	Id              int64           `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	TokenAddress    string          `json:"token_address" gorm:"column:token_address;type:varchar(42);not null"`
+	TokenSymbol     string          `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	HolderAddress   string          `json:"holder_address" gorm:"column:holder_address;type:varchar(42);not null"`
+	Balance         decimal.Decimal `json:"balance" gorm:"column:balance;type:decimal(65);not null;default:0"`
+	LastBlockNumber int64           `json:"last_block_number" gorm:"column:last_block_number;type:bigint(20);not null;default:0"`
+	CreateTime      int64           `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime      int64           `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func BalanceTableName(chainName string) string {
+	return fmt.Sprintf("st_balance_%s", chainName)
+}
+
+// HolderCount 每个股票代币每天的持有人数量（当天最后一次变动后的数量），不包含代币合约自身
+type HolderCount struct {
+	Id           int64  `json:"id" gorm:"primaryKey;autoIncrement;column:id;not null"`
+	TokenAddress string `json:"token_address" gorm:"column:token_address;type:varchar(42);not null"`
+	TokenSymbol  string `json:"token_symbol" gorm:"column:token_symbol;type:varchar(20);not null"`
+	Day          int64  `json:"day" gorm:"column:day;type:bigint(20);not null;comment:当天0点(UTC)的时间戳"`
+	HolderCount  int64  `json:"holder_count" gorm:"column:holder_count;type:bigint(20);not null;default:0"`
+	BlockNumber  int64  `json:"block_number" gorm:"column:block_number;type:bigint(20);not null;default:0"`
+	CreateTime   int64  `json:"create_time" gorm:"column:create_time;type:bigint(20);autoCreateTime:milli;comment:创建时间"` // 创建时间
+	UpdateTime   int64  `json:"update_time" gorm:"column:update_time;type:bigint(20);autoUpdateTime:milli;comment:更新时间"` // 更新时间
+}
+
+func HolderCountTableName(chainName string) string {
+	return fmt.Sprintf("st_holder_count_%s", chainName)
+}
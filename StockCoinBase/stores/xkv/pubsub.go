@@ -0,0 +1,57 @@
+package xkv
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/pkg/errors"
+	red "github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// Publish 向channel发布消息，返回收到消息的订阅者数量
+func (s *Store) Publish(ctx context.Context, channel string, message interface{}) (int64, error) {
+	n, err := s.Redis.PublishCtx(ctx, channel, message)
+	if err != nil {
+		return 0, errors.Wrapf(err, "publish to %s err", channel)
+	}
+	return n, nil
+}
+
+// Subscribe 订阅channel，消息从返回的PubSub.Channel()中读取，由调用方负责Close
+func (s *Store) Subscribe(ctx context.Context, channels ...string) (*red.PubSub, error) {
+	pubsub := s.pubsubClient().Subscribe(ctx, channels...)
+	// 等待订阅确认，确保返回后不会丢失随后发布的消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, errors.Wrapf(err, "subscribe to %v err", channels)
+	}
+	return pubsub, nil
+}
+
+// pubsubClient go-zero的redis未封装订阅，按相同的节点配置单独建立go-redis连接
+func (s *Store) pubsubClient() red.UniversalClient {
+	s.subOnce.Do(func() {
+		var tlsConfig *tls.Config
+		if s.conf.Tls {
+			tlsConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+
+		if s.conf.Type == redis.ClusterType {
+			s.subClient = red.NewClusterClient(&red.ClusterOptions{
+				Addrs:     []string{s.conf.Host},
+				Username:  s.conf.User,
+				Password:  s.conf.Pass,
+				TLSConfig: tlsConfig,
+			})
+			return
+		}
+		s.subClient = red.NewClient(&red.Options{
+			Addr:      s.conf.Host,
+			Username:  s.conf.User,
+			Password:  s.conf.Pass,
+			TLSConfig: tlsConfig,
+		})
+	})
+	return s.subClient
+}
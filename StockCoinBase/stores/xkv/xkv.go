@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"reflect"
+	"sync"
 
 	"github.com/pkg/errors"
+	red "github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/kv"
 	"github.com/zeromicro/go-zero/core/stores/redis"
@@ -26,6 +28,10 @@ return current;`
 type Store struct {
 	kv.Store
 	Redis *redis.Redis
+
+	conf      redis.RedisConf // 订阅使用的节点配置，与Redis相同
+	subOnce   sync.Once
+	subClient red.UniversalClient
 }
 
 // NewStore 新建键值存取器
@@ -38,6 +44,7 @@ func NewStore(c kv.KvConf) *Store {
 	return &Store{
 		Store: kv.NewStore(c),
 		Redis: cn,
+		conf:  c[0].RedisConf,
 	}
 }
 
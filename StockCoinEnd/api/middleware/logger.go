@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,14 +19,24 @@ type BodyLogWriter struct {
 }
 
 func (w BodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	if !w.isStream() {
+		w.body.Write(b)
+	}
 	return w.ResponseWriter.Write(b)
 }
 func (w BodyLogWriter) WriteString(s string) (int, error) {
-	w.body.WriteString(s)
+	if !w.isStream() {
+		w.body.WriteString(s)
+	}
 	return w.ResponseWriter.WriteString(s)
 }
 
+// isStream SSE等长连接推送及图片等二进制内容不记录响应体，避免缓存持续增长
+func (w BodyLogWriter) isStream() bool {
+	contentType := w.Header().Get("Content-Type")
+	return strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "image/")
+}
+
 // RLog 请求响应日志打印处理
 // RLog() 是一个中间件函数,用于记录HTTP请求和响应的详细日志
 // 主要功能包括:
@@ -22,6 +22,28 @@ func loadV1(r *gin.Engine, svcCtx *svc.ServerCtx) {
 		user.GET("/:address/login-message", v1.GetLoginMessageHandler(svcCtx)) // 生成login签名信息
 		user.POST("/login", v1.UserLoginHandler(svcCtx))                       // 登陆
 		user.GET("/:address/sig-status", v1.GetSigStatusHandler(svcCtx))       // 获取用户签名状态
+
+		watchlists := user.Group("/watchlists", middleware.AuthMiddleWare(svcCtx.KvStore))
+		{
+			watchlists.GET("", v1.GetWatchlists(svcCtx))                              // 获取用户自选股列表
+			watchlists.POST("", v1.CreateWatchlist(svcCtx))                           // 创建自选股列表
+			watchlists.PUT("/order", v1.ReorderWatchlists(svcCtx))                    // 调整自选股列表顺序
+			watchlists.GET("/:id", v1.GetWatchlist(svcCtx))                           // 获取指定自选股列表
+			watchlists.PUT("/:id", v1.RenameWatchlist(svcCtx))                        // 重命名自选股列表
+			watchlists.DELETE("/:id", v1.DeleteWatchlist(svcCtx))                     // 删除自选股列表
+			watchlists.PUT("/:id/items", v1.SetWatchlistTickers(svcCtx))              // 设置列表股票及顺序
+			watchlists.POST("/:id/items", v1.AddWatchlistTicker(svcCtx))              // 添加股票到列表
+			watchlists.DELETE("/:id/items/:ticker", v1.RemoveWatchlistTicker(svcCtx)) // 从列表移除股票
+		}
+
+		alerts := user.Group("/alerts", middleware.AuthMiddleWare(svcCtx.KvStore))
+		{
+			alerts.GET("", v1.GetPriceAlerts(svcCtx))                     // 获取用户价格提醒
+			alerts.POST("", v1.CreatePriceAlert(svcCtx))                  // 创建价格提醒
+			alerts.GET("/deliveries", v1.GetPriceAlertDeliveries(svcCtx)) // 获取价格提醒触发记录
+			alerts.PUT("/:id/active", v1.SetPriceAlertActive(svcCtx))     // 启用/停用价格提醒
+			alerts.DELETE("/:id", v1.DeletePriceAlert(svcCtx))            // 删除价格提醒
+		}
 	}
 
 	collections := apiV1.Group("/collections")
@@ -50,10 +72,13 @@ func loadV1(r *gin.Engine, svcCtx *svc.ServerCtx) {
 
 	portfolio := apiV1.Group("/portfolio")
 	{
-		portfolio.GET("/collections", v1.UserMultiChainCollectionsHandler(svcCtx)) // 获取用户拥有Collection信息
-		portfolio.GET("/items", v1.UserMultiChainItemsHandler(svcCtx))             // 查询用户拥有nft的Item基本信息
-		portfolio.GET("/listings", v1.UserMultiChainListingsHandler(svcCtx))       // 查询用户挂单的Listing信息
-		portfolio.GET("/bids", v1.UserMultiChainBidsHandler(svcCtx))               // 查询用户挂单的Bids信息
+		portfolio.GET("/collections", v1.UserMultiChainCollectionsHandler(svcCtx))        // 获取用户拥有Collection信息
+		portfolio.GET("/items", v1.UserMultiChainItemsHandler(svcCtx))                    // 查询用户拥有nft的Item基本信息
+		portfolio.GET("/listings", v1.UserMultiChainListingsHandler(svcCtx))              // 查询用户挂单的Listing信息
+		portfolio.GET("/bids", v1.UserMultiChainBidsHandler(svcCtx))                      // 查询用户挂单的Bids信息
+		portfolio.GET("/stocks", v1.UserStockPortfolioHandler(svcCtx))                    // 查询登录用户持有的股票代币及盈亏
+		portfolio.GET("/stocks/trades", v1.UserStockTradesHandler(svcCtx))                // 导出登录用户的股票代币买卖记录(json/csv)
+		portfolio.GET("/stocks/realized-gains", v1.UserStockRealizedGainsHandler(svcCtx)) // 按FIFO/LIFO/平均成本计算已实现收益(json/csv)
 	}
 
 	orders := apiV1.Group("/bid-orders")
@@ -77,9 +102,23 @@ func loadV1(r *gin.Engine, svcCtx *svc.ServerCtx) {
 	}
 	stocks := apiV1.Group("/stocks")
 	{
-		stocks.GET("", v1.GetStockList(svcCtx))              // 分页批量获取stock信息
-		stocks.GET("/overview", v1.GetOverview(svcCtx))      // 获取股票总览信息
-		stocks.GET("/:code/price", v1.GetStockPrice(svcCtx)) // 获取股票价格
+		stocks.GET("", v1.GetStockList(svcCtx))                                     // 分页批量获取stock信息
+		stocks.GET("/overview", v1.GetOverview(svcCtx))                             // 获取股票总览信息
+		stocks.GET("/overview/history", v1.GetOverviewHistory(svcCtx))              // 获取平台总览走势(总市值、TVL、成交额)
+		stocks.GET("/stream", v1.StreamStockPrices(svcCtx))                         // 订阅股票价格实时推送(SSE)
+		stocks.GET("/:code/price", v1.GetStockPrice(svcCtx))                        // 获取股票价格
+		stocks.GET("/:code/candles", v1.GetStockCandles(svcCtx))                    // 获取股票K线
+		stocks.GET("/:code/actions", v1.GetCorporateActions(svcCtx))                // 获取股票拆股、分红记录
+		stocks.GET("/:code/token", v1.GetStockTokenInfo(svcCtx))                    // 获取股票代币合约交易参数及储备
+		stocks.GET("/:code/quote", v1.GetStockQuote(svcCtx))                        // 获取买卖预估及建议的最少可得数量
+		stocks.GET("/:code/update-data", v1.GetOracleUpdateData(svcCtx))            // 获取预言机更新数据及更新费用
+		stocks.GET("/:code/holders", v1.GetStockHolders(svcCtx))                    // 获取股票代币持有人数量及持仓排行
+		stocks.GET("/:code/holders/history", v1.GetStockHolderCountHistory(svcCtx)) // 获取股票代币每天的持有人数量
+	}
+	assets := apiV1.Group("/assets")
+	{
+		assets.GET("/placeholder/:seed", v1.GetAssetPlaceholder(svcCtx)) // 获取占位图
+		assets.GET("/:id", v1.GetAsset(svcCtx))                          // 通过图片代理获取logo、NFT图片及缩略图
 	}
 	swagger := gin.Default()
 	// 注册 Swagger 路由
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/asset"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/v1"
+)
+
+const (
+	assetCacheControl       = "public, max-age=31536000, immutable" // id由来源地址决定，内容不会变化
+	placeholderCacheControl = "public, max-age=300"                 // 占位图短暂缓存，来源恢复后可尽快换成真实图片
+)
+
+// GetAsset 通过图片代理获取图片，size为缩略图尺寸（如64/128/256/512），不传时返回原图
+func GetAsset(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size, ok := assetSize(c)
+		if !ok {
+			return
+		}
+
+		img, err := service.GetAsset(c.Request.Context(), svcCtx, c.Param("id"), size)
+		if err != nil {
+			serviceError(c, err, "query asset err.")
+			return
+		}
+
+		writeAsset(c, img)
+	}
+}
+
+// GetAssetPlaceholder 获取占位图，同一seed总是返回相同颜色
+func GetAssetPlaceholder(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size, ok := assetSize(c)
+		if !ok {
+			return
+		}
+
+		img, err := service.GetAssetPlaceholder(svcCtx, c.Param("seed"), size)
+		if err != nil {
+			serviceError(c, err, "query asset placeholder err.")
+			return
+		}
+
+		writeAsset(c, img)
+	}
+}
+
+func assetSize(c *gin.Context) (int, bool) {
+	v := c.Query("size")
+	if v == "" {
+		return 0, true
+	}
+
+	size, err := strconv.Atoi(v)
+	if err != nil || size <= 0 {
+		xhttp.Error(c, errcode.ErrInvalidParams)
+		return 0, false
+	}
+	return size, true
+}
+
+func writeAsset(c *gin.Context, img *asset.Image) {
+	if img.Placeholder {
+		c.Header("Cache-Control", placeholderCacheControl)
+	} else {
+		c.Header("Cache-Control", assetCacheControl)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
@@ -1,10 +1,15 @@
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
 	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
 
 	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
@@ -169,3 +174,124 @@ func UserMultiChainBidsHandler(svcCtx *svc.ServerCtx) gin.HandlerFunc {
 		xhttp.OkJson(c, res)
 	}
 }
+
+// UserStockPortfolioHandler 获取登录用户（所有session对应的地址）持有的股票代币、成本及盈亏，currency为换算币种，默认USD
+func UserStockPortfolioHandler(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddrs, ok := authUserAddresses(c, svcCtx)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetStockPortfolio(c.Request.Context(), svcCtx, userAddrs, c.Query("currency"))
+		if err != nil {
+			serviceError(c, err, "query user stock portfolio err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// exportTimeRange 解析导出的时间范围，from/to为unix时间戳（秒或毫秒），默认为全部记录
+func exportTimeRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, to := time.Unix(0, 0), time.Now()
+	if v := c.Query("from"); v != "" {
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return from, to, false
+		}
+		from = timeUtil.UnixToTime(ts)
+	}
+	if v := c.Query("to"); v != "" {
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return from, to, false
+		}
+		to = timeUtil.UnixToTime(ts)
+	}
+	if from.After(to) {
+		xhttp.Error(c, errcode.NewCustomErr("from must be before to"))
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// writeCSV 以附件形式返回write生成的CSV
+func writeCSV(c *gin.Context, filename string, write func(w *bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		serviceError(c, err, "write csv err.")
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// UserStockTradesHandler 导出登录用户所有地址的股票代币买卖记录，format为json(默认)或csv，from/to为时间范围
+func UserStockTradesHandler(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddrs, ok := authUserAddresses(c, svcCtx)
+		if !ok {
+			return
+		}
+		format := c.DefaultQuery("format", types.ExportFormatJSON)
+		if format != types.ExportFormatJSON && format != types.ExportFormatCSV {
+			xhttp.Error(c, errcode.NewCustomErr("unsupported format: "+format))
+			return
+		}
+		from, to, ok := exportTimeRange(c)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetStockTrades(c.Request.Context(), svcCtx, userAddrs, from, to)
+		if err != nil {
+			serviceError(c, err, "query user stock trades err.")
+			return
+		}
+
+		if format == types.ExportFormatCSV {
+			writeCSV(c, "stock_trades.csv", func(w *bytes.Buffer) error {
+				return service.WriteStockTradesCSV(w, res.Trades)
+			})
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// UserStockRealizedGainsHandler 按method(fifo/lifo/average，默认fifo)计算登录用户在from/to范围内卖出的已实现收益，format为json(默认)或csv
+func UserStockRealizedGainsHandler(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddrs, ok := authUserAddresses(c, svcCtx)
+		if !ok {
+			return
+		}
+		format := c.DefaultQuery("format", types.ExportFormatJSON)
+		if format != types.ExportFormatJSON && format != types.ExportFormatCSV {
+			xhttp.Error(c, errcode.NewCustomErr("unsupported format: "+format))
+			return
+		}
+		from, to, ok := exportTimeRange(c)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetStockRealizedGains(c.Request.Context(), svcCtx, userAddrs, c.DefaultQuery("method", types.LotMethodFIFO), from, to)
+		if err != nil {
+			serviceError(c, err, "query user stock realized gains err.")
+			return
+		}
+
+		if format == types.ExportFormatCSV {
+			writeCSV(c, "stock_realized_gains_"+res.Method+".csv", func(w *bytes.Buffer) error {
+				return service.WriteStockRealizedGainsCSV(w, res)
+			})
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/v1"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	defaultAlertDeliveryPageSize = 20
+	maxAlertDeliveryPageSize     = 100
+)
+
+// 获取用户的全部价格提醒
+func GetPriceAlerts(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetPriceAlerts(c.Request.Context(), svcCtx, userAddr)
+		if err != nil {
+			serviceError(c, err, "query price alerts err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 创建价格提醒：price_above/price_below 穿越价格阈值，day_change 当日涨跌幅超过阈值（百分比）
+func CreatePriceAlert(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		var req types.CreatePriceAlertReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		res, err := service.CreatePriceAlert(c.Request.Context(), svcCtx, userAddr, &req)
+		if err != nil {
+			serviceError(c, err, "create price alert err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 启用或停用价格提醒
+func SetPriceAlertActive(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var req types.SetPriceAlertActiveReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		if err := service.SetPriceAlertActive(c.Request.Context(), svcCtx, userAddr, id, req.IsActive); err != nil {
+			serviceError(c, err, "update price alert err.")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// 删除价格提醒
+func DeletePriceAlert(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+
+		if err := service.DeletePriceAlert(c.Request.Context(), svcCtx, userAddr, id); err != nil {
+			serviceError(c, err, "delete price alert err.")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// 分页获取价格提醒的触发记录
+func GetPriceAlertDeliveries(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+
+		page, pageSize := 1, defaultAlertDeliveryPageSize
+		if v := c.Query("pageNum"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+			page = n
+		}
+		if v := c.Query("pageSize"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxAlertDeliveryPageSize {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+			pageSize = n
+		}
+
+		res, err := service.GetPriceAlertDeliveries(c.Request.Context(), svcCtx, userAddr, page, pageSize)
+		if err != nil {
+			serviceError(c, err, "query price alert deliveries err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/v1"
+)
+
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	defaultMaxStreamTickers  = 50
+)
+
+// 以Server-Sent Events推送股票价格及摘要更新，tickers为逗号分隔的订阅列表。
+// 连接建立后先推送当前价格（event: price），之后每轮行情更新推送一次，并定时发送心跳（event: heartbeat）
+func StreamStockPrices(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if svcCtx.PriceHub == nil {
+			xhttp.Error(c, errcode.NewCustomErr("price stream is disabled"))
+			return
+		}
+
+		heartbeatInterval, maxTickers := defaultHeartbeatInterval, defaultMaxStreamTickers
+		if cfg := svcCtx.C.PriceStream; cfg != nil {
+			if cfg.HeartbeatInterval > 0 {
+				heartbeatInterval = time.Duration(cfg.HeartbeatInterval) * time.Second
+			}
+			if cfg.MaxTickers > 0 {
+				maxTickers = cfg.MaxTickers
+			}
+		}
+
+		tickers := service.ParseStreamTickers(c.Query("tickers"))
+		if len(tickers) == 0 || len(tickers) > maxTickers {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		// 先订阅再读取快照，避免两者之间的更新丢失
+		sub := svcCtx.PriceHub.Subscribe(tickers)
+		defer svcCtx.PriceHub.Unsubscribe(sub)
+
+		snapshot, err := service.GetPriceSnapshot(svcCtx, tickers)
+		if err != nil {
+			serviceError(c, err, "query price snapshot err.")
+			return
+		}
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // 关闭nginx缓冲
+		for _, update := range snapshot {
+			c.SSEvent("price", update)
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case update := <-sub.C:
+				c.SSEvent("price", update)
+			case t := <-heartbeat.C:
+				c.SSEvent("heartbeat", t.UnixMilli())
+			}
+			return true
+		})
+	}
+}
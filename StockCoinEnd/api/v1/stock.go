@@ -3,35 +3,86 @@ package v1
 import (
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
 	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
 	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
 	"github.com/locey/CryptoStock/StockCoinEnd/service/v1"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultStockPageSize = 20
+	maxStockPageSize     = 100
 )
 
 // @BasePath /api/v1
 // @Success 200 {string} string "OK"
 // @Router /stocks [get]
+// 支持keyword搜索代码/名称，category、is_active筛选，sort_by(market_cap/price/gain_percent/volume)+order(asc/desc)排序，
+// currency(USD/CNY/HKD)指定价格、市值的换算币种
 func GetStockList(svcCtx *svc.ServerCtx) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		pageSize := ctx.Query("pageSize")
-		pageNum := ctx.Query("pageNum")
-		pageSizeInt, err := strconv.Atoi(pageSize)
-		if err != nil {
+		filter := types.StockFilterParams{
+			Keyword:  strings.TrimSpace(ctx.Query("keyword")),
+			Category: ctx.Query("category"),
+			SortBy:   ctx.DefaultQuery("sort_by", types.StockSortByMarketCap),
+			Page:     1,
+			PageSize: defaultStockPageSize,
+		}
+
+		if v := ctx.Query("pageNum"); v != "" {
+			page, err := strconv.Atoi(v)
+			if err != nil || page < 1 {
+				xhttp.Error(ctx, errcode.ErrInvalidParams)
+				return
+			}
+			filter.Page = page
+		}
+		if v := ctx.Query("pageSize"); v != "" {
+			pageSize, err := strconv.Atoi(v)
+			if err != nil || pageSize < 1 || pageSize > maxStockPageSize {
+				xhttp.Error(ctx, errcode.ErrInvalidParams)
+				return
+			}
+			filter.PageSize = pageSize
+		}
+		if v := ctx.Query("is_active"); v != "" {
+			isActive, err := strconv.ParseBool(v)
+			if err != nil {
+				xhttp.Error(ctx, errcode.ErrInvalidParams)
+				return
+			}
+			filter.IsActive = &isActive
+		}
+		switch filter.SortBy {
+		case types.StockSortByMarketCap, types.StockSortByPrice, types.StockSortByGainPercent, types.StockSortByVolume:
+		default:
+			xhttp.Error(ctx, errcode.NewCustomErr("unsupported sort_by: "+filter.SortBy))
 			return
 		}
-		pageNumInt, err := strconv.Atoi(pageNum)
-		if err != nil {
+		switch ctx.DefaultQuery("order", "desc") {
+		case "desc":
+			filter.Desc = true
+		case "asc":
+		default:
+			xhttp.Error(ctx, errcode.ErrInvalidParams)
 			return
 		}
-		summary, err := service.GetStockList(svcCtx, pageSizeInt, pageNumInt)
+
+		res, err := service.GetStockList(ctx.Request.Context(), svcCtx, filter, ctx.Query("currency"))
 		if err != nil {
-			log.Fatalf("获取股票数据失败: %v", err)
+			serviceError(ctx, err, "query stock list err.")
+			return
 		}
 
-		xhttp.OkJson(ctx, summary)
+		xhttp.OkJson(ctx, res)
 	}
 
 }
@@ -40,6 +91,7 @@ func Init(svcCtx *svc.ServerCtx, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	service.Init(svcCtx)
 	for {
 		select {
 		case <-ticker.C:
@@ -52,9 +104,10 @@ func Init(svcCtx *svc.ServerCtx, interval time.Duration) {
 }
 func GetOverview(svcCtx *svc.ServerCtx) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		overview, err := service.GetOverview(svcCtx)
+		overview, err := service.GetOverview(ctx.Request.Context(), svcCtx, ctx.Query("currency"))
 		if err != nil {
-			log.Fatalf("获取股票类型失败: %v", err)
+			serviceError(ctx, err, "query overview err.")
+			return
 		}
 
 		xhttp.OkJson(ctx, overview)
@@ -62,14 +115,198 @@ func GetOverview(svcCtx *svc.ServerCtx) gin.HandlerFunc {
 
 }
 
+// GetOverviewHistory 获取平台总览走势，range为24h/7d/30d/90d/1y，默认30d，currency为换算币种，默认USD
+func GetOverviewHistory(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := service.GetOverviewHistory(c.Request.Context(), svcCtx, c.DefaultQuery("range", service.DefaultOverviewRange), c.Query("currency"))
+		if err != nil {
+			serviceError(c, err, "query overview history err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
 // 通过股票代码获取股票价格
 func GetStockPrice(svcCtx *svc.ServerCtx) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		stockCode := ctx.Param("code")
-		price, err := service.GetStockPrice(svcCtx, stockCode)
+		stockCode := strings.ToUpper(ctx.Param("code"))
+		price, err := service.GetStockPrice(ctx.Request.Context(), svcCtx, stockCode, ctx.Query("currency"))
 		if err != nil {
-			log.Fatalf("获取股票价格失败: %v", err)
+			serviceError(ctx, err, "query stock price err.")
+			return
 		}
 		xhttp.OkJson(ctx, price)
 	}
 }
+
+// 默认查询最近180天的K线
+const defaultCandleRange = 180 * 24 * time.Hour
+
+// GetStockCandles 获取股票K线，from/to为unix时间戳（秒或毫秒）
+func GetStockCandles(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+		interval := c.DefaultQuery("interval", dao.CandleInterval1d)
+
+		to := time.Now()
+		if v := c.Query("to"); v != "" {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+			to = timeUtil.UnixToTime(ts)
+		}
+		from := to.Add(-defaultCandleRange)
+		if v := c.Query("from"); v != "" {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+			from = timeUtil.UnixToTime(ts)
+		}
+		if from.After(to) {
+			xhttp.Error(c, errcode.NewCustomErr("from must be before to"))
+			return
+		}
+
+		res, err := service.GetStockCandles(c.Request.Context(), svcCtx, stockCode, interval, from, to)
+		if err != nil {
+			serviceError(c, err, "query stock candles err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetCorporateActions 获取股票的拆股、分红记录，type可选split/dividend
+func GetCorporateActions(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+		actionType := c.Query("type")
+		switch actionType {
+		case "", dao.ActionTypeSplit, dao.ActionTypeDividend:
+		default:
+			xhttp.Error(c, errcode.NewCustomErr("unsupported action type: "+actionType))
+			return
+		}
+
+		res, err := service.GetCorporateActions(c.Request.Context(), svcCtx, stockCode, actionType)
+		if err != nil {
+			serviceError(c, err, "query corporate actions err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetStockTokenInfo 获取股票代币合约的手续费率、最小交易金额、暂停状态及储备
+func GetStockTokenInfo(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+
+		res, err := service.GetStockTokenInfo(c.Request.Context(), svcCtx, stockCode)
+		if err != nil {
+			serviceError(c, err, "query stock token info err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetStockQuote 买卖预估：side=buy时amount为投入的USDT数量，side=sell时为卖出的代币数量，
+// slippage_bps为滑点容忍度（基点），用于计算建议的minTokenAmount/minUsdtAmount
+func GetStockQuote(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+		side := c.DefaultQuery("side", types.QuoteSideBuy)
+		if side != types.QuoteSideBuy && side != types.QuoteSideSell {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		amount, err := decimal.NewFromString(c.Query("amount"))
+		if err != nil || !amount.IsPositive() {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		slippageBps := int64(service.DefaultSlippageBps)
+		if v := c.Query("slippage_bps"); v != "" {
+			slippageBps, err = strconv.ParseInt(v, 10, 64)
+			if err != nil || slippageBps < 0 || slippageBps > service.MaxSlippageBps {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+		}
+
+		res, err := service.GetStockQuote(c.Request.Context(), svcCtx, stockCode, side, amount, slippageBps)
+		if err != nil {
+			serviceError(c, err, "query stock quote err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetOracleUpdateData 获取调用buy/sell所需的预言机更新数据（Pyth、RedStone）及需要支付的费用
+func GetOracleUpdateData(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+
+		res, err := service.GetOracleUpdateData(c.Request.Context(), svcCtx, stockCode)
+		if err != nil {
+			serviceError(c, err, "query oracle update data err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetStockHolders 获取股票代币的持有人数量及持仓最多的持有人，limit默认20，最大100
+func GetStockHolders(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+
+		limit := service.DefaultStockHolderLimit
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > service.MaxStockHolderLimit {
+				xhttp.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+			limit = n
+		}
+
+		res, err := service.GetStockHolders(c.Request.Context(), svcCtx, stockCode, limit)
+		if err != nil {
+			serviceError(c, err, "query stock holders err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
+
+// GetStockHolderCountHistory 获取股票代币每天的持有人数量，range为24h/7d/30d/90d/1y，默认30d
+func GetStockHolderCountHistory(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stockCode := strings.ToUpper(c.Param("code"))
+
+		res, err := service.GetStockHolderCountHistory(c.Request.Context(), svcCtx, stockCode, c.DefaultQuery("range", service.DefaultOverviewRange))
+		if err != nil {
+			serviceError(c, err, "query stock holder count history err.")
+			return
+		}
+
+		xhttp.OkJson(c, res)
+	}
+}
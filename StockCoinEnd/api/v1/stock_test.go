@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+)
+
+func TestGetStockListParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// 参数校验失败时直接返回，不会访问svcCtx
+	router := gin.New()
+	router.GET("/stocks", GetStockList(&svc.ServerCtx{}))
+
+	tests := []struct {
+		name  string
+		query string
+		code  uint32
+		msg   string
+	}{
+		{"page size zero", "pageSize=0", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+		{"page size too large", "pageSize=101", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+		{"page size not a number", "pageSize=ten", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+		{"page num zero", "pageNum=0", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+		{"invalid is_active", "is_active=maybe", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+		{"unsupported sort field", "sort_by=name", errcode.CodeCustom, "unsupported sort_by: name"},
+		{"sort by column name", "sort_by=current_price", errcode.CodeCustom, "unsupported sort_by: current_price"},
+		{"invalid order", "order=random", errcode.ErrInvalidParams.Code(), errcode.ErrInvalidParams.Error()},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stocks?"+tt.query, nil))
+
+		var resp xhttp.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s: invalid response %q: %v", tt.name, w.Body.String(), err)
+			continue
+		}
+		// 业务错误的http状态码为200，错误码在返回内容中
+		if resp.Code != tt.code || resp.Msg != tt.msg {
+			t.Errorf("%s: code %d, msg %q, want code %d, msg %q", tt.name, resp.Code, resp.Msg, tt.code, tt.msg)
+		}
+	}
+}
@@ -1,5 +1,13 @@
 package v1
 
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+)
+
 const (
 	CursorDelimiter = "_"
 )
@@ -11,3 +19,13 @@ var chainIDToChain = chainIDMap{
 	10:       "optimism",
 	11155111: "sepolia",
 }
+
+// serviceError 业务校验错误（*errcode.Err）原样返回，其他错误只记录日志并返回固定提示，避免暴露内部错误
+func serviceError(c *gin.Context, err error, msg string) {
+	if errcode.IsErr(err) {
+		xhttp.Error(c, err)
+		return
+	}
+	log.Printf("%s %s: %v", c.Request.URL.Path, msg, err)
+	xhttp.Error(c, errcode.NewCustomErr(msg))
+}
@@ -0,0 +1,254 @@
+package v1
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinBase/xhttp"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/api/middleware"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/v1"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+// 获取用户的全部自选股列表
+func GetWatchlists(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetWatchlists(c.Request.Context(), svcCtx, userAddr)
+		if err != nil {
+			serviceError(c, err, "query watchlists err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 获取指定自选股列表
+func GetWatchlist(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+
+		res, err := service.GetWatchlist(c.Request.Context(), svcCtx, userAddr, id)
+		if err != nil {
+			serviceError(c, err, "query watchlist err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 创建自选股列表
+func CreateWatchlist(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		var req types.CreateWatchlistReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		res, err := service.CreateWatchlist(c.Request.Context(), svcCtx, userAddr, &req)
+		if err != nil {
+			serviceError(c, err, "create watchlist err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 修改自选股列表名称
+func RenameWatchlist(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var req types.RenameWatchlistReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		if err := service.RenameWatchlist(c.Request.Context(), svcCtx, userAddr, id, req.Name); err != nil {
+			serviceError(c, err, "rename watchlist err.")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// 删除自选股列表
+func DeleteWatchlist(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+
+		if err := service.DeleteWatchlist(c.Request.Context(), svcCtx, userAddr, id); err != nil {
+			serviceError(c, err, "delete watchlist err.")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// 重排用户的自选股列表
+func ReorderWatchlists(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		var req types.ReorderWatchlistsReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		if err := service.ReorderWatchlists(c.Request.Context(), svcCtx, userAddr, req.IDs); err != nil {
+			serviceError(c, err, "reorder watchlists err.")
+			return
+		}
+		xhttp.OkJson(c, nil)
+	}
+}
+
+// 整体设置列表中的股票及顺序
+func SetWatchlistTickers(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var req types.WatchlistTickersReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		res, err := service.SetWatchlistTickers(c.Request.Context(), svcCtx, userAddr, id, req.Tickers)
+		if err != nil {
+			serviceError(c, err, "set watchlist tickers err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 向列表追加股票
+func AddWatchlistTicker(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var req types.AddWatchlistTickerReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			xhttp.Error(c, errcode.ErrInvalidParams)
+			return
+		}
+
+		res, err := service.AddWatchlistTicker(c.Request.Context(), svcCtx, userAddr, id, req.Ticker)
+		if err != nil {
+			serviceError(c, err, "add watchlist ticker err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// 从列表移除股票
+func RemoveWatchlistTicker(svcCtx *svc.ServerCtx) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAddr, ok := authUserAddress(c, svcCtx)
+		if !ok {
+			return
+		}
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+
+		res, err := service.RemoveWatchlistTicker(c.Request.Context(), svcCtx, userAddr, id, c.Param("ticker"))
+		if err != nil {
+			serviceError(c, err, "remove watchlist ticker err.")
+			return
+		}
+		xhttp.OkJson(c, res)
+	}
+}
+
+// authUserAddress 获取登录用户地址（多个session时取第一个），未登录时返回错误响应
+func authUserAddress(c *gin.Context, svcCtx *svc.ServerCtx) (string, bool) {
+	addrs, err := middleware.GetAuthUserAddress(c, svcCtx.KvStore)
+	if err != nil || len(addrs) == 0 {
+		xhttp.Error(c, errcode.ErrTokenVerify)
+		return "", false
+	}
+	return strings.ToLower(addrs[0]), true
+}
+
+// authUserAddresses 获取所有session对应的登录用户地址（去重），未登录时返回错误响应
+func authUserAddresses(c *gin.Context, svcCtx *svc.ServerCtx) ([]string, bool) {
+	addrs, err := middleware.GetAuthUserAddress(c, svcCtx.KvStore)
+	if err != nil || len(addrs) == 0 {
+		xhttp.Error(c, errcode.ErrTokenVerify)
+		return nil, false
+	}
+
+	seen := make(map[string]bool, len(addrs))
+	userAddrs := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = strings.ToLower(addr)
+		if !seen[addr] {
+			seen[addr] = true
+			userAddrs = append(userAddrs, addr)
+		}
+	}
+	return userAddrs, true
+}
+
+// paramID 解析路径中的正整数id，不合法时返回参数错误
+func paramID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		xhttp.Error(c, errcode.ErrInvalidParams)
+		return 0, false
+	}
+	return id, true
+}
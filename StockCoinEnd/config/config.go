@@ -21,6 +21,16 @@ type Config struct {
 	MetadataParse   *MetadataParse    `toml:"metadata_parse" mapstructure:"metadata_parse" json:"metadata_parse"`
 	ChainSupported  []*ChainSupported `toml:"chain_supported" mapstructure:"chain_supported" json:"chain_supported"`
 	AirdropContract *AirdropContract  `toml:"airdrop_contract" mapstructure:"airdrop_contract" json:"airdrop_contract"`
+	MarketData      *MarketData       `toml:"market_data" mapstructure:"market_data" json:"market_data"`
+	TokenFactory    *TokenFactory     `toml:"token_factory" mapstructure:"token_factory" json:"token_factory"`
+	ChainReader     *ChainReader      `toml:"chain_reader" mapstructure:"chain_reader" json:"chain_reader"`
+	PriceAggregator *PriceAggregator  `toml:"price_aggregator" mapstructure:"price_aggregator" json:"price_aggregator"`
+	OracleMonitor   *OracleMonitor    `toml:"oracle_monitor" mapstructure:"oracle_monitor" json:"oracle_monitor"`
+	PriceStream     *PriceStream      `toml:"price_stream" mapstructure:"price_stream" json:"price_stream"`
+	OracleUpdate    *OracleUpdate     `toml:"oracle_update" mapstructure:"oracle_update" json:"oracle_update"`
+	Overview        *Overview         `toml:"overview" mapstructure:"overview" json:"overview"`
+	FX              *FX               `toml:"fx" mapstructure:"fx" json:"fx"`
+	Asset           *Asset            `toml:"asset" mapstructure:"asset" json:"asset"`
 }
 
 type ProjectCfg struct {
@@ -68,6 +78,80 @@ type AirdropContract struct {
 	BatchSize       int    `toml:"batch_size" mapstructure:"batch_size" json:"batch_size"`                // 批量处理大小
 }
 
+type MarketData struct {
+	Provider               string   `toml:"provider" mapstructure:"provider" json:"provider"`                                                 // 行情数据源: polygon / replay / mock
+	BaseURL                string   `toml:"base_url" mapstructure:"base_url" json:"base_url"`                                                 // polygon接口地址
+	ApiKeys                []string `toml:"api_keys" mapstructure:"api_keys" json:"api_keys"`                                                 // polygon apiKey列表，触发限流时轮换
+	ReplayDir              string   `toml:"replay_dir" mapstructure:"replay_dir" json:"replay_dir"`                                           // replay数据源的本地数据目录
+	Timeout                int      `toml:"timeout" mapstructure:"timeout" json:"timeout"`                                                    // 请求超时时间（秒）
+	PollInterval           int      `toml:"poll_interval" mapstructure:"poll_interval" json:"poll_interval"`                                  // 常规交易时段拉取行情的间隔（秒）
+	ExtendedPollInterval   int      `toml:"extended_poll_interval" mapstructure:"extended_poll_interval" json:"extended_poll_interval"`       // 盘前盘后拉取行情的间隔（秒）
+	ClosedPollInterval     int      `toml:"closed_poll_interval" mapstructure:"closed_poll_interval" json:"closed_poll_interval"`             // 休市时拉取行情的间隔（秒）
+	StaleAfter             int      `toml:"stale_after" mapstructure:"stale_after" json:"stale_after"`                                        // 交易时段内行情超过该时长视为过期（秒）
+	ActionsRefreshInterval int      `toml:"actions_refresh_interval" mapstructure:"actions_refresh_interval" json:"actions_refresh_interval"` // 同步拆股、分红等公司行为的间隔（秒）
+}
+
+type TokenFactory struct {
+	Address         string   `toml:"address" mapstructure:"address" json:"address"`                            // TokenFactoryV2代理合约地址
+	Categories      []string `toml:"categories" mapstructure:"categories" json:"categories"`                   // 同步的代币类别(stock/crypto/commodity/forex)，为空时同步全部
+	RefreshInterval int      `toml:"refresh_interval" mapstructure:"refresh_interval" json:"refresh_interval"` // 刷新代币列表的间隔（秒）
+}
+
+type ChainReader struct {
+	Chain            string `toml:"chain" mapstructure:"chain" json:"chain"`                                     // 股票代币所在链的名称，对应StockCoinSync同步的数据表后缀
+	RPCEndpoint      string `toml:"rpc_endpoint" mapstructure:"rpc_endpoint" json:"rpc_endpoint"`                // 以太坊节点RPC地址
+	MulticallAddress string `toml:"multicall_address" mapstructure:"multicall_address" json:"multicall_address"` // Multicall3合约地址，为空时使用默认地址
+	BatchSize        int    `toml:"batch_size" mapstructure:"batch_size" json:"batch_size"`                      // 单次multicall的最大调用数
+	CallFrom         string `toml:"call_from" mapstructure:"call_from" json:"call_from"`                         // 附带预言机更新费用的eth_call的发起地址（需有ETH余额），为空时使用state override
+}
+
+type PriceAggregator struct {
+	Address string `toml:"address" mapstructure:"address" json:"address"` // PriceAggregator合约地址
+}
+
+type OracleMonitor struct {
+	Interval     int    `toml:"interval" mapstructure:"interval" json:"interval"`                // 检查预言机与市场价格偏差的间隔（秒）
+	ThresholdBps int64  `toml:"threshold_bps" mapstructure:"threshold_bps" json:"threshold_bps"` // 偏差告警阈值（基点）
+	AlertWebhook string `toml:"alert_webhook" mapstructure:"alert_webhook" json:"alert_webhook"` // 告警推送地址，为空时只记录日志
+}
+
+type PriceStream struct {
+	HeartbeatInterval int `toml:"heartbeat_interval" mapstructure:"heartbeat_interval" json:"heartbeat_interval"` // 推送连接的心跳间隔（秒）
+	MaxTickers        int `toml:"max_tickers" mapstructure:"max_tickers" json:"max_tickers"`                      // 单个连接最多订阅的股票数量
+}
+
+type OracleUpdate struct {
+	HermesURL             string `toml:"hermes_url" mapstructure:"hermes_url" json:"hermes_url"`                                           // Pyth Hermes接口地址，为空时使用官方地址
+	RedstoneGatewayURL    string `toml:"redstone_gateway_url" mapstructure:"redstone_gateway_url" json:"redstone_gateway_url"`             // RedStone数据网关地址，为空时使用官方地址
+	RedstoneDataServiceID string `toml:"redstone_data_service_id" mapstructure:"redstone_data_service_id" json:"redstone_data_service_id"` // RedStone数据服务ID
+	RedstoneUniqueSigners int    `toml:"redstone_unique_signers" mapstructure:"redstone_unique_signers" json:"redstone_unique_signers"`    // RedStone数据包需要的签名者数量
+	Timeout               int    `toml:"timeout" mapstructure:"timeout" json:"timeout"`                                                    // 请求超时时间（秒）
+}
+
+type Overview struct {
+	SnapshotInterval int `toml:"snapshot_interval" mapstructure:"snapshot_interval" json:"snapshot_interval"` // 平台总览快照的间隔（秒）
+}
+
+type FX struct {
+	Provider     string             `toml:"provider" mapstructure:"provider" json:"provider"`                // 汇率数据源: http / local
+	BaseURL      string             `toml:"base_url" mapstructure:"base_url" json:"base_url"`                // http数据源的接口地址
+	Rates        map[string]float64 `toml:"rates" mapstructure:"rates" json:"rates"`                         // local数据源的固定汇率（1美元可兑换的数量）
+	Currencies   []string           `toml:"currencies" mapstructure:"currencies" json:"currencies"`          // 支持换算的币种，为空时只支持USD
+	CacheSeconds int                `toml:"cache_seconds" mapstructure:"cache_seconds" json:"cache_seconds"` // 汇率缓存时间（秒）
+	Timeout      int                `toml:"timeout" mapstructure:"timeout" json:"timeout"`                   // 请求超时时间（秒）
+}
+
+type Asset struct {
+	StorageDir        string            `toml:"storage_dir" mapstructure:"storage_dir" json:"storage_dir"`                         // 图片本地存储目录
+	BaseURL           string            `toml:"base_url" mapstructure:"base_url" json:"base_url"`                                  // 图片代理地址前缀，默认/api/v1/assets
+	MaxSize           int64             `toml:"max_size" mapstructure:"max_size" json:"max_size"`                                  // 原图大小上限（字节）
+	Sizes             []int             `toml:"sizes" mapstructure:"sizes" json:"sizes"`                                           // 允许的缩略图尺寸（像素）
+	IPFSGateway       string            `toml:"ipfs_gateway" mapstructure:"ipfs_gateway" json:"ipfs_gateway"`                      // ipfs://图片使用的网关
+	AllowPrivateHosts bool              `toml:"allow_private_hosts" mapstructure:"allow_private_hosts" json:"allow_private_hosts"` // 允许拉取内网地址的图片，仅用于本地测试
+	Timeout           int               `toml:"timeout" mapstructure:"timeout" json:"timeout"`                                     // 请求超时时间（秒）
+	StockLogos        map[string]string `toml:"stock_logos" mapstructure:"stock_logos" json:"stock_logos"`                         // 股票logo来源地址，key为股票代码
+}
+
 // UnmarshalConfig unmarshal conifg file
 // @params path: the path of config dir
 func UnmarshalConfig(configFilePath string) (*Config, error) {
@@ -0,0 +1,138 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PriceDecimals 预言机聚合价格的精度
+const PriceDecimals = 18
+
+// 预言机列表没有长度查询接口，按下标批量探测的上限
+const maxOracleCount = 16
+
+// OracleType 与IPriceOracle中的OracleType枚举保持一致
+type OracleType uint8
+
+const (
+	OracleTypePyth OracleType = iota
+	OracleTypeRedStone
+)
+
+// OracleInfo PriceAggregator中注册的预言机
+type OracleInfo struct {
+	OracleType    OracleType
+	OracleAddress common.Address
+	Weight        *big.Int
+}
+
+// PriceAggregator ABI（简化版本，只包含价格查询相关方法）
+const priceAggregatorABI = `[
+    {
+        "inputs": [
+            {"internalType": "string", "name": "symbol", "type": "string"},
+            {"internalType": "bytes[][]", "name": "updateDataArray", "type": "bytes[][]"}
+        ],
+        "name": "getAggregatedPrice",
+        "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "stateMutability": "payable",
+        "type": "function"
+    },
+    {
+        "inputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "name": "oracles",
+        "outputs": [
+            {"internalType": "enum OracleType", "name": "oracleType", "type": "uint8"},
+            {"internalType": "address", "name": "oracleAddress", "type": "address"},
+            {"internalType": "uint256", "name": "weight", "type": "uint256"}
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [],
+        "name": "totalWeight",
+        "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// PriceAggregatorContract 封装了PriceAggregator的价格查询方法
+type PriceAggregatorContract struct {
+	reader      *ChainReader
+	contractABI abi.ABI
+	address     common.Address
+}
+
+func NewPriceAggregatorContract(reader *ChainReader, address string) (*PriceAggregatorContract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid price aggregator address: %s", address)
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(priceAggregatorABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse price aggregator ABI: %v", err)
+	}
+
+	return &PriceAggregatorContract{
+		reader:      reader,
+		contractABI: parsedABI,
+		address:     common.HexToAddress(address),
+	}, nil
+}
+
+func (c *PriceAggregatorContract) Address() common.Address {
+	return c.address
+}
+
+// GetOracles 获取已注册的预言机列表，通过multicall按下标探测直到调用失败
+func (c *PriceAggregatorContract) GetOracles(ctx context.Context) ([]OracleInfo, error) {
+	calls := make([]Call3, maxOracleCount)
+	for i := range calls {
+		data, err := c.contractABI.Pack("oracles", big.NewInt(int64(i)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack oracles: %v", err)
+		}
+		calls[i] = Call3{Target: c.address, AllowFailure: true, CallData: data}
+	}
+
+	results, err := c.reader.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	oracles := make([]OracleInfo, 0)
+	for _, result := range results {
+		// 下标越界时调用revert，说明已到列表末尾
+		if !result.Success {
+			break
+		}
+		out, err := c.contractABI.Unpack("oracles", result.ReturnData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack oracles: %v", err)
+		}
+		oracles = append(oracles, OracleInfo{
+			OracleType:    OracleType(*abi.ConvertType(out[0], new(uint8)).(*uint8)),
+			OracleAddress: *abi.ConvertType(out[1], new(common.Address)).(*common.Address),
+			Weight:        *abi.ConvertType(out[2], new(*big.Int)).(**big.Int),
+		})
+	}
+
+	return oracles, nil
+}
+
+// GetAggregatedPrice 模拟调用getAggregatedPrice获取加权平均价格（18位精度）
+// updateData需与预言机列表一一对应，value为支付给预言机的更新费用
+func (c *PriceAggregatorContract) GetAggregatedPrice(ctx context.Context, symbol string, updateData [][][]byte, value *big.Int) (*big.Int, error) {
+	values, err := c.reader.CallWithValue(ctx, c.contractABI, c.address, value, "getAggregatedPrice", symbol, updateData)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(values[0], new(*big.Int)).(**big.Int), nil
+}
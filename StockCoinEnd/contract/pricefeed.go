@@ -0,0 +1,65 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PythPriceFeed ABI（简化版本，只包含构造更新数据所需的查询方法）
+const pythPriceFeedABI = `[
+    {
+        "inputs": [{"internalType": "string", "name": "", "type": "string"}],
+        "name": "symbolToFeedId",
+        "outputs": [{"internalType": "bytes32", "name": "", "type": "bytes32"}],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [{"internalType": "bytes[]", "name": "updateData", "type": "bytes[]"}],
+        "name": "getUpdateFee",
+        "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// PythPriceFeedContract 封装了PythPriceFeed的查询方法，预言机地址按调用传入
+type PythPriceFeedContract struct {
+	reader      *ChainReader
+	contractABI abi.ABI
+}
+
+func NewPythPriceFeedContract(reader *ChainReader) (*PythPriceFeedContract, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(pythPriceFeedABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pyth price feed ABI: %v", err)
+	}
+
+	return &PythPriceFeedContract{
+		reader:      reader,
+		contractABI: parsedABI,
+	}, nil
+}
+
+// FeedID 查询symbol对应的Pyth Feed ID，未配置时返回零值
+func (c *PythPriceFeedContract) FeedID(ctx context.Context, feed common.Address, symbol string) (common.Hash, error) {
+	values, err := c.reader.Call(ctx, c.contractABI, feed, "symbolToFeedId", symbol)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.Hash(*abi.ConvertType(values[0], new([32]byte)).(*[32]byte)), nil
+}
+
+// UpdateFee 查询提交updateData需要支付的更新费用（wei）
+func (c *PythPriceFeedContract) UpdateFee(ctx context.Context, feed common.Address, updateData [][]byte) (*big.Int, error) {
+	values, err := c.reader.Call(ctx, c.contractABI, feed, "getUpdateFee", updateData)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(values[0], new(*big.Int)).(**big.Int), nil
+}
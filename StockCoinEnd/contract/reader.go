@@ -0,0 +1,306 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// DefaultMulticall3Address Multicall3在主网及各测试网上的统一部署地址
+	DefaultMulticall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	defaultMulticallBatchSize = 100
+
+	// 未配置call_from时，附带value的eth_call使用该地址，并通过state override给它足够的余额
+	defaultCallFrom = "0x00000000000000000000000000000000000C0FFE"
+)
+
+// 只读查询用到的ABI片段，启动时解析一次
+const (
+	erc20ABI = `[
+    {"inputs": [], "name": "totalSupply", "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+    {"inputs": [{"internalType": "address", "name": "account", "type": "address"}], "name": "balanceOf", "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"}
+]`
+
+	priceFeedABI = `[
+    {"inputs": [], "name": "getSupportedSymbols", "outputs": [{"internalType": "string[]", "name": "", "type": "string[]"}], "stateMutability": "view", "type": "function"}
+]`
+
+	multicall3ABI = `[
+    {
+        "inputs": [{"components": [
+            {"internalType": "address", "name": "target", "type": "address"},
+            {"internalType": "bool", "name": "allowFailure", "type": "bool"},
+            {"internalType": "bytes", "name": "callData", "type": "bytes"}
+        ], "internalType": "struct Multicall3.Call3[]", "name": "calls", "type": "tuple[]"}],
+        "name": "aggregate3",
+        "outputs": [{"components": [
+            {"internalType": "bool", "name": "success", "type": "bool"},
+            {"internalType": "bytes", "name": "returnData", "type": "bytes"}
+        ], "internalType": "struct Multicall3.Result[]", "name": "returnData", "type": "tuple[]"}],
+        "stateMutability": "payable",
+        "type": "function"
+    }
+]`
+)
+
+// Call3 Multicall3.aggregate3的单个调用
+type Call3 struct {
+	Target       common.Address `abi:"target"`
+	AllowFailure bool           `abi:"allowFailure"`
+	CallData     []byte         `abi:"callData"`
+}
+
+// Call3Result Multicall3.aggregate3的单个调用结果
+type Call3Result struct {
+	Success    bool   `abi:"success"`
+	ReturnData []byte `abi:"returnData"`
+}
+
+// ChainReader 共享的链上只读客户端：复用同一个RPC连接，ABI只解析一次，并支持Multicall3批量查询
+type ChainReader struct {
+	client    *ethclient.Client
+	multicall common.Address
+	batchSize int
+	callFrom  common.Address // 附带value的eth_call的发起地址
+	fundFrom  bool           // callFrom未配置时为true，调用时通过state override设置余额
+
+	erc20ABI     abi.ABI
+	priceFeedABI abi.ABI
+	multicallABI abi.ABI
+}
+
+// NewChainReader 创建链上只读客户端，callFrom为附带value的eth_call使用的有余额的地址，为空时改用state override
+func NewChainReader(endpoint string, multicallAddress string, batchSize int, callFrom string) (*ChainReader, error) {
+	if multicallAddress == "" {
+		multicallAddress = DefaultMulticall3Address
+	}
+	if !common.IsHexAddress(multicallAddress) {
+		return nil, fmt.Errorf("invalid multicall address: %s", multicallAddress)
+	}
+	fundFrom := callFrom == ""
+	if fundFrom {
+		callFrom = defaultCallFrom
+	}
+	if !common.IsHexAddress(callFrom) {
+		return nil, fmt.Errorf("invalid call from address: %s", callFrom)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultMulticallBatchSize
+	}
+
+	client, err := connectWithTimeout(endpoint, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ChainReader{
+		client:    client,
+		multicall: common.HexToAddress(multicallAddress),
+		batchSize: batchSize,
+		callFrom:  common.HexToAddress(callFrom),
+		fundFrom:  fundFrom,
+	}
+	for _, item := range []struct {
+		dst *abi.ABI
+		raw string
+	}{
+		{&r.erc20ABI, erc20ABI},
+		{&r.priceFeedABI, priceFeedABI},
+		{&r.multicallABI, multicall3ABI},
+	} {
+		if *item.dst, err = abi.JSON(strings.NewReader(item.raw)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to parse ABI: %v", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Client 返回共享的ethclient，供其它合约封装复用连接
+func (r *ChainReader) Client() *ethclient.Client {
+	return r.client
+}
+
+// Call 调用合约的只读方法并解包返回值
+func (r *ChainReader) Call(ctx context.Context, contractABI abi.ABI, to common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	return r.CallWithValue(ctx, contractABI, to, nil, method, args...)
+}
+
+// CallWithValue 以eth_call模拟调用payable方法（如需要支付预言机更新费用的查询），不会上链；
+// value大于0时从callFrom发起，未配置call_from时通过state override为其设置余额
+func (r *ChainReader) CallWithValue(ctx context.Context, contractABI abi.ABI, to common.Address, value *big.Int, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %v", method, err)
+	}
+
+	var result []byte
+	if value != nil && value.Sign() > 0 {
+		result, err = r.callWithValue(ctx, to, data, value)
+	} else {
+		result, err = r.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s on %s: %v", method, to.Hex(), err)
+	}
+
+	values, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %v", method, err)
+	}
+	return values, nil
+}
+
+// callWithValue 从callFrom发起附带value的eth_call：从0地址发起时节点会因余额不足拒绝调用，
+// 未配置call_from时通过state override为callFrom设置余额
+func (r *ChainReader) callWithValue(ctx context.Context, to common.Address, data []byte, value *big.Int) ([]byte, error) {
+	arg := map[string]interface{}{
+		"from":  r.callFrom,
+		"to":    to,
+		"input": hexutil.Bytes(data),
+		"value": (*hexutil.Big)(value),
+	}
+	var result hexutil.Bytes
+	if !r.fundFrom {
+		err := r.client.Client().CallContext(ctx, &result, "eth_call", arg, "latest")
+		return result, err
+	}
+
+	overrides := map[common.Address]map[string]interface{}{
+		r.callFrom: {"balance": (*hexutil.Big)(value)},
+	}
+	err := r.client.Client().CallContext(ctx, &result, "eth_call", arg, "latest", overrides)
+	return result, err
+}
+
+// Multicall 通过Multicall3.aggregate3批量调用，超过batchSize时自动分批
+func (r *ChainReader) Multicall(ctx context.Context, calls []Call3) ([]Call3Result, error) {
+	results := make([]Call3Result, 0, len(calls))
+	for start := 0; start < len(calls); start += r.batchSize {
+		end := start + r.batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		data, err := r.multicallABI.Pack("aggregate3", calls[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack aggregate3: %v", err)
+		}
+		output, err := r.client.CallContract(ctx, ethereum.CallMsg{To: &r.multicall, Data: data}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call aggregate3: %v", err)
+		}
+
+		values, err := r.multicallABI.Unpack("aggregate3", output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack aggregate3: %v", err)
+		}
+		var batch []Call3Result
+		if err := r.multicallABI.Methods["aggregate3"].Outputs.Copy(&batch, values); err != nil {
+			return nil, fmt.Errorf("failed to copy aggregate3 results: %v", err)
+		}
+		results = append(results, batch...)
+	}
+
+	return results, nil
+}
+
+// TotalSupplies 通过一次Multicall批量查询多个代币的totalSupply，调用失败（如地址不是代币合约）的代币记录日志后跳过
+func (r *ChainReader) TotalSupplies(ctx context.Context, tokens []common.Address) (map[common.Address]*big.Int, error) {
+	supplies := make(map[common.Address]*big.Int, len(tokens))
+	if len(tokens) == 0 {
+		return supplies, nil
+	}
+
+	callData, err := r.erc20ABI.Pack("totalSupply")
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack totalSupply: %v", err)
+	}
+	calls := make([]Call3, len(tokens))
+	for i, token := range tokens {
+		calls[i] = Call3{Target: token, AllowFailure: true, CallData: callData}
+	}
+
+	results, err := r.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if !result.Success {
+			log.Printf("totalSupply reverted on %s, skipped", tokens[i].Hex())
+			continue
+		}
+		values, err := r.erc20ABI.Unpack("totalSupply", result.ReturnData)
+		if err != nil {
+			log.Printf("failed to unpack totalSupply of %s, skipped: %v", tokens[i].Hex(), err)
+			continue
+		}
+		supplies[tokens[i]] = *abi.ConvertType(values[0], new(*big.Int)).(**big.Int)
+	}
+
+	return supplies, nil
+}
+
+// BalancesOf 通过一次Multicall批量查询holder在多个代币上的余额，调用失败的代币记录日志后跳过
+func (r *ChainReader) BalancesOf(ctx context.Context, holder common.Address, tokens []common.Address) (map[common.Address]*big.Int, error) {
+	balances := make(map[common.Address]*big.Int, len(tokens))
+	if len(tokens) == 0 {
+		return balances, nil
+	}
+
+	callData, err := r.erc20ABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack balanceOf: %v", err)
+	}
+	calls := make([]Call3, len(tokens))
+	for i, token := range tokens {
+		calls[i] = Call3{Target: token, AllowFailure: true, CallData: callData}
+	}
+
+	results, err := r.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if !result.Success {
+			log.Printf("balanceOf reverted on %s, skipped", tokens[i].Hex())
+			continue
+		}
+		values, err := r.erc20ABI.Unpack("balanceOf", result.ReturnData)
+		if err != nil {
+			log.Printf("failed to unpack balanceOf of %s, skipped: %v", tokens[i].Hex(), err)
+			continue
+		}
+		balances[tokens[i]] = *abi.ConvertType(values[0], new(*big.Int)).(**big.Int)
+	}
+
+	return balances, nil
+}
+
+// SupportedSymbols 查询预言机支持的股票代码
+func (r *ChainReader) SupportedSymbols(ctx context.Context, priceFeed common.Address) ([]string, error) {
+	values, err := r.Call(ctx, r.priceFeedABI, priceFeed, "getSupportedSymbols")
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(values[0], new([]string)).(*[]string), nil
+}
+
+// Close 关闭客户端连接
+func (r *ChainReader) Close() {
+	if r.client != nil {
+		r.client.Close()
+	}
+}
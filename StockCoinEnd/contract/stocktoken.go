@@ -0,0 +1,203 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TradingInfo StockToken的交易参数及合约持有的代币、USDT储备
+type TradingInfo struct {
+	MinTradeAmount *big.Int // 最小交易金额（USDT，6位精度）
+	MaxSlippage    *big.Int // 最大滑点（基点）
+	TradeFeeRate   *big.Int // 交易手续费率（基点）
+	FeeReceiver    common.Address
+	Paused         bool
+	TokenReserve   *big.Int // 合约持有的代币数量（18位精度）
+	USDTReserve    *big.Int // 合约持有的USDT数量（6位精度）
+}
+
+// Reserves 合约持有的代币、USDT储备
+type Reserves struct {
+	TokenReserve *big.Int // 代币数量（18位精度）
+	USDTReserve  *big.Int // USDT数量（6位精度）
+}
+
+// StockToken ABI（简化版本，只包含交易参数、储备及买卖预估的查询方法）
+const stockTokenABI = `[
+    {
+        "inputs": [],
+        "name": "getTradingInfo",
+        "outputs": [
+            {"internalType": "uint256", "name": "_minTradeAmount", "type": "uint256"},
+            {"internalType": "uint256", "name": "_maxSlippage", "type": "uint256"},
+            {"internalType": "uint256", "name": "_tradeFeeRate", "type": "uint256"},
+            {"internalType": "address", "name": "_feeReceiver", "type": "address"},
+            {"internalType": "bool", "name": "_paused", "type": "bool"}
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [],
+        "name": "getContractTokenBalance",
+        "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [],
+        "name": "getContractUSDTBalance",
+        "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {"internalType": "uint256", "name": "usdtAmount", "type": "uint256"},
+            {"internalType": "bytes[][]", "name": "updateData", "type": "bytes[][]"}
+        ],
+        "name": "getBuyEstimate",
+        "outputs": [
+            {"internalType": "uint256", "name": "tokenAmount", "type": "uint256"},
+            {"internalType": "uint256", "name": "feeAmount", "type": "uint256"}
+        ],
+        "stateMutability": "payable",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {"internalType": "uint256", "name": "tokenAmount", "type": "uint256"},
+            {"internalType": "bytes[][]", "name": "updateData", "type": "bytes[][]"}
+        ],
+        "name": "getSellEstimate",
+        "outputs": [
+            {"internalType": "uint256", "name": "usdtAmount", "type": "uint256"},
+            {"internalType": "uint256", "name": "feeAmount", "type": "uint256"}
+        ],
+        "stateMutability": "payable",
+        "type": "function"
+    }
+]`
+
+// StockTokenContract 封装了StockToken的只读查询，代币地址按调用传入，所有代币共用一个实例
+type StockTokenContract struct {
+	reader      *ChainReader
+	contractABI abi.ABI
+}
+
+func NewStockTokenContract(reader *ChainReader) (*StockTokenContract, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(stockTokenABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stock token ABI: %v", err)
+	}
+
+	return &StockTokenContract{
+		reader:      reader,
+		contractABI: parsedABI,
+	}, nil
+}
+
+// GetTradingInfo 通过一次Multicall查询交易参数及代币、USDT储备
+func (c *StockTokenContract) GetTradingInfo(ctx context.Context, token common.Address) (*TradingInfo, error) {
+	methods := []string{"getTradingInfo", "getContractTokenBalance", "getContractUSDTBalance"}
+	calls := make([]Call3, len(methods))
+	for i, method := range methods {
+		callData, err := c.contractABI.Pack(method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %v", method, err)
+		}
+		calls[i] = Call3{Target: token, AllowFailure: false, CallData: callData}
+	}
+
+	results, err := c.reader.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([][]interface{}, len(methods))
+	for i, method := range methods {
+		if outputs[i], err = c.contractABI.Unpack(method, results[i].ReturnData); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s of %s: %v", method, token.Hex(), err)
+		}
+	}
+	if len(outputs[0]) != 5 {
+		return nil, fmt.Errorf("unexpected getTradingInfo output length: %d", len(outputs[0]))
+	}
+
+	return &TradingInfo{
+		MinTradeAmount: *abi.ConvertType(outputs[0][0], new(*big.Int)).(**big.Int),
+		MaxSlippage:    *abi.ConvertType(outputs[0][1], new(*big.Int)).(**big.Int),
+		TradeFeeRate:   *abi.ConvertType(outputs[0][2], new(*big.Int)).(**big.Int),
+		FeeReceiver:    *abi.ConvertType(outputs[0][3], new(common.Address)).(*common.Address),
+		Paused:         *abi.ConvertType(outputs[0][4], new(bool)).(*bool),
+		TokenReserve:   *abi.ConvertType(outputs[1][0], new(*big.Int)).(**big.Int),
+		USDTReserve:    *abi.ConvertType(outputs[2][0], new(*big.Int)).(**big.Int),
+	}, nil
+}
+
+// GetBuyEstimate 模拟调用getBuyEstimate，返回扣除手续费后可得的代币数量及手续费（代币，18位精度）
+// updateData需与PriceAggregator的预言机列表一一对应，value为支付给预言机的更新费用
+func (c *StockTokenContract) GetBuyEstimate(ctx context.Context, token common.Address, usdtAmount *big.Int, updateData [][][]byte, value *big.Int) (tokenAmount, feeAmount *big.Int, err error) {
+	return c.estimate(ctx, token, "getBuyEstimate", usdtAmount, updateData, value)
+}
+
+// GetSellEstimate 模拟调用getSellEstimate，返回扣除手续费后可得的USDT数量及手续费（USDT，6位精度）
+func (c *StockTokenContract) GetSellEstimate(ctx context.Context, token common.Address, tokenAmount *big.Int, updateData [][][]byte, value *big.Int) (usdtAmount, feeAmount *big.Int, err error) {
+	return c.estimate(ctx, token, "getSellEstimate", tokenAmount, updateData, value)
+}
+
+func (c *StockTokenContract) estimate(ctx context.Context, token common.Address, method string, amount *big.Int, updateData [][][]byte, value *big.Int) (*big.Int, *big.Int, error) {
+	values, err := c.reader.CallWithValue(ctx, c.contractABI, token, value, method, amount, updateData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) != 2 {
+		return nil, nil, fmt.Errorf("unexpected %s output length: %d", method, len(values))
+	}
+
+	return *abi.ConvertType(values[0], new(*big.Int)).(**big.Int), *abi.ConvertType(values[1], new(*big.Int)).(**big.Int), nil
+}
+
+// GetReserves 通过Multicall批量查询多个代币合约持有的代币、USDT储备
+func (c *StockTokenContract) GetReserves(ctx context.Context, tokens []common.Address) (map[common.Address]*Reserves, error) {
+	reserves := make(map[common.Address]*Reserves, len(tokens))
+	if len(tokens) == 0 {
+		return reserves, nil
+	}
+
+	methods := []string{"getContractTokenBalance", "getContractUSDTBalance"}
+	calls := make([]Call3, 0, len(tokens)*len(methods))
+	for _, token := range tokens {
+		for _, method := range methods {
+			callData, err := c.contractABI.Pack(method)
+			if err != nil {
+				return nil, fmt.Errorf("failed to pack %s: %v", method, err)
+			}
+			calls = append(calls, Call3{Target: token, AllowFailure: false, CallData: callData})
+		}
+	}
+
+	results, err := c.reader.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, token := range tokens {
+		amounts := make([]*big.Int, len(methods))
+		for j, method := range methods {
+			values, err := c.contractABI.Unpack(method, results[i*len(methods)+j].ReturnData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unpack %s of %s: %v", method, token.Hex(), err)
+			}
+			amounts[j] = *abi.ConvertType(values[0], new(*big.Int)).(**big.Int)
+		}
+		reserves[token] = &Reserves{TokenReserve: amounts[0], USDTReserve: amounts[1]}
+	}
+
+	return reserves, nil
+}
@@ -0,0 +1,151 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenCategory 与TokenFactoryV2中的TokenCategory枚举保持一致
+type TokenCategory uint8
+
+const (
+	TokenCategoryStock TokenCategory = iota
+	TokenCategoryCrypto
+	TokenCategoryCommodity
+	TokenCategoryForex
+)
+
+var tokenCategoryNames = map[TokenCategory]string{
+	TokenCategoryStock:     "stock",
+	TokenCategoryCrypto:    "crypto",
+	TokenCategoryCommodity: "commodity",
+	TokenCategoryForex:     "forex",
+}
+
+func (c TokenCategory) String() string {
+	if name, ok := tokenCategoryNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(c))
+}
+
+// ParseTokenCategory 将配置中的类别名称转换为TokenCategory
+func ParseTokenCategory(name string) (TokenCategory, error) {
+	for category, n := range tokenCategoryNames {
+		if strings.EqualFold(n, name) {
+			return category, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown token category: %s", name)
+}
+
+// TokenInfo TokenFactoryV2.getTokenInfo返回的代币元数据
+type TokenInfo struct {
+	Symbol       string
+	TokenAddress common.Address
+	Category     TokenCategory
+	CreatedAt    *big.Int
+	IsActive     bool
+	TotalVolume  *big.Int
+	Creator      common.Address
+}
+
+// TokenFactoryV2 ABI（简化版本，只包含代币注册表的查询方法）
+const tokenFactoryABI = `[
+    {
+        "inputs": [],
+        "name": "getActiveTokens",
+        "outputs": [{"internalType": "string[]", "name": "", "type": "string[]"}],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [{"internalType": "enum TokenFactoryV2.TokenCategory", "name": "category", "type": "uint8"}],
+        "name": "getTokensByCategory",
+        "outputs": [{"internalType": "string[]", "name": "", "type": "string[]"}],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [{"internalType": "string", "name": "symbol", "type": "string"}],
+        "name": "getTokenInfo",
+        "outputs": [
+            {"internalType": "address", "name": "tokenAddress", "type": "address"},
+            {"internalType": "enum TokenFactoryV2.TokenCategory", "name": "category", "type": "uint8"},
+            {"internalType": "uint256", "name": "createdAt", "type": "uint256"},
+            {"internalType": "bool", "name": "isActive", "type": "bool"},
+            {"internalType": "uint256", "name": "totalVolume", "type": "uint256"},
+            {"internalType": "address", "name": "creator", "type": "address"}
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// TokenFactoryContract 封装了TokenFactoryV2代币注册表的查询方法
+type TokenFactoryContract struct {
+	reader      *ChainReader
+	contractABI abi.ABI
+	address     common.Address
+}
+
+func NewTokenFactoryContract(reader *ChainReader, address string) (*TokenFactoryContract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid token factory address: %s", address)
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(tokenFactoryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token factory ABI: %v", err)
+	}
+
+	return &TokenFactoryContract{
+		reader:      reader,
+		contractABI: parsedABI,
+		address:     common.HexToAddress(address),
+	}, nil
+}
+
+// GetActiveTokens 获取所有活跃代币的symbol
+func (c *TokenFactoryContract) GetActiveTokens(ctx context.Context) ([]string, error) {
+	values, err := c.reader.Call(ctx, c.contractABI, c.address, "getActiveTokens")
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(values[0], new([]string)).(*[]string), nil
+}
+
+// GetTokensByCategory 获取指定类别下的代币symbol（包含已停用的代币）
+func (c *TokenFactoryContract) GetTokensByCategory(ctx context.Context, category TokenCategory) ([]string, error) {
+	values, err := c.reader.Call(ctx, c.contractABI, c.address, "getTokensByCategory", uint8(category))
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(values[0], new([]string)).(*[]string), nil
+}
+
+// GetTokenInfo 获取代币元数据
+func (c *TokenFactoryContract) GetTokenInfo(ctx context.Context, symbol string) (*TokenInfo, error) {
+	out, err := c.reader.Call(ctx, c.contractABI, c.address, "getTokenInfo", symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != 6 {
+		return nil, fmt.Errorf("unexpected getTokenInfo output length: %d", len(out))
+	}
+
+	return &TokenInfo{
+		Symbol:       symbol,
+		TokenAddress: *abi.ConvertType(out[0], new(common.Address)).(*common.Address),
+		Category:     TokenCategory(*abi.ConvertType(out[1], new(uint8)).(*uint8)),
+		CreatedAt:    *abi.ConvertType(out[2], new(*big.Int)).(**big.Int),
+		IsActive:     *abi.ConvertType(out[3], new(bool)).(*bool),
+		TotalVolume:  *abi.ConvertType(out[4], new(*big.Int)).(**big.Int),
+		Creator:      *abi.ConvertType(out[5], new(common.Address)).(*common.Address),
+	}, nil
+}
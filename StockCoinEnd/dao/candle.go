@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CandleInterval1d = "1d" // 日线，数据源原始粒度
+	CandleInterval1w = "1w" // 周线，由日线汇总
+	CandleInterval1M = "1M" // 月线，由日线汇总
+)
+
+// StockCandle 股票K线(OHLCV)，以 ticker + interval + timestamp 唯一
+type StockCandle struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	Ticker    string    `gorm:"size:20;not null;uniqueIndex:idx_ticker_interval_ts,priority:1" json:"ticker"`
+	Interval  string    `gorm:"column:time_interval;size:8;not null;uniqueIndex:idx_ticker_interval_ts,priority:2" json:"interval"`
+	Timestamp int64     `gorm:"not null;uniqueIndex:idx_ticker_interval_ts,priority:3" json:"timestamp"` // 开盘时间（毫秒）
+	Open      float64   `gorm:"type:double" json:"open"`
+	High      float64   `gorm:"type:double" json:"high"`
+	Low       float64   `gorm:"type:double" json:"low"`
+	Close     float64   `gorm:"type:double" json:"close"`
+	Volume    float64   `gorm:"type:double" json:"volume"`
+	ItemCount int64     `json:"item_count"` // 成交笔数
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func StockCandleTableName() string {
+	return "stock_candle"
+}
+
+// BatchUpsertCandles 批量写入K线，已存在的K线覆盖OHLCV（当日K线在收盘前会持续变化）
+func (d *Dao) BatchUpsertCandles(ctx context.Context, candles []StockCandle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	err := d.DB.WithContext(ctx).Table(StockCandleTableName()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ticker"}, {Name: "time_interval"}, {Name: "timestamp"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "high", "low", "close", "volume", "item_count", "updated_at"}),
+	}).CreateInBatches(candles, 100).Error
+	if err != nil {
+		return errors.Wrap(err, "failed on upsert stock candles")
+	}
+
+	return nil
+}
+
+// QueryCandles 查询[from, to]（毫秒）区间内的K线，按时间升序
+func (d *Dao) QueryCandles(ctx context.Context, ticker, interval string, from, to int64) ([]StockCandle, error) {
+	var candles []StockCandle
+	if err := d.DB.WithContext(ctx).Table(StockCandleTableName()).
+		Where("ticker = ? AND time_interval = ? AND timestamp >= ? AND timestamp <= ?", ticker, interval, from, to).
+		Order("timestamp asc").
+		Find(&candles).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query stock candles")
+	}
+
+	return candles, nil
+}
+
+// GetEarliestCandle 获取指定ticker最早的一根K线，不存在时返回nil
+func (d *Dao) GetEarliestCandle(ctx context.Context, ticker, interval string) (*StockCandle, error) {
+	var candles []StockCandle
+	if err := d.DB.WithContext(ctx).Table(StockCandleTableName()).
+		Where("ticker = ? AND time_interval = ?", ticker, interval).
+		Order("timestamp asc").
+		Limit(1).
+		Find(&candles).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on get earliest stock candle")
+	}
+	if len(candles) == 0 {
+		return nil, nil
+	}
+
+	return &candles[0], nil
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ActionTypeSplit    = "split"    // 拆股/合股
+	ActionTypeDividend = "dividend" // 现金分红
+)
+
+// CorporateAction 公司行为（拆股、分红），以 ticker + action_type + ex_date 唯一
+type CorporateAction struct {
+	ID         int64           `gorm:"primaryKey" json:"id"`
+	Ticker     string          `gorm:"size:20;not null;uniqueIndex:idx_ticker_type_ex_date,priority:1" json:"ticker"`
+	ActionType string          `gorm:"size:16;not null;uniqueIndex:idx_ticker_type_ex_date,priority:2" json:"action_type"`
+	ExDate     int64           `gorm:"not null;uniqueIndex:idx_ticker_type_ex_date,priority:3" json:"ex_date"` // 除权除息日（纽约时间零点，毫秒）
+	PayDate    int64           `json:"pay_date"`                                                               // 派息日（毫秒），仅分红
+	SplitFrom  float64         `gorm:"type:double" json:"split_from"`
+	SplitTo    float64         `gorm:"type:double" json:"split_to"`
+	CashAmount decimal.Decimal `gorm:"type:decimal(36,18)" json:"cash_amount"` // 每股分红金额
+	Currency   string          `gorm:"size:8" json:"currency"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+func CorporateActionTableName() string {
+	return "corporate_action"
+}
+
+// BatchUpsertCorporateActions 批量写入公司行为，已存在的记录以数据源最新数据为准（公告后金额、日期可能修正）
+func (d *Dao) BatchUpsertCorporateActions(ctx context.Context, actions []CorporateAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	err := d.DB.WithContext(ctx).Table(CorporateActionTableName()).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ticker"}, {Name: "action_type"}, {Name: "ex_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pay_date", "split_from", "split_to", "cash_amount", "currency", "updated_at"}),
+	}).CreateInBatches(actions, 100).Error
+	if err != nil {
+		return errors.Wrap(err, "failed on upsert corporate actions")
+	}
+
+	return nil
+}
+
+// QueryCorporateActions 查询指定ticker的公司行为，actionType为空时不过滤类型，按除权除息日升序
+func (d *Dao) QueryCorporateActions(ctx context.Context, ticker, actionType string) ([]CorporateAction, error) {
+	db := d.DB.WithContext(ctx).Table(CorporateActionTableName()).Where("ticker = ?", ticker)
+	if actionType != "" {
+		db = db.Where("action_type = ?", actionType)
+	}
+
+	var actions []CorporateAction
+	if err := db.Order("ex_date asc").Find(&actions).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query corporate actions")
+	}
+
+	return actions, nil
+}
+
+// QuerySplitsByTickers 批量查询多个ticker的拆股记录，按ticker分组
+func (d *Dao) QuerySplitsByTickers(ctx context.Context, tickers []string) (map[string][]CorporateAction, error) {
+	splits := make(map[string][]CorporateAction, len(tickers))
+	if len(tickers) == 0 {
+		return splits, nil
+	}
+
+	var actions []CorporateAction
+	if err := d.DB.WithContext(ctx).Table(CorporateActionTableName()).
+		Where("ticker IN ? AND action_type = ?", tickers, ActionTypeSplit).
+		Order("ex_date asc").
+		Find(&actions).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query splits")
+	}
+	for _, action := range actions {
+		splits[action.Ticker] = append(splits[action.Ticker], action)
+	}
+
+	return splits, nil
+}
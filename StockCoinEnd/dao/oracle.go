@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// OracleDeviation 预言机聚合价格与市场价格的偏差时间序列
+type OracleDeviation struct {
+	ID           int64           `gorm:"primaryKey" json:"id"`
+	Ticker       string          `gorm:"size:20;not null;index:idx_ticker_ts,priority:1" json:"ticker"`
+	OraclePrice  decimal.Decimal `gorm:"type:decimal(36,18)" json:"oracle_price"`                  // PriceAggregator聚合价格
+	MarketPrice  decimal.Decimal `gorm:"type:decimal(36,18)" json:"market_price"`                  // StockInfo.CurrentPrice
+	DeviationBps int64           `json:"deviation_bps"`                                            // (oracle-market)/market，单位基点，可为负
+	IsAlert      bool            `json:"is_alert"`                                                 // 是否超过告警阈值
+	IsStale      bool            `json:"is_stale"`                                                 // 采样时休市或市场价格已过期，不参与告警
+	Timestamp    int64           `gorm:"not null;index:idx_ticker_ts,priority:2" json:"timestamp"` // 采样时间（毫秒）
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func OracleDeviationTableName() string {
+	return "oracle_deviation"
+}
+
+// BatchCreateOracleDeviations 批量写入偏差采样
+func (d *Dao) BatchCreateOracleDeviations(ctx context.Context, deviations []OracleDeviation) error {
+	if len(deviations) == 0 {
+		return nil
+	}
+
+	if err := d.DB.WithContext(ctx).Table(OracleDeviationTableName()).CreateInBatches(deviations, 100).Error; err != nil {
+		return errors.Wrap(err, "failed on create oracle deviations")
+	}
+
+	return nil
+}
+
+// QueryOracleDeviations 查询[from, to]（毫秒）区间内的偏差采样，按时间升序
+func (d *Dao) QueryOracleDeviations(ctx context.Context, ticker string, from, to int64) ([]OracleDeviation, error) {
+	var deviations []OracleDeviation
+	if err := d.DB.WithContext(ctx).Table(OracleDeviationTableName()).
+		Where("ticker = ? AND timestamp >= ? AND timestamp <= ?", ticker, from, to).
+		Order("timestamp asc").
+		Find(&deviations).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query oracle deviations")
+	}
+
+	return deviations, nil
+}
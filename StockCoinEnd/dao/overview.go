@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm/clause"
+)
+
+// OverviewSnapshot 平台总览的定时快照，用于绘制总市值、TVL等走势
+type OverviewSnapshot struct {
+	ID              int64           `gorm:"primaryKey" json:"id"`
+	SnapshotAt      int64           `gorm:"not null;uniqueIndex" json:"snapshot_at"`     // 快照时间（毫秒，按快照间隔对齐）
+	TotalMarketCap  decimal.Decimal `gorm:"type:decimal(36,18)" json:"total_market_cap"` // 股票总市值
+	PoolTokenValue  decimal.Decimal `gorm:"type:decimal(36,18)" json:"pool_token_value"` // 币股池持有的代币按当前价格折算的价值（USDT）
+	PoolUsdtReserve decimal.Decimal `gorm:"type:decimal(36,18)" json:"pool_usdt_reserve"`
+	Volume24h       decimal.Decimal `gorm:"type:decimal(36,18)" json:"volume_24h"` // 近24小时链上买卖成交额（USDT）
+	StockCount      int64           `json:"stock_count"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func OverviewSnapshotTableName() string {
+	return "overview_snapshot"
+}
+
+// CreateOverviewSnapshot 写入快照，同一时间点已存在时忽略（多副本同时运行快照任务）
+func (d *Dao) CreateOverviewSnapshot(ctx context.Context, snapshot *OverviewSnapshot) error {
+	if err := d.DB.WithContext(ctx).Table(OverviewSnapshotTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(snapshot).Error; err != nil {
+		return errors.Wrap(err, "failed on create overview snapshot")
+	}
+
+	return nil
+}
+
+// QueryOverviewSnapshots 查询[from, to]（毫秒）区间内的快照，按时间升序
+func (d *Dao) QueryOverviewSnapshots(ctx context.Context, from, to int64) ([]OverviewSnapshot, error) {
+	var snapshots []OverviewSnapshot
+	if err := d.DB.WithContext(ctx).Table(OverviewSnapshotTableName()).
+		Where("snapshot_at >= ? AND snapshot_at <= ?", from, to).
+		Order("snapshot_at asc").
+		Find(&snapshots).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query overview snapshots")
+	}
+
+	return snapshots, nil
+}
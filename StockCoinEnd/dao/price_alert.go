@@ -0,0 +1,228 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// 价格提醒条件
+const (
+	AlertConditionPriceAbove = "price_above" // 价格向上穿越阈值
+	AlertConditionPriceBelow = "price_below" // 价格向下穿越阈值
+	AlertConditionDayChange  = "day_change"  // 当日涨跌幅绝对值超过阈值（百分比）
+)
+
+// 价格提醒模式
+const (
+	AlertModeOnce      = "once"      // 触发一次后自动停用
+	AlertModeRecurring = "recurring" // 冷却期结束后可再次触发
+)
+
+// 提醒投递状态
+const (
+	AlertDeliveryPending   = "pending"
+	AlertDeliveryDelivered = "delivered"
+	AlertDeliveryFailed    = "failed"
+)
+
+// PriceAlert 用户设置的价格提醒
+type PriceAlert struct {
+	ID              int64           `gorm:"primaryKey" json:"id"`
+	UserAddress     string          `gorm:"size:42;not null;index" json:"user_address"`
+	Ticker          string          `gorm:"size:20;not null;index:idx_ticker_active,priority:1" json:"ticker"`
+	Condition       string          `gorm:"column:alert_condition;size:20;not null" json:"condition"` // 提醒条件
+	Threshold       decimal.Decimal `gorm:"type:decimal(36,18)" json:"threshold"`                     // 价格阈值，day_change时为百分比
+	Mode            string          `gorm:"size:20;not null" json:"mode"`                             // once/recurring
+	CooldownSeconds int64           `json:"cooldown_seconds"`                                         // 两次触发的最小间隔（秒）
+	IsActive        bool            `gorm:"index:idx_ticker_active,priority:2" json:"is_active"`
+	LastTriggeredAt int64           `json:"last_triggered_at"` // 最近一次触发时间（毫秒）
+	TriggerCount    int64           `json:"trigger_count"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// PriceAlertDelivery 提醒触发后生成的投递记录，由各通知渠道消费
+type PriceAlertDelivery struct {
+	ID            int64           `gorm:"primaryKey" json:"id"`
+	AlertID       int64           `gorm:"not null;index" json:"alert_id"`
+	UserAddress   string          `gorm:"size:42;not null;index" json:"user_address"`
+	Ticker        string          `gorm:"size:20;not null" json:"ticker"`
+	Condition     string          `gorm:"column:alert_condition;size:20;not null" json:"condition"`
+	Threshold     decimal.Decimal `gorm:"type:decimal(36,18)" json:"threshold"`
+	Price         decimal.Decimal `gorm:"type:decimal(36,18)" json:"price"` // 触发时的价格
+	ChangePercent float64         `json:"change_percent"`                   // 触发时的当日涨跌幅（百分比）
+	TriggeredAt   int64           `gorm:"not null" json:"triggered_at"`     // 触发时间（毫秒）
+	Status        string          `gorm:"size:20;not null;index" json:"status"`
+	DeliveredAt   int64           `json:"delivered_at"` // 投递完成时间（毫秒）
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func PriceAlertTableName() string {
+	return "price_alert"
+}
+
+func PriceAlertDeliveryTableName() string {
+	return "price_alert_delivery"
+}
+
+// CreatePriceAlert 创建价格提醒
+func (d *Dao) CreatePriceAlert(ctx context.Context, alert *PriceAlert) error {
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).Create(alert).Error; err != nil {
+		return errors.Wrap(err, "failed on create price alert")
+	}
+
+	return nil
+}
+
+// QueryPriceAlerts 获取用户的全部价格提醒，最新创建的在前
+func (d *Dao) QueryPriceAlerts(ctx context.Context, userAddress string) ([]PriceAlert, error) {
+	var alerts []PriceAlert
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).
+		Where("user_address = ?", userAddress).
+		Order("id desc").
+		Find(&alerts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query price alerts")
+	}
+
+	return alerts, nil
+}
+
+// GetPriceAlert 获取用户的指定价格提醒，不存在或不属于该用户时返回nil
+func (d *Dao) GetPriceAlert(ctx context.Context, userAddress string, id int64) (*PriceAlert, error) {
+	var alerts []PriceAlert
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).
+		Where("id = ? AND user_address = ?", id, userAddress).
+		Limit(1).
+		Find(&alerts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on get price alert")
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	return &alerts[0], nil
+}
+
+// SetPriceAlertActive 启用或停用价格提醒
+func (d *Dao) SetPriceAlertActive(ctx context.Context, userAddress string, id int64, isActive bool) error {
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).
+		Where("id = ? AND user_address = ?", id, userAddress).
+		Updates(map[string]interface{}{"is_active": isActive, "updated_at": time.Now()}).Error; err != nil {
+		return errors.Wrap(err, "failed on update price alert")
+	}
+
+	return nil
+}
+
+// DeletePriceAlert 删除价格提醒，已生成的投递记录保留
+func (d *Dao) DeletePriceAlert(ctx context.Context, userAddress string, id int64) error {
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).
+		Where("id = ? AND user_address = ?", id, userAddress).
+		Delete(&PriceAlert{}).Error; err != nil {
+		return errors.Wrap(err, "failed on delete price alert")
+	}
+
+	return nil
+}
+
+// QueryActivePriceAlerts 获取指定股票上所有启用中的价格提醒
+func (d *Dao) QueryActivePriceAlerts(ctx context.Context, tickers []string) ([]PriceAlert, error) {
+	var alerts []PriceAlert
+	if len(tickers) == 0 {
+		return alerts, nil
+	}
+
+	if err := d.DB.WithContext(ctx).Table(PriceAlertTableName()).
+		Where("ticker IN ? AND is_active = ?", tickers, true).
+		Find(&alerts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query active price alerts")
+	}
+
+	return alerts, nil
+}
+
+// TriggerPriceAlert 记录提醒触发并生成投递记录。
+// 以last_triggered_at做乐观锁，多个实例同时评估同一提醒时只有一个会成功，返回false表示已被其它实例触发
+func (d *Dao) TriggerPriceAlert(ctx context.Context, alert *PriceAlert, delivery *PriceAlertDelivery) (bool, error) {
+	triggered := false
+	err := d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(PriceAlertTableName()).
+			Where("id = ? AND is_active = ? AND last_triggered_at = ?", alert.ID, true, alert.LastTriggeredAt).
+			Updates(map[string]interface{}{
+				"last_triggered_at": delivery.TriggeredAt,
+				"trigger_count":     gorm.Expr("trigger_count + 1"),
+				"is_active":         alert.Mode != AlertModeOnce,
+				"updated_at":        time.Now(),
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "failed on update price alert")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Table(PriceAlertDeliveryTableName()).Create(delivery).Error; err != nil {
+			return errors.Wrap(err, "failed on create price alert delivery")
+		}
+		triggered = true
+		return nil
+	})
+
+	return triggered, err
+}
+
+// QueryAlertDeliveries 分页获取用户的提醒投递记录，最新的在前
+func (d *Dao) QueryAlertDeliveries(ctx context.Context, userAddress string, page, pageSize int) ([]PriceAlertDelivery, int64, error) {
+	var deliveries []PriceAlertDelivery
+	var count int64
+
+	db := d.DB.WithContext(ctx).Table(PriceAlertDeliveryTableName()).Where("user_address = ?", userAddress)
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "failed on count price alert deliveries")
+	}
+	if err := db.Order("id desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "failed on query price alert deliveries")
+	}
+
+	return deliveries, count, nil
+}
+
+// QueryPendingAlertDeliveries 按触发顺序获取待投递的记录，供通知渠道消费
+func (d *Dao) QueryPendingAlertDeliveries(ctx context.Context, limit int) ([]PriceAlertDelivery, error) {
+	var deliveries []PriceAlertDelivery
+	if err := d.DB.WithContext(ctx).Table(PriceAlertDeliveryTableName()).
+		Where("status = ?", AlertDeliveryPending).
+		Order("id asc").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query pending price alert deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// UpdateAlertDeliveryStatus 通知渠道投递后回写状态
+func (d *Dao) UpdateAlertDeliveryStatus(ctx context.Context, ids []int64, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	updates := map[string]interface{}{"status": status}
+	if status == AlertDeliveryDelivered {
+		updates["delivered_at"] = time.Now().UnixMilli()
+	}
+	if err := d.DB.WithContext(ctx).Table(PriceAlertDeliveryTableName()).
+		Where("id IN ?", ids).
+		Updates(updates).Error; err != nil {
+		return errors.Wrap(err, "failed on update price alert delivery status")
+	}
+
+	return nil
+}
@@ -1,22 +1,32 @@
 package dao
 
 import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
 )
 
 type StockInfo struct {
 	gorm.Model
-	Ticker         string  `gorm:"uniqueIndex;size:20" json:"ticker"`
-	Name           string  `gorm:"size:100" json:"name"`
-	Description    string  `gorm:"type:text" json:"description"`
-	Logo           string  `gorm:"size:255" json:"logo"`
-	TokenAddress   string  `gorm:"size:100" json:"token_address"`
-	AvgGain        float64 `gorm:"type:float" json:"avg_gain"`         //7日平均涨幅
-	AvgGainPercent float64 `gorm:"type:float" json:"avg_gain_percent"` //7日平均涨幅百分比
-	AvgVolume      float64 `gorm:"type:float" json:"avg_volume"`       //7日平均成交量
-	CurrentPrice   float64 `gorm:"type:float" json:"current_price"`    //当前价格
-	MarketCap      float64 `gorm:"type:float" json:"market_cap"`       //市值
+	Ticker         string          `gorm:"uniqueIndex;size:20" json:"ticker"`
+	Name           string          `gorm:"size:100" json:"name"`
+	Description    string          `gorm:"type:text" json:"description"`
+	Logo           string          `gorm:"size:255" json:"logo"`
+	TokenAddress   string          `gorm:"size:100" json:"token_address"`
+	AvgGain        decimal.Decimal `gorm:"type:decimal(36,18)" json:"avg_gain"`      //7日平均涨幅
+	AvgGainPercent float64         `gorm:"type:float" json:"avg_gain_percent"`       //7日平均涨幅百分比
+	AvgVolume      float64         `gorm:"type:float" json:"avg_volume"`             //7日平均成交量
+	CurrentPrice   decimal.Decimal `gorm:"type:decimal(36,18)" json:"current_price"` //当前价格
+	MarketCap      decimal.Decimal `gorm:"type:decimal(36,18)" json:"market_cap"`    //市值
+	Category       string          `gorm:"size:20;index" json:"category"`            //代币类别(stock/crypto/commodity/forex)
+	IsActive       bool            `gorm:"index" json:"is_active"`                   //是否在TokenFactory中处于活跃状态
+	PriceAsOf      int64           `json:"price_as_of"`                              //当前价格对应的时间（毫秒）
 }
 
 // 构造stock_token表
@@ -60,13 +70,14 @@ func (d *Dao) Update(stock *StockInfo) error {
 	return d.DB.Save(stock).Error
 }
 
-// UpdateStockSummary 通过ticker更新AvgGain、AvgGainPercent、AvgVolume、CurrentPrice
-func (d *Dao) UpdateStockSummary(ticker string, avgGain float64, avgGainPercent float64, avgVolume float64, currentPrice float64) error {
+// UpdateStockSummary 通过ticker更新AvgGain、AvgGainPercent、AvgVolume、CurrentPrice及价格时间
+func (d *Dao) UpdateStockSummary(ticker string, avgGain decimal.Decimal, avgGainPercent float64, avgVolume float64, currentPrice decimal.Decimal, priceAsOf int64) error {
 	return d.DB.Model(&StockInfo{}).Where("ticker = ?", ticker).Updates(map[string]interface{}{
 		"avg_gain":         avgGain,
 		"avg_gain_percent": avgGainPercent,
 		"avg_volume":       avgVolume,
 		"current_price":    currentPrice,
+		"price_as_of":      priceAsOf,
 	}).Error
 }
 
@@ -79,6 +90,41 @@ func (d *Dao) UpdateMarketData(ticker string, sharesOutstanding float64, avgMark
 
 }
 
+// ListActiveTickers 获取所有活跃股票代码
+func (d *Dao) ListActiveTickers() ([]string, error) {
+	var tickers []string
+	err := d.DB.Model(&StockInfo{}).Where("is_active = ?", true).Pluck("ticker", &tickers).Error
+	return tickers, err
+}
+
+// UpdateTokenRegistry 同步TokenFactory中的代币地址、类别及活跃状态
+func (d *Dao) UpdateTokenRegistry(ticker string, tokenAddress string, category string, isActive bool) error {
+	return d.DB.Model(&StockInfo{}).Where("ticker = ?", ticker).Updates(map[string]interface{}{
+		"token_address": tokenAddress,
+		"category":      category,
+		"is_active":     isActive,
+	}).Error
+}
+
+// DeactivateExcept 将不在tickers中的股票标记为非活跃
+func (d *Dao) DeactivateExcept(tickers []string) error {
+	db := d.DB.Model(&StockInfo{}).Where("is_active = ?", true)
+	if len(tickers) > 0 {
+		db = db.Where("ticker NOT IN ?", tickers)
+	}
+	return db.Update("is_active", false).Error
+}
+
+// UpdateLogo 更新股票logo地址
+func (d *Dao) UpdateLogo(ticker string, logo string) error {
+	return d.DB.Model(&StockInfo{}).Where("ticker = ?", ticker).Update("logo", logo).Error
+}
+
+// UpdateMarketCap 更新市值
+func (d *Dao) UpdateMarketCap(ticker string, marketCap decimal.Decimal) error {
+	return d.DB.Model(&StockInfo{}).Where("ticker = ?", ticker).Update("market_cap", marketCap).Error
+}
+
 // Delete 删除股票信息
 func (d *Dao) Delete(ticker string) error {
 	return d.DB.Where("ticker = ?", ticker).Delete(&StockInfo{}).Error
@@ -91,25 +137,88 @@ func (d *Dao) ListAll() ([]StockInfo, error) {
 	return stocks, err
 }
 
-// 获取币股池数量
-func (d *Dao) Count() (int64, float64, float64, float64, error) {
+// Count 获取活跃股票数量及市值、成交量总和与平均涨幅，已下架的股票不计入
+func (d *Dao) Count() (int64, decimal.Decimal, float64, float64, error) {
+	active := func() *gorm.DB {
+		return d.DB.Model(&StockInfo{}).Where("is_active = ?", true)
+	}
+
 	var count int64
-	d.DB.Model(&StockInfo{}).Count(&count)
+	if err := active().Count(&count).Error; err != nil {
+		return 0, decimal.Zero, 0, 0, errors.Wrap(err, "failed on count stocks")
+	}
+	if count == 0 {
+		return 0, decimal.Zero, 0, 0, nil
+	}
 	//计算market_cap字段总和
-	var sumMarketCap float64
-	d.DB.Model(&StockInfo{}).Select("SUM(market_cap)").Scan(&sumMarketCap)
+	var sumMarketCap decimal.Decimal
+	if err := active().Select("COALESCE(SUM(market_cap), 0)").Scan(&sumMarketCap).Error; err != nil {
+		return 0, decimal.Zero, 0, 0, errors.Wrap(err, "failed on sum market cap")
+	}
 	//计算avg_volume总和
 	var sumAvgVolume float64
-	d.DB.Model(&StockInfo{}).Select("SUM(avg_volume)").Scan(&sumAvgVolume)
+	if err := active().Select("COALESCE(SUM(avg_volume), 0)").Scan(&sumAvgVolume).Error; err != nil {
+		return 0, decimal.Zero, 0, 0, errors.Wrap(err, "failed on sum avg volume")
+	}
 	var avgGainPercent float64
-	d.DB.Model(&StockInfo{}).Select("SUM(avg_gain_percent)").Scan(&avgGainPercent)
+	if err := active().Select("COALESCE(SUM(avg_gain_percent), 0)").Scan(&avgGainPercent).Error; err != nil {
+		return 0, decimal.Zero, 0, 0, errors.Wrap(err, "failed on sum avg gain percent")
+	}
 	//查询数据量
 	return count, sumMarketCap, sumAvgVolume, avgGainPercent / float64(count), nil
 }
 
-// SearchByName 根据名称搜索股票
-func (d *Dao) SearchByName(keyword string) ([]StockInfo, error) {
+// 排序字段到数据库列的映射，只允许白名单内的列参与排序
+var stockSortColumns = map[string]string{
+	types.StockSortByMarketCap:   "market_cap",
+	types.StockSortByPrice:       "current_price",
+	types.StockSortByGainPercent: "avg_gain_percent",
+	types.StockSortByVolume:      "avg_volume",
+}
+
+// QueryStocks 按条件搜索、筛选、排序并分页查询股票，同时返回满足条件的总数
+func (d *Dao) QueryStocks(ctx context.Context, filter types.StockFilterParams) ([]StockInfo, int64, error) {
+	db := d.DB.WithContext(ctx).Model(&StockInfo{}).Scopes(searchStocks(filter.Keyword))
+	if filter.Category != "" {
+		db = db.Where("category = ?", filter.Category)
+	}
+	if filter.IsActive != nil {
+		db = db.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "failed on count stocks")
+	}
+	if count == 0 {
+		return []StockInfo{}, 0, nil
+	}
+
+	column, ok := stockSortColumns[filter.SortBy]
+	if !ok {
+		column = stockSortColumns[types.StockSortByMarketCap]
+	}
 	var stocks []StockInfo
-	err := d.DB.Where("name LIKE ?", "%"+keyword+"%").Find(&stocks).Error
-	return stocks, err
+	if err := db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: filter.Desc}).
+		Order("id asc").
+		Limit(filter.PageSize).
+		Offset((filter.Page - 1) * filter.PageSize).
+		Find(&stocks).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "failed on query stocks")
+	}
+
+	return stocks, count, nil
 }
+
+func searchStocks(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" {
+			return db
+		}
+		// 关键字中的%、_按普通字符匹配
+		like := "%" + likeEscaper.Replace(keyword) + "%"
+		return db.Where(`ticker LIKE ? ESCAPE '!' OR name LIKE ? ESCAPE '!'`, like, like)
+	}
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
@@ -0,0 +1,23 @@
+package dao
+
+import (
+	"context"
+
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+)
+
+// QueryStockActivities 查询用户在[from, to]（unix秒）内的股票代币买卖记录，按链上顺序升序
+func (d *Dao) QueryStockActivities(ctx context.Context, chain string, userAddrs []string, from, to int64) ([]stockmodel.Activity, error) {
+	var activities []stockmodel.Activity
+	if len(userAddrs) == 0 {
+		return activities, nil
+	}
+	if err := d.DB.WithContext(ctx).Table(stockmodel.ActivityTableName(chain)).
+		Where("user_address in (?) and event_time >= ? and event_time <= ?", userAddrs, from, to).
+		Order("block_number asc, log_index asc, id asc").
+		Find(&activities).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query stock activities")
+	}
+	return activities, nil
+}
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"context"
+
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// 代币合约自身持有的是买卖池库存，不计为持有人
+func stockHolders(tokenAddress string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("token_address = ? and holder_address <> ? and balance > 0", tokenAddress, tokenAddress)
+	}
+}
+
+// QueryTopStockHolders 按余额从高到低查询代币持有人
+func (d *Dao) QueryTopStockHolders(ctx context.Context, chain string, tokenAddress string, limit int) ([]stockmodel.Balance, error) {
+	var balances []stockmodel.Balance
+	if err := d.DB.WithContext(ctx).Table(stockmodel.BalanceTableName(chain)).
+		Scopes(stockHolders(tokenAddress)).
+		Order("balance desc, id asc").
+		Limit(limit).
+		Find(&balances).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query top holders")
+	}
+	return balances, nil
+}
+
+// CountStockHolders 统计代币当前的持有人数量
+func (d *Dao) CountStockHolders(ctx context.Context, chain string, tokenAddress string) (int64, error) {
+	var count int64
+	if err := d.DB.WithContext(ctx).Table(stockmodel.BalanceTableName(chain)).
+		Scopes(stockHolders(tokenAddress)).
+		Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "failed on count holders")
+	}
+	return count, nil
+}
+
+// SumStockBalances 统计代币所有地址（包括代币合约自身）的余额之和，即已同步的总供应量（链上原始精度）
+func (d *Dao) SumStockBalances(ctx context.Context, chain string, tokenAddress string) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	if err := d.DB.WithContext(ctx).Table(stockmodel.BalanceTableName(chain)).
+		Select("COALESCE(SUM(balance), 0)").
+		Where("token_address = ? and balance > 0", tokenAddress).
+		Scan(&sum).Error; err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed on sum balances")
+	}
+	return sum, nil
+}
+
+// QueryStockHolderCounts 查询[fromDay, toDay]内每天的持有人数量，按日期升序
+func (d *Dao) QueryStockHolderCounts(ctx context.Context, chain string, tokenAddress string, fromDay, toDay int64) ([]stockmodel.HolderCount, error) {
+	var counts []stockmodel.HolderCount
+	if err := d.DB.WithContext(ctx).Table(stockmodel.HolderCountTableName(chain)).
+		Where("token_address = ? and day >= ? and day <= ?", tokenAddress, fromDay, toDay).
+		Order("day asc").
+		Find(&counts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query holder counts")
+	}
+	return counts, nil
+}
+
+// GetLastStockHolderCount 查询day之前最近一天的持有人数量，不存在时返回nil
+func (d *Dao) GetLastStockHolderCount(ctx context.Context, chain string, tokenAddress string, day int64) (*stockmodel.HolderCount, error) {
+	var counts []stockmodel.HolderCount
+	if err := d.DB.WithContext(ctx).Table(stockmodel.HolderCountTableName(chain)).
+		Where("token_address = ? and day < ?", tokenAddress, day).
+		Order("day desc").
+		Limit(1).
+		Find(&counts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query last holder count")
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+	return &counts[0], nil
+}
+
+// QueryStockBalancesByHolders 查询holders在所有股票代币上的余额
+func (d *Dao) QueryStockBalancesByHolders(ctx context.Context, chain string, holders []string) ([]stockmodel.Balance, error) {
+	var balances []stockmodel.Balance
+	if len(holders) == 0 {
+		return balances, nil
+	}
+	if err := d.DB.WithContext(ctx).Table(stockmodel.BalanceTableName(chain)).
+		Where("holder_address in (?) and balance > 0", holders).
+		Find(&balances).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query holder balances")
+	}
+	return balances, nil
+}
@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"context"
+
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+)
+
+// QueryStockPositions 查询用户在chain上的股票代币持仓（由StockCoinSync根据买卖事件维护）
+func (d *Dao) QueryStockPositions(ctx context.Context, chain string, userAddrs []string) ([]stockmodel.Position, error) {
+	var positions []stockmodel.Position
+	if len(userAddrs) == 0 {
+		return positions, nil
+	}
+	if err := d.DB.WithContext(ctx).Table(stockmodel.PositionTableName(chain)).
+		Where("user_address in (?)", userAddrs).
+		Find(&positions).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query stock positions")
+	}
+	return positions, nil
+}
@@ -0,0 +1,21 @@
+package dao
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	tests := []struct {
+		keyword string
+		want    string
+	}{
+		{"AAPL", "AAPL"},
+		{"100%", "100!%"},
+		{"BRK_B", "BRK!_B"},
+		{"a!b", "a!!b"},
+		{"%_!", "!%!_!!"},
+	}
+	for _, tt := range tests {
+		if got := likeEscaper.Replace(tt.keyword); got != tt.want {
+			t.Errorf("escape %q = %q, want %q", tt.keyword, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"context"
+
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// QueryStockTradeStats 查询股票代币的链上成交统计（由StockCoinSync定时汇总），tickers为空时返回空
+func (d *Dao) QueryStockTradeStats(ctx context.Context, chain string, tickers []string) ([]stockmodel.TradeStat, error) {
+	var stats []stockmodel.TradeStat
+	if len(tickers) == 0 {
+		return stats, nil
+	}
+	if err := d.DB.WithContext(ctx).Table(stockmodel.TradeStatTableName(chain)).
+		Where("token_symbol in (?)", tickers).
+		Find(&stats).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query stock trade stats")
+	}
+	return stats, nil
+}
+
+// SumStockVolume24h 统计所有股票代币最近24小时的链上成交额（USDT）
+func (d *Dao) SumStockVolume24h(ctx context.Context, chain string) (decimal.Decimal, error) {
+	var sum decimal.Decimal
+	if err := d.DB.WithContext(ctx).Table(stockmodel.TradeStatTableName(chain)).
+		Select("COALESCE(SUM(volume_24h), 0)").
+		Scan(&sum).Error; err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed on sum stock volume")
+	}
+	return sum, nil
+}
@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Watchlist 用户自选股列表，以 user_address + name 唯一
+type Watchlist struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	UserAddress string    `gorm:"size:42;not null;uniqueIndex:idx_user_name,priority:1" json:"user_address"`
+	Name        string    `gorm:"size:64;not null;uniqueIndex:idx_user_name,priority:2" json:"name"`
+	Sort        int       `gorm:"not null;default:0" json:"sort"` // 列表排序，越小越靠前
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WatchlistItem 自选股列表中的股票，以 watchlist_id + ticker 唯一
+type WatchlistItem struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	WatchlistID int64     `gorm:"not null;uniqueIndex:idx_watchlist_ticker,priority:1" json:"watchlist_id"`
+	Ticker      string    `gorm:"size:20;not null;uniqueIndex:idx_watchlist_ticker,priority:2" json:"ticker"`
+	Sort        int       `gorm:"not null;default:0" json:"sort"` // 列表内排序，越小越靠前
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func WatchlistTableName() string {
+	return "watchlist"
+}
+
+func WatchlistItemTableName() string {
+	return "watchlist_item"
+}
+
+// CreateWatchlist 创建自选股列表，排在用户已有列表的最后
+func (d *Dao) CreateWatchlist(ctx context.Context, watchlist *Watchlist, tickers []string) error {
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var maxSort int
+		if err := tx.Table(WatchlistTableName()).
+			Select("COALESCE(MAX(sort), -1)").
+			Where("user_address = ?", watchlist.UserAddress).
+			Scan(&maxSort).Error; err != nil {
+			return errors.Wrap(err, "failed on query watchlist sort")
+		}
+		watchlist.Sort = maxSort + 1
+
+		if err := tx.Table(WatchlistTableName()).Create(watchlist).Error; err != nil {
+			return errors.Wrap(err, "failed on create watchlist")
+		}
+		return replaceWatchlistItems(tx, watchlist.ID, tickers)
+	})
+}
+
+// QueryWatchlists 获取用户的全部自选股列表，按排序返回
+func (d *Dao) QueryWatchlists(ctx context.Context, userAddress string) ([]Watchlist, error) {
+	var watchlists []Watchlist
+	if err := d.DB.WithContext(ctx).Table(WatchlistTableName()).
+		Where("user_address = ?", userAddress).
+		Order("sort asc, id asc").
+		Find(&watchlists).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query watchlists")
+	}
+
+	return watchlists, nil
+}
+
+// GetWatchlist 获取用户的指定自选股列表，不存在或不属于该用户时返回nil
+func (d *Dao) GetWatchlist(ctx context.Context, userAddress string, id int64) (*Watchlist, error) {
+	var watchlists []Watchlist
+	if err := d.DB.WithContext(ctx).Table(WatchlistTableName()).
+		Where("id = ? AND user_address = ?", id, userAddress).
+		Limit(1).
+		Find(&watchlists).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on get watchlist")
+	}
+	if len(watchlists) == 0 {
+		return nil, nil
+	}
+
+	return &watchlists[0], nil
+}
+
+// RenameWatchlist 修改自选股列表名称
+func (d *Dao) RenameWatchlist(ctx context.Context, userAddress string, id int64, name string) error {
+	if err := d.DB.WithContext(ctx).Table(WatchlistTableName()).
+		Where("id = ? AND user_address = ?", id, userAddress).
+		Updates(map[string]interface{}{"name": name, "updated_at": time.Now()}).Error; err != nil {
+		return errors.Wrap(err, "failed on rename watchlist")
+	}
+
+	return nil
+}
+
+// DeleteWatchlist 删除自选股列表及其中的股票
+func (d *Dao) DeleteWatchlist(ctx context.Context, userAddress string, id int64) error {
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(WatchlistTableName()).Where("id = ? AND user_address = ?", id, userAddress).Delete(&Watchlist{})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "failed on delete watchlist")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Table(WatchlistItemTableName()).Where("watchlist_id = ?", id).Delete(&WatchlistItem{}).Error; err != nil {
+			return errors.Wrap(err, "failed on delete watchlist items")
+		}
+		return nil
+	})
+}
+
+// ReorderWatchlists 按ids的顺序重排用户的自选股列表
+func (d *Dao) ReorderWatchlists(ctx context.Context, userAddress string, ids []int64) error {
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Table(WatchlistTableName()).
+				Where("id = ? AND user_address = ?", id, userAddress).
+				Update("sort", i).Error; err != nil {
+				return errors.Wrap(err, "failed on reorder watchlists")
+			}
+		}
+		return nil
+	})
+}
+
+// QueryWatchlistItems 批量获取自选股列表中的股票，按列表内排序返回
+func (d *Dao) QueryWatchlistItems(ctx context.Context, watchlistIDs []int64) ([]WatchlistItem, error) {
+	var items []WatchlistItem
+	if len(watchlistIDs) == 0 {
+		return items, nil
+	}
+
+	if err := d.DB.WithContext(ctx).Table(WatchlistItemTableName()).
+		Where("watchlist_id IN ?", watchlistIDs).
+		Order("sort asc, id asc").
+		Find(&items).Error; err != nil {
+		return nil, errors.Wrap(err, "failed on query watchlist items")
+	}
+
+	return items, nil
+}
+
+// ReplaceWatchlistItems 以tickers的顺序整体替换列表中的股票，用于增删及排序
+func (d *Dao) ReplaceWatchlistItems(ctx context.Context, watchlistID int64, tickers []string) error {
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(WatchlistItemTableName()).Where("watchlist_id = ?", watchlistID).Delete(&WatchlistItem{}).Error; err != nil {
+			return errors.Wrap(err, "failed on clear watchlist items")
+		}
+		if err := replaceWatchlistItems(tx, watchlistID, tickers); err != nil {
+			return err
+		}
+		return tx.Table(WatchlistTableName()).Where("id = ?", watchlistID).Update("updated_at", time.Now()).Error
+	})
+}
+
+func replaceWatchlistItems(tx *gorm.DB, watchlistID int64, tickers []string) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	items := make([]WatchlistItem, 0, len(tickers))
+	for i, ticker := range tickers {
+		items = append(items, WatchlistItem{
+			WatchlistID: watchlistID,
+			Ticker:      ticker,
+			Sort:        i,
+		})
+	}
+	if err := tx.Table(WatchlistItemTableName()).CreateInBatches(items, 100).Error; err != nil {
+		return errors.Wrap(err, "failed on create watchlist items")
+	}
+
+	return nil
+}
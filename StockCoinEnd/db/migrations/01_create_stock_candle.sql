@@ -0,0 +1,20 @@
+
+create table stock_candle
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    ticker        varchar(20)      not null comment '股票代码',
+    time_interval varchar(8)       not null comment 'K线周期(1d)',
+    timestamp     bigint           not null comment '开盘时间(毫秒)',
+    open          double default 0 not null comment '开盘价',
+    high          double default 0 not null comment '最高价',
+    low           double default 0 not null comment '最低价',
+    close         double default 0 not null comment '收盘价',
+    volume        double default 0 not null comment '成交量',
+    item_count    bigint default 0 not null comment '成交笔数',
+    created_at    datetime         null comment '创建时间',
+    updated_at    datetime         null comment '更新时间',
+    constraint idx_ticker_interval_ts
+        unique (ticker, time_interval, timestamp)
+)
+    collate = utf8mb4_general_ci;
@@ -0,0 +1,12 @@
+
+-- 价格/市值由float改为decimal，避免浮点误差
+alter table stock_info
+    modify avg_gain      decimal(36, 18) default 0 null comment '7日平均涨幅',
+    modify current_price decimal(36, 18) default 0 null comment '当前价格',
+    modify market_cap    decimal(36, 18) default 0 null comment '市值';
+
+-- 旧数据为单精度float，转换后去掉多余的尾数噪声，下一次轮询会写入精确值
+update stock_info
+set avg_gain      = round(avg_gain, 4),
+    current_price = round(current_price, 4),
+    market_cap    = round(market_cap, 0);
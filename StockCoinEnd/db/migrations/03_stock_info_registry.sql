@@ -0,0 +1,8 @@
+
+-- 股票列表改为由TokenFactory注册表驱动
+alter table stock_info
+    add category  varchar(20) default 'stock' not null comment '代币类别(stock/crypto/commodity/forex)',
+    add is_active tinyint(1)  default 1       not null comment '是否在TokenFactory中处于活跃状态';
+
+create index idx_stock_info_category on stock_info (category);
+create index idx_stock_info_is_active on stock_info (is_active);
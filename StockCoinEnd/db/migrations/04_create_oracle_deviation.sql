@@ -0,0 +1,17 @@
+create table oracle_deviation
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    ticker        varchar(20)                 not null comment '股票代码',
+    oracle_price  decimal(36, 18) default 0   not null comment '预言机聚合价格',
+    market_price  decimal(36, 18) default 0   not null comment '市场价格',
+    deviation_bps bigint          default 0   not null comment '偏差(基点)，预言机高于市场为正',
+    is_alert      tinyint(1)      default 0   not null comment '是否超过告警阈值',
+    is_stale      tinyint(1)      default 0   not null comment '采样时休市或市场价格已过期',
+    timestamp     bigint                      not null comment '采样时间(毫秒)',
+    created_at    datetime                    null comment '创建时间'
+)
+    collate = utf8mb4_general_ci;
+
+create index idx_ticker_ts
+    on oracle_deviation (ticker, timestamp);
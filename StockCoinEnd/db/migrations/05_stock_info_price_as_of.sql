@@ -0,0 +1,4 @@
+
+-- 价格附带行情时间，接口据此返回as_of/is_stale
+alter table stock_info
+    add price_as_of bigint default 0 not null comment '当前价格对应的时间(毫秒)';
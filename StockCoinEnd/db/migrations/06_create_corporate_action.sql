@@ -0,0 +1,18 @@
+create table corporate_action
+(
+    id          bigint auto_increment comment '主键'
+        primary key,
+    ticker      varchar(20)                   not null comment '股票代码',
+    action_type varchar(16)                   not null comment '类型(split/dividend)',
+    ex_date     bigint                        not null comment '除权除息日(纽约时间零点，毫秒)',
+    pay_date    bigint          default 0     not null comment '派息日(毫秒)，仅分红',
+    split_from  double          default 0     not null comment '拆股前股数',
+    split_to    double          default 0     not null comment '拆股后股数',
+    cash_amount decimal(36, 18) default 0     not null comment '每股分红金额',
+    currency    varchar(8)      default ''    not null comment '分红币种',
+    created_at  datetime                      null comment '创建时间',
+    updated_at  datetime                      null comment '更新时间',
+    constraint idx_ticker_type_ex_date
+        unique (ticker, action_type, ex_date)
+)
+    collate = utf8mb4_general_ci;
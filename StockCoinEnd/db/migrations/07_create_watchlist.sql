@@ -0,0 +1,26 @@
+create table watchlist
+(
+    id           bigint auto_increment comment '主键'
+        primary key,
+    user_address varchar(42)   not null comment '用户地址',
+    name         varchar(64)   not null comment '列表名称',
+    sort         int default 0 not null comment '列表排序',
+    created_at   datetime      null comment '创建时间',
+    updated_at   datetime      null comment '更新时间',
+    constraint idx_user_name
+        unique (user_address, name)
+)
+    collate = utf8mb4_general_ci;
+
+create table watchlist_item
+(
+    id           bigint auto_increment comment '主键'
+        primary key,
+    watchlist_id bigint        not null comment '自选股列表ID',
+    ticker       varchar(20)   not null comment '股票代码',
+    sort         int default 0 not null comment '列表内排序',
+    created_at   datetime      null comment '创建时间',
+    constraint idx_watchlist_ticker
+        unique (watchlist_id, ticker)
+)
+    collate = utf8mb4_general_ci;
@@ -0,0 +1,50 @@
+create table price_alert
+(
+    id                bigint auto_increment comment '主键'
+        primary key,
+    user_address      varchar(42)                 not null comment '用户地址',
+    ticker            varchar(20)                 not null comment '股票代码',
+    alert_condition   varchar(20)                 not null comment '提醒条件(price_above/price_below/day_change)',
+    threshold         decimal(36, 18) default 0   not null comment '价格阈值，day_change时为百分比',
+    mode              varchar(20)                 not null comment '提醒模式(once/recurring)',
+    cooldown_seconds  bigint          default 0   not null comment '两次触发的最小间隔(秒)',
+    is_active         tinyint(1)      default 1   not null comment '是否启用',
+    last_triggered_at bigint          default 0   not null comment '最近一次触发时间(毫秒)',
+    trigger_count     bigint          default 0   not null comment '累计触发次数',
+    created_at        datetime                    null comment '创建时间',
+    updated_at        datetime                    null comment '更新时间'
+)
+    collate = utf8mb4_general_ci;
+
+create index idx_user_address
+    on price_alert (user_address);
+
+create index idx_ticker_active
+    on price_alert (ticker, is_active);
+
+create table price_alert_delivery
+(
+    id              bigint auto_increment comment '主键'
+        primary key,
+    alert_id        bigint                      not null comment '价格提醒ID',
+    user_address    varchar(42)                 not null comment '用户地址',
+    ticker          varchar(20)                 not null comment '股票代码',
+    alert_condition varchar(20)                 not null comment '提醒条件',
+    threshold       decimal(36, 18) default 0   not null comment '触发时的阈值',
+    price           decimal(36, 18) default 0   not null comment '触发时的价格',
+    change_percent  double          default 0   not null comment '触发时的当日涨跌幅(百分比)',
+    triggered_at    bigint                      not null comment '触发时间(毫秒)',
+    status          varchar(20)                 not null comment '投递状态(pending/delivered/failed)',
+    delivered_at    bigint          default 0   not null comment '投递完成时间(毫秒)',
+    created_at      datetime                    null comment '创建时间'
+)
+    collate = utf8mb4_general_ci;
+
+create index idx_alert_id
+    on price_alert_delivery (alert_id);
+
+create index idx_user_address
+    on price_alert_delivery (user_address);
+
+create index idx_status
+    on price_alert_delivery (status);
@@ -0,0 +1,15 @@
+create table overview_snapshot
+(
+    id                bigint auto_increment comment '主键'
+        primary key,
+    snapshot_at       bigint                      not null comment '快照时间(毫秒)，按快照间隔对齐',
+    total_market_cap  decimal(36, 18) default 0   not null comment '股票总市值',
+    pool_token_value  decimal(36, 18) default 0   not null comment '币股池代币按当前价格折算的价值(USDT)',
+    pool_usdt_reserve decimal(36, 18) default 0   not null comment '币股池持有的USDT',
+    volume_24h        decimal(36, 18) default 0   not null comment '近24小时链上成交额(USDT)',
+    stock_count       bigint          default 0   not null comment '股票数量',
+    created_at        datetime                    null comment '创建时间',
+    constraint uk_snapshot_at
+        unique (snapshot_at)
+)
+    collate = utf8mb4_general_ci;
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	_ "net/http/pprof"
+	"time"
 
 	"github.com/locey/CryptoStock/StockCoinEnd/api/router"
+	v1 "github.com/locey/CryptoStock/StockCoinEnd/api/v1"
 	"github.com/locey/CryptoStock/StockCoinEnd/app"
 	"github.com/locey/CryptoStock/StockCoinEnd/config"
 	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
@@ -37,11 +40,30 @@ func main() {
 	}
 	// Initialize router
 	r := router.NewRouter(serverCtx)
-	// 启动每分钟轮询股票信息
-	//go service.StartStockDataPoller(serverCtx, 2*time.Minute)
-
-	// 定时获取股票信息，市值需要更新（其它基础信息不需要更新）
-	//go v1.Init(serverCtx, 1*time.Minute)
+	// 定时从TokenFactory同步股票列表，市值需要更新（其它基础信息不需要更新）
+	if c.TokenFactory != nil && c.TokenFactory.RefreshInterval > 0 {
+		go v1.Init(serverCtx, time.Duration(c.TokenFactory.RefreshInterval)*time.Second)
+	}
+	// 启动轮询股票信息
+	if c.MarketData != nil && c.MarketData.PollInterval > 0 {
+		go service.StartStockDataPoller(serverCtx)
+	}
+	// 定时同步拆股、分红等公司行为
+	if c.MarketData != nil && c.MarketData.ActionsRefreshInterval > 0 {
+		go service.StartCorporateActionSync(serverCtx, time.Duration(c.MarketData.ActionsRefreshInterval)*time.Second)
+	}
+	// 启动预言机与市场价格偏差监控
+	if c.OracleMonitor != nil && c.OracleMonitor.Interval > 0 {
+		go service.StartOracleMonitor(serverCtx, time.Duration(c.OracleMonitor.Interval)*time.Second)
+	}
+	// 定时记录平台总览快照
+	if c.Overview != nil && c.Overview.SnapshotInterval > 0 {
+		go service.StartOverviewSnapshot(serverCtx, time.Duration(c.Overview.SnapshotInterval)*time.Second)
+	}
+	// 订阅行情频道，向本副本的推送连接分发价格更新
+	if serverCtx.PriceHub != nil {
+		go serverCtx.PriceHub.Run(context.Background())
+	}
 	go service.StartAirdropEventListener(serverCtx)
 
 	app, err := app.NewPlatform(c, r, serverCtx)
@@ -0,0 +1,121 @@
+package asset
+
+import (
+	"bytes"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ContentTypePNG = "image/png"
+
+	maxPixels = 16_000_000 // 原图像素上限，解码及缩放时的RGBA副本各占4字节/像素，避免超大图片耗尽内存
+)
+
+// 只接受可以解码、缩放的位图格式，SVG等可能携带脚本的格式一律拒绝
+var allowedContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// checkImage 按内容识别MIME类型（不信任响应头），并校验图片尺寸
+func checkImage(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !allowedContentTypes[contentType] {
+		return "", errors.Errorf("unsupported content type: %s", contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.Wrap(err, "failed on decode image config")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return "", errors.Errorf("unsupported image dimensions: %dx%d", cfg.Width, cfg.Height)
+	}
+	return contentType, nil
+}
+
+// resizePNG 将图片等比缩放到不超过size*size并编码为PNG，小于size的图片不放大
+func resizePNG(data []byte, size int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on decode image")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resize(src, size)); err != nil {
+		return nil, errors.Wrap(err, "failed on encode png")
+	}
+	return buf.Bytes(), nil
+}
+
+// resize 按区域平均缩小图片
+func resize(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	if w <= size && h <= size {
+		return rgba
+	}
+
+	dw, dh := size, size
+	if w >= h {
+		dh = max(1, h*size/w)
+	} else {
+		dw = max(1, w*size/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				offset := rgba.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					for i := 0; i < 4; i++ {
+						sum[i] += int(rgba.Pix[offset+i])
+					}
+					offset += 4
+				}
+			}
+
+			n := (sy1 - sy0) * (sx1 - sx0)
+			offset := dst.PixOffset(x, y)
+			for i := 0; i < 4; i++ {
+				dst.Pix[offset+i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// placeholderPNG 生成size*size的纯色占位图，颜色由seed决定，同一seed总是相同
+func placeholderPNG(seed string, size int) ([]byte, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(seed))
+	sum := h.Sum32()
+	// 取较浅的颜色，避免与前端文字颜色冲突
+	fill := color.RGBA{R: 128 + uint8(sum)%128, G: 128 + uint8(sum>>8)%128, B: 128 + uint8(sum>>16)%128, A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: fill}, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "failed on encode placeholder")
+	}
+	return buf.Bytes(), nil
+}
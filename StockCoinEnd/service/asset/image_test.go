@@ -0,0 +1,123 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gifHeader 只有文件头的GIF，DecodeConfig只读取文件头中的宽高
+func gifHeader(w, h uint16) []byte {
+	data := []byte("GIF89a")
+	data = binary.LittleEndian.AppendUint16(data, w)
+	data = binary.LittleEndian.AppendUint16(data, h)
+	return append(data, 0, 0, 0)
+}
+
+func TestCheckImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		wantErr     bool
+	}{
+		{name: "png", data: encodeTestImage(t, "png", 4, 4), contentType: "image/png"},
+		{name: "jpeg", data: encodeTestImage(t, "jpeg", 4, 4), contentType: "image/jpeg"},
+		{name: "gif", data: encodeTestImage(t, "gif", 4, 4), contentType: "image/gif"},
+		{name: "gif at pixel cap", data: gifHeader(4000, 4000), contentType: "image/gif"},
+		{name: "gif over pixel cap", data: gifHeader(4000, 4001), wantErr: true},
+		{name: "zero width", data: gifHeader(0, 10), wantErr: true},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), wantErr: true},
+		{name: "html", data: []byte(`<html><body>not an image</body></html>`), wantErr: true},
+		// 内容识别为png但无法解码
+		{name: "truncated png", data: encodeTestImage(t, "png", 4, 4)[:20], wantErr: true},
+	}
+	for _, tt := range tests {
+		contentType, err := checkImage(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if contentType != tt.contentType {
+			t.Errorf("%s: content type = %q, want %q", tt.name, contentType, tt.contentType)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name  string
+		w, h  int
+		size  int
+		wantW int
+		wantH int
+	}{
+		{"smaller than size", 40, 30, 64, 40, 30},
+		{"square", 256, 256, 64, 64, 64},
+		{"landscape", 200, 100, 64, 64, 32},
+		{"portrait", 100, 200, 64, 32, 64},
+		{"thin line keeps one pixel", 1000, 2, 64, 64, 1},
+	}
+	for _, tt := range tests {
+		src := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+		got := resize(src, tt.size).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("%s: resize(%dx%d, %d) = %dx%d, want %dx%d", tt.name, tt.w, tt.h, tt.size, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// 按区域取平均值：左半黑、右半白缩小到2x1
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 14; x++ {
+			c := color.RGBA{A: 255}
+			if x >= 12 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	dst := resize(src, 2).(*image.RGBA)
+	if dst.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds = %v", dst.Bounds())
+	}
+	if got := dst.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("left pixel = %v, want black", got)
+	}
+	if got := dst.RGBAAt(1, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("right pixel = %v, want white", got)
+	}
+
+	// resizePNG输出为PNG且不超过size
+	out, err := resizePNG(encodeTestImage(t, "jpeg", 300, 150), 128)
+	if err != nil {
+		t.Fatalf("resizePNG: %v", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+	if err != nil || format != "png" || cfg.Width != 128 || cfg.Height != 64 {
+		t.Errorf("resizePNG output = %s %dx%d, %v", format, cfg.Width, cfg.Height, err)
+	}
+}
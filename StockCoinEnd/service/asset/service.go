@@ -0,0 +1,357 @@
+package asset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/syncx"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+)
+
+const (
+	defaultBaseURL         = "/api/v1/assets"
+	defaultMaxSize         = 5 << 20 // 5MB
+	defaultTimeout         = 10 * time.Second
+	defaultIPFSGateway     = "https://ipfs.io/ipfs/"
+	defaultPlaceholderSize = 128
+
+	// 拉取失败的图片在该时间内不再重试，直接返回占位图
+	failureRetryInterval = 10 * time.Minute
+
+	idLength = 32
+)
+
+var defaultSizes = []int{64, 128, 256, 512}
+
+// Image 返回给调用方的图片内容
+type Image struct {
+	Data        []byte
+	ContentType string
+	Placeholder bool // 是否为占位图
+}
+
+// Service 图片代理：首次访问时拉取远程图片保存到存储中，之后直接从存储返回，并按需生成缩略图
+// 图片以来源地址的哈希作为id，对外只暴露稳定的代理地址，前端不会直接访问第三方图片服务
+type Service struct {
+	storage     Storage
+	client      *http.Client
+	baseURL     string
+	ipfsGateway string
+	maxSize     int64
+	sizes       map[int]bool
+	flight      syncx.SingleFlight
+
+	mu     sync.Mutex
+	failed map[string]time.Time
+}
+
+func New(c *config.Asset) (*Service, error) {
+	if c == nil {
+		c = &config.Asset{}
+	}
+
+	storage, err := NewLocalStorage(c.StorageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if !c.AllowPrivateHosts {
+		dialer.Control = publicAddressOnly
+	}
+
+	s := &Service{
+		storage: storage,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+		},
+		baseURL:     strings.TrimRight(c.BaseURL, "/"),
+		ipfsGateway: c.IPFSGateway,
+		maxSize:     c.MaxSize,
+		sizes:       make(map[int]bool),
+		flight:      syncx.NewSingleFlight(),
+		failed:      make(map[string]time.Time),
+	}
+	if s.baseURL == "" {
+		s.baseURL = defaultBaseURL
+	}
+	if s.ipfsGateway == "" {
+		s.ipfsGateway = defaultIPFSGateway
+	}
+	if s.maxSize <= 0 {
+		s.maxSize = defaultMaxSize
+	}
+	sizes := c.Sizes
+	if len(sizes) == 0 {
+		sizes = defaultSizes
+	}
+	for _, size := range sizes {
+		s.sizes[size] = true
+	}
+	return s, nil
+}
+
+// ID 来源地址对应的图片id
+func ID(sourceURL string) string {
+	sum := sha256.Sum256([]byte(sourceURL))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// URL 登记图片来源并返回稳定的代理地址，ipfs://地址通过网关访问
+func (s *Service) URL(ctx context.Context, sourceURL string) (string, error) {
+	source, err := s.normalizeSource(sourceURL)
+	if err != nil {
+		return "", err
+	}
+
+	id := ID(source)
+	key := sourceKey(id)
+	exists, err := s.storage.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := s.storage.Put(ctx, key, []byte(source)); err != nil {
+			return "", err
+		}
+	}
+
+	return s.baseURL + "/" + id, nil
+}
+
+// PlaceholderURL 没有图片来源时使用的占位图地址
+func (s *Service) PlaceholderURL(seed string) string {
+	return s.baseURL + "/placeholder/" + url.PathEscape(seed)
+}
+
+// ValidSize 判断缩略图尺寸是否允许，0表示原图
+func (s *Service) ValidSize(size int) bool {
+	return size == 0 || s.sizes[size]
+}
+
+// Get 返回id对应的图片，size为0时返回原图；图片不存在或拉取失败时返回占位图
+func (s *Service) Get(ctx context.Context, id string, size int) (*Image, error) {
+	if !s.ValidSize(size) {
+		return nil, errcode.NewCustomErr(fmt.Sprintf("unsupported size: %d", size))
+	}
+
+	img, err := s.get(ctx, id, size)
+	if err != nil {
+		if err != ErrNotExist {
+			log.Printf("获取图片 %s 失败，返回占位图: %v", id, err)
+		}
+		return s.Placeholder(id, size)
+	}
+	return img, nil
+}
+
+// Placeholder 生成seed对应的占位图
+func (s *Service) Placeholder(seed string, size int) (*Image, error) {
+	if !s.ValidSize(size) {
+		return nil, errcode.NewCustomErr(fmt.Sprintf("unsupported size: %d", size))
+	}
+	if size == 0 {
+		size = defaultPlaceholderSize
+	}
+
+	data, err := placeholderPNG(seed, size)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Data: data, ContentType: ContentTypePNG, Placeholder: true}, nil
+}
+
+func (s *Service) get(ctx context.Context, id string, size int) (*Image, error) {
+	if !isValidID(id) {
+		return nil, ErrNotExist
+	}
+
+	if size > 0 {
+		data, err := s.storage.Get(ctx, variantKey(id, size))
+		if err == nil {
+			return &Image{Data: data, ContentType: ContentTypePNG}, nil
+		}
+		if err != ErrNotExist {
+			return nil, err
+		}
+	}
+
+	original, err := s.original(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		contentType, err := checkImage(original)
+		if err != nil {
+			return nil, err
+		}
+		return &Image{Data: original, ContentType: contentType}, nil
+	}
+
+	// 同一缩略图的并发请求只生成一次
+	val, err := s.flight.Do(variantKey(id, size), func() (interface{}, error) {
+		data, err := resizePNG(original, size)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.storage.Put(ctx, variantKey(id, size), data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Data: val.([]byte), ContentType: ContentTypePNG}, nil
+}
+
+// original 读取原图，本地不存在时从来源地址拉取并保存
+func (s *Service) original(ctx context.Context, id string) ([]byte, error) {
+	data, err := s.storage.Get(ctx, originalKey(id))
+	if err != ErrNotExist {
+		return data, err
+	}
+
+	val, err := s.flight.Do(originalKey(id), func() (interface{}, error) {
+		if s.recentlyFailed(id) {
+			return nil, errors.New("recently failed, skip fetching")
+		}
+
+		source, err := s.storage.Get(ctx, sourceKey(id))
+		if err != nil {
+			return nil, err
+		}
+		data, err := s.fetch(ctx, string(source))
+		if err != nil {
+			s.markFailed(id)
+			return nil, err
+		}
+		if err := s.storage.Put(ctx, originalKey(id), data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.([]byte), nil
+}
+
+// fetch 拉取远程图片，限制大小并校验内容类型
+func (s *Service) fetch(ctx context.Context, sourceURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on create request")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on do request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status: %s", resp.Status)
+	}
+	if resp.ContentLength > s.maxSize {
+		return nil, errors.Errorf("image too large: %d bytes", resp.ContentLength)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s.maxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read response")
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.Errorf("image larger than %d bytes", s.maxSize)
+	}
+
+	if _, err := checkImage(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *Service) normalizeSource(sourceURL string) (string, error) {
+	source := strings.TrimSpace(sourceURL)
+	if strings.HasPrefix(source, "ipfs://") {
+		source = strings.TrimRight(s.ipfsGateway, "/") + "/" + strings.TrimPrefix(strings.TrimPrefix(source, "ipfs://"), "ipfs/")
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid image url")
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.Errorf("unsupported image url: %s", sourceURL)
+	}
+	return u.String(), nil
+}
+
+func (s *Service) recentlyFailed(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failedAt, ok := s.failed[id]
+	if ok && time.Since(failedAt) >= failureRetryInterval {
+		delete(s.failed, id)
+		return false
+	}
+	return ok
+}
+
+func (s *Service) markFailed(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[id] = time.Now()
+}
+
+// publicAddressOnly 拒绝连接内网、回环等非公网地址，防止通过图片地址访问内部服务
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() {
+		return errors.Errorf("refuse to connect to non-public address %s", address)
+	}
+	return nil
+}
+
+func isValidID(id string) bool {
+	if len(id) != idLength {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func sourceKey(id string) string {
+	return "sources/" + id
+}
+
+func originalKey(id string) string {
+	return "originals/" + id
+}
+
+func variantKey(id string, size int) string {
+	return fmt.Sprintf("variants/%s_%d.png", id, size)
+}
@@ -0,0 +1,93 @@
+package asset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+)
+
+func TestPublicAddressOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"8.8.8.8:443", false},
+		{"[2606:4700:4700::1111]:443", false},
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.0.0.1:80", true},
+		{"172.16.5.4:80", true},
+		{"192.168.1.1:80", true},
+		{"[fd00::1]:80", true},
+		// 云服务器元数据地址
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"224.0.0.1:80", true},
+		// IPv4映射的IPv6地址
+		{"[::ffff:127.0.0.1]:80", true},
+		{"localhost:80", true},
+		{"8.8.8.8", true},
+	}
+	for _, tt := range tests {
+		err := publicAddressOnly("tcp", tt.address, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("publicAddressOnly(%s) err = %v, wantErr %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFetchRejectsPrivateHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(encodeTestImage(t, "png", 4, 4))
+	}))
+	defer server.Close()
+
+	s, err := New(&config.Asset{StorageDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.fetch(context.Background(), server.URL); err == nil {
+		t.Errorf("fetch(%s): expected loopback address to be refused", server.URL)
+	}
+
+	s, err = New(&config.Asset{StorageDir: t.TempDir(), AllowPrivateHosts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.fetch(context.Background(), server.URL); err != nil {
+		t.Errorf("fetch with allow_private_hosts: %v", err)
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	s := &Service{ipfsGateway: "https://gateway.example.com/ipfs/"}
+	tests := []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{source: "https://example.com/logo.png", want: "https://example.com/logo.png"},
+		{source: "  http://example.com/a.png ", want: "http://example.com/a.png"},
+		{source: "ipfs://QmHash/logo.png", want: "https://gateway.example.com/ipfs/QmHash/logo.png"},
+		{source: "ipfs://ipfs/QmHash", want: "https://gateway.example.com/ipfs/QmHash"},
+		{source: "ftp://example.com/logo.png", wantErr: true},
+		{source: "file:///etc/passwd", wantErr: true},
+		{source: "data:image/png;base64,AAAA", wantErr: true},
+		{source: "/relative/logo.png", wantErr: true},
+		{source: "https://", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := s.normalizeSource(tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeSource(%q) err = %v, wantErr %v", tt.source, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeSource(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
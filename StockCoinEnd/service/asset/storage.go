@@ -0,0 +1,109 @@
+package asset
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotExist 存储中不存在该key
+var ErrNotExist = errors.New("asset not exist")
+
+// Storage 图片存储，key为以/分隔的相对路径
+type Storage interface {
+	// Get 读取key对应的内容，不存在时返回ErrNotExist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put 写入key对应的内容，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte) error
+	// Exists 判断key是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// LocalStorage 基于本地磁盘的存储
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, errors.New("storage dir is required for local storage")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed on create storage dir")
+	}
+
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on read %s", key)
+	}
+	return data, nil
+}
+
+// Put 先写临时文件再重命名，避免并发读取到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrapf(err, "failed on create dir of %s", key)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return errors.Wrapf(err, "failed on create temp file of %s", key)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "failed on write %s", key)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed on close %s", key)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed on rename %s", key)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed on stat %s", key)
+	}
+	return true, nil
+}
+
+// path 将key转换为存储目录下的文件路径，拒绝跳出存储目录的key
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == "." || filepath.IsAbs(cleaned) || strings.HasPrefix(cleaned, "..") {
+		return "", errors.Errorf("invalid asset key: %s", key)
+	}
+	return filepath.Join(s.dir, cleaned), nil
+}
@@ -0,0 +1,103 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLocalProvider(t *testing.T) {
+	// viper读取的key为小写，返回时统一为大写
+	p, err := NewLocalProvider(map[string]float64{"cny": 7.1, "eur": 0.92})
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+	rates, err := p.GetRates(context.Background())
+	if err != nil {
+		t.Fatalf("GetRates: %v", err)
+	}
+	if rates.Base != BaseCurrency || len(rates.Rates) != 2 {
+		t.Fatalf("rates = %+v", rates)
+	}
+	if !rates.Rates["CNY"].Equal(decimal.RequireFromString("7.1")) || !rates.Rates["EUR"].Equal(decimal.RequireFromString("0.92")) {
+		t.Errorf("rates = %v", rates.Rates)
+	}
+
+	// 调用方修改返回结果不影响数据源
+	rates.Rates["CNY"] = decimal.Zero
+	again, _ := p.GetRates(context.Background())
+	if !again.Rates["CNY"].Equal(decimal.RequireFromString("7.1")) {
+		t.Errorf("provider rates were modified through the returned map")
+	}
+
+	for _, rate := range []float64{0, -1} {
+		if _, err := NewLocalProvider(map[string]float64{"cny": rate}); err == nil {
+			t.Errorf("NewLocalProvider(%v): expected an error", rate)
+		}
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"result":"success","base_code":"USD","time_last_update_unix":1717372801,"rates":{"USD":1,"cny":7.2412,"EUR":0.9189}}`,
+		},
+		{
+			name:    "api error",
+			status:  http.StatusOK,
+			body:    `{"result":"error","error-type":"unsupported-code"}`,
+			wantErr: true,
+		},
+		{
+			name:    "http error",
+			status:  http.StatusInternalServerError,
+			body:    `internal error`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			status:  http.StatusOK,
+			body:    `{"result":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		rates, err := NewHTTPProvider(server.URL+"/", time.Second).GetRates(context.Background())
+		server.Close()
+		if path != "/v6/latest/USD" {
+			t.Errorf("%s: request path = %s", tt.name, path)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+
+		if rates.Base != BaseCurrency || rates.AsOf != 1717372801000 {
+			t.Errorf("%s: base/asOf = %s/%d", tt.name, rates.Base, rates.AsOf)
+		}
+		if !rates.Rates["CNY"].Equal(decimal.RequireFromString("7.2412")) || !rates.Rates["EUR"].Equal(decimal.RequireFromString("0.9189")) {
+			t.Errorf("%s: rates = %v", tt.name, rates.Rates)
+		}
+	}
+}
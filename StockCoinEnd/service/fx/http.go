@@ -0,0 +1,84 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const defaultHTTPBaseURL = "https://open.er-api.com"
+
+// latestRatesResponse /v6/latest/{base} 接口返回结构
+type latestRatesResponse struct {
+	Result             string                     `json:"result"`
+	BaseCode           string                     `json:"base_code"`
+	TimeLastUpdateUnix int64                      `json:"time_last_update_unix"`
+	Rates              map[string]decimal.Decimal `json:"rates"`
+	ErrorType          string                     `json:"error-type"`
+}
+
+// HTTPProvider 通过HTTP汇率接口获取汇率
+type HTTPProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewHTTPProvider(baseURL string, timeout time.Duration) *HTTPProvider {
+	if baseURL == "" {
+		baseURL = defaultHTTPBaseURL
+	}
+
+	return &HTTPProvider{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return ProviderHTTP
+}
+
+func (p *HTTPProvider) GetRates(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/v6/latest/"+BaseCurrency, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on create request")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on do request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("API错误: %s - %s", resp.Status, string(body))
+	}
+
+	var result latestRatesResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, errors.Wrap(err, "JSON解析失败")
+	}
+	if result.Result != "success" {
+		return nil, errors.Errorf("API错误: %s", result.ErrorType)
+	}
+
+	rates := make(map[string]decimal.Decimal, len(result.Rates))
+	for currency, rate := range result.Rates {
+		rates[NormalizeCurrency(currency)] = rate
+	}
+	return &Rates{
+		Base:  BaseCurrency,
+		Rates: rates,
+		AsOf:  time.Unix(result.TimeLastUpdateUnix, 0).UnixMilli(),
+	}, nil
+}
@@ -0,0 +1,44 @@
+package fx
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// LocalProvider 使用配置中的固定汇率，不依赖外部接口
+type LocalProvider struct {
+	rates map[string]decimal.Decimal
+	asOf  int64
+}
+
+func NewLocalProvider(rates map[string]float64) (*LocalProvider, error) {
+	p := &LocalProvider{
+		rates: make(map[string]decimal.Decimal, len(rates)),
+		asOf:  time.Now().UnixMilli(),
+	}
+	for currency, rate := range rates {
+		if rate <= 0 {
+			return nil, errors.Errorf("invalid fx rate of %s: %v", currency, rate)
+		}
+		// viper读取配置时map的key会被转为小写
+		p.rates[NormalizeCurrency(currency)] = decimal.NewFromFloat(rate)
+	}
+
+	return p, nil
+}
+
+func (p *LocalProvider) Name() string {
+	return ProviderLocal
+}
+
+func (p *LocalProvider) GetRates(ctx context.Context) (*Rates, error) {
+	rates := make(map[string]decimal.Decimal, len(p.rates))
+	for currency, rate := range p.rates {
+		rates[currency] = rate
+	}
+
+	return &Rates{Base: BaseCurrency, Rates: rates, AsOf: p.asOf}, nil
+}
@@ -0,0 +1,63 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+)
+
+const (
+	ProviderHTTP  = "http"  // 汇率接口，兼容open.er-api.com的返回格式
+	ProviderLocal = "local" // 配置中的固定汇率，用于本地开发及测试
+
+	// BaseCurrency 平台所有价格、市值均以美元计价
+	BaseCurrency = "USD"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Rates 1单位基准货币可兑换的各币种数量
+type Rates struct {
+	Base  string
+	Rates map[string]decimal.Decimal
+	AsOf  int64 // 汇率更新时间（毫秒）
+}
+
+// RateProvider 汇率数据源，屏蔽具体的数据供应商
+type RateProvider interface {
+	// Name 数据源名称
+	Name() string
+	// GetRates 获取以BaseCurrency为基准的最新汇率
+	GetRates(ctx context.Context) (*Rates, error)
+}
+
+// New 根据配置创建汇率数据源，未配置时默认使用http
+func New(c *config.FX) (RateProvider, error) {
+	if c == nil {
+		c = &config.FX{}
+	}
+
+	timeout := defaultTimeout
+	if c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+
+	switch c.Provider {
+	case "", ProviderHTTP:
+		return NewHTTPProvider(c.BaseURL, timeout), nil
+	case ProviderLocal:
+		return NewLocalProvider(c.Rates)
+	default:
+		return nil, errors.Errorf("unsupported fx provider: %s", c.Provider)
+	}
+}
+
+// NormalizeCurrency 币种代码统一为大写
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
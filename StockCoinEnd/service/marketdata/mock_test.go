@@ -0,0 +1,52 @@
+package marketdata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+)
+
+func TestMockProviderDeterministic(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	first, err := NewMockProvider().GetDailyAggregates(ctx, "AAPL", from, to)
+	if err != nil {
+		t.Fatalf("GetDailyAggregates: %v", err)
+	}
+	second, _ := NewMockProvider().GetDailyAggregates(ctx, "AAPL", from, to)
+	if len(first) == 0 || !reflect.DeepEqual(first, second) {
+		t.Fatalf("mock bars are not deterministic: %d vs %d bars", len(first), len(second))
+	}
+
+	// 子区间返回的K线与完整区间中对应日期的K线一致
+	sub, _ := NewMockProvider().GetDailyAggregates(ctx, "AAPL", time.UnixMilli(first[3].Timestamp), time.UnixMilli(first[5].Timestamp))
+	if !reflect.DeepEqual(sub, first[3:6]) {
+		t.Errorf("sub range bars = %+v, want %+v", sub, first[3:6])
+	}
+
+	other, _ := NewMockProvider().GetDailyAggregates(ctx, "TSLA", from, to)
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("different tickers should produce different bars")
+	}
+
+	for _, bar := range first {
+		day := time.UnixMilli(bar.Timestamp).In(timeUtil.NewYork())
+		if !timeUtil.IsNYSETradingDay(day) || day.Hour() != 0 {
+			t.Errorf("bar at %s is not a trading day at New York midnight", day)
+		}
+		if bar.Low > bar.Open || bar.Low > bar.Close || bar.High < bar.Open || bar.High < bar.Close {
+			t.Errorf("bar %+v has inconsistent OHLC", bar)
+		}
+	}
+
+	d1, _ := NewMockProvider().GetTickerDetails(ctx, "AAPL")
+	d2, _ := NewMockProvider().GetTickerDetails(ctx, "AAPL")
+	if !reflect.DeepEqual(d1, d2) || d1.Ticker != "AAPL" {
+		t.Errorf("ticker details are not deterministic: %+v vs %+v", d1, d2)
+	}
+}
@@ -0,0 +1,240 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPolygonBaseURL = "https://api.polygon.io"
+	polygonMaxRetries     = 3
+	polygonRetryBackoff   = time.Second // 首次重试前的等待时间，之后每次翻倍
+	polygonReferenceLimit = "1000"      // 单只股票的公司行为数量很少，一页即可取完
+)
+
+// AggregatesResponse polygon日线聚合接口返回结构
+type AggregatesResponse struct {
+	Ticker       string `json:"ticker"`
+	QueryCount   int    `json:"queryCount"`
+	ResultsCount int    `json:"resultsCount"`
+	Adjusted     bool   `json:"adjusted"`
+	Results      []Bar  `json:"results"`
+}
+
+// SplitsResponse polygon拆股接口返回结构
+type SplitsResponse struct {
+	Results []struct {
+		Ticker        string  `json:"ticker"`
+		ExecutionDate string  `json:"execution_date"`
+		SplitFrom     float64 `json:"split_from"`
+		SplitTo       float64 `json:"split_to"`
+	} `json:"results"`
+	Status string `json:"status"`
+}
+
+// DividendsResponse polygon分红接口返回结构
+type DividendsResponse struct {
+	Results []struct {
+		Ticker         string  `json:"ticker"`
+		ExDividendDate string  `json:"ex_dividend_date"`
+		PayDate        string  `json:"pay_date"`
+		CashAmount     float64 `json:"cash_amount"`
+		Currency       string  `json:"currency"`
+	} `json:"results"`
+	Status string `json:"status"`
+}
+
+// TickerResponse polygon股票参考信息接口返回结构
+type TickerResponse struct {
+	RequestID string        `json:"request_id"`
+	Results   TickerDetails `json:"results"`
+	Status    string        `json:"status"`
+}
+
+// PolygonProvider 基于polygon.io的行情数据源
+type PolygonProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKeys []string
+	backoff time.Duration
+
+	mu     sync.Mutex
+	keyIdx int
+}
+
+func NewPolygonProvider(baseURL string, apiKeys []string, timeout time.Duration) (*PolygonProvider, error) {
+	if baseURL == "" {
+		baseURL = defaultPolygonBaseURL
+	}
+	if len(apiKeys) == 0 {
+		return nil, errors.New("api_keys is required for polygon provider, set CNFT_MARKET_DATA_API_KEYS")
+	}
+
+	return &PolygonProvider{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKeys: apiKeys,
+		backoff: polygonRetryBackoff,
+	}, nil
+}
+
+func (p *PolygonProvider) Name() string {
+	return ProviderPolygon
+}
+
+func (p *PolygonProvider) GetDailyAggregates(ctx context.Context, ticker string, from, to time.Time) ([]Bar, error) {
+	path := fmt.Sprintf("/v2/aggs/ticker/%s/range/1/day/%s/%s", ticker, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	// 取未复权数据，拆股复权统一按公司行为表处理，避免历史K线与新数据口径不一致
+	params := url.Values{}
+	params.Set("adjusted", "false")
+	params.Set("sort", "asc")
+
+	var result AggregatesResponse
+	if err := p.get(ctx, path, params, &result); err != nil {
+		return nil, errors.Wrapf(err, "failed on get %s aggregates", ticker)
+	}
+
+	return result.Results, nil
+}
+
+func (p *PolygonProvider) GetTickerDetails(ctx context.Context, ticker string) (*TickerDetails, error) {
+	var result TickerResponse
+	if err := p.get(ctx, fmt.Sprintf("/v3/reference/tickers/%s", ticker), nil, &result); err != nil {
+		return nil, errors.Wrapf(err, "failed on get %s ticker details", ticker)
+	}
+
+	return &result.Results, nil
+}
+
+func (p *PolygonProvider) GetCorporateActions(ctx context.Context, ticker string, from, to time.Time) ([]CorporateAction, error) {
+	params := url.Values{}
+	params.Set("ticker", ticker)
+	params.Set("execution_date.gte", from.Format(DateLayout))
+	params.Set("execution_date.lte", to.Format(DateLayout))
+	params.Set("limit", polygonReferenceLimit)
+	var splits SplitsResponse
+	if err := p.get(ctx, "/v3/reference/splits", params, &splits); err != nil {
+		return nil, errors.Wrapf(err, "failed on get %s splits", ticker)
+	}
+
+	params = url.Values{}
+	params.Set("ticker", ticker)
+	params.Set("ex_dividend_date.gte", from.Format(DateLayout))
+	params.Set("ex_dividend_date.lte", to.Format(DateLayout))
+	params.Set("limit", polygonReferenceLimit)
+	var dividends DividendsResponse
+	if err := p.get(ctx, "/v3/reference/dividends", params, &dividends); err != nil {
+		return nil, errors.Wrapf(err, "failed on get %s dividends", ticker)
+	}
+
+	actions := make([]CorporateAction, 0, len(splits.Results)+len(dividends.Results))
+	for _, split := range splits.Results {
+		actions = append(actions, CorporateAction{
+			Ticker:    ticker,
+			Type:      ActionTypeSplit,
+			ExDate:    split.ExecutionDate,
+			SplitFrom: split.SplitFrom,
+			SplitTo:   split.SplitTo,
+		})
+	}
+	for _, dividend := range dividends.Results {
+		actions = append(actions, CorporateAction{
+			Ticker:     ticker,
+			Type:       ActionTypeDividend,
+			ExDate:     dividend.ExDividendDate,
+			PayDate:    dividend.PayDate,
+			CashAmount: dividend.CashAmount,
+			Currency:   dividend.Currency,
+		})
+	}
+
+	return filterActions(actions, from, to), nil
+}
+
+// get 请求polygon接口，触发速率限制时切换apiKey重试；网络错误及5xx按指数退避重试，其它4xx直接返回
+func (p *PolygonProvider) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	apiKey := p.currentApiKey()
+	var lastErr error
+	for i := 0; i < polygonMaxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.backoff << (i - 1)):
+			}
+		}
+
+		query := url.Values{}
+		for key, values := range params {
+			query[key] = values
+		}
+		query.Set("apiKey", apiKey)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return errors.Wrap(err, "failed on create request")
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			lastErr = errors.Wrap(err, "failed on do request")
+			log.Printf("%s 请求执行失败 (尝试%d/%d): %v", path, i+1, polygonMaxRetries, err)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = errors.Wrap(err, "failed on read response")
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			log.Printf("%s 触发了 API 速率限制 (尝试%d/%d)", path, i+1, polygonMaxRetries)
+			lastErr = errors.Errorf("API错误: %s", resp.Status)
+			apiKey = p.rotateApiKey(apiKey)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = errors.Errorf("API错误: %s - %s", resp.Status, string(body))
+			// 鉴权失败、资源不存在等客户端错误重试也不会成功
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+				return lastErr
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(body, out); err != nil {
+			return errors.Wrap(err, "JSON解析失败")
+		}
+		return nil
+	}
+
+	return lastErr
+}
+
+func (p *PolygonProvider) currentApiKey() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.apiKeys[p.keyIdx]
+}
+
+// rotateApiKey 切换到下一个apiKey；若其它请求已切换过（当前key不是used）则直接使用当前key
+func (p *PolygonProvider) rotateApiKey(used string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.apiKeys[p.keyIdx] == used {
+		p.keyIdx = (p.keyIdx + 1) % len(p.apiKeys)
+	}
+	return p.apiKeys[p.keyIdx]
+}
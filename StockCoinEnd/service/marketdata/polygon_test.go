@@ -0,0 +1,90 @@
+package marketdata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testPolygonServer 依次返回statuses中的状态码，记录每次请求使用的apiKey
+func testPolygonServer(t *testing.T, statuses ...int) (*PolygonProvider, *[]string) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("apiKey"))
+		status := statuses[len(keys)-1]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"ticker":"AAPL","results":[{"t":1717387200000,"c":194.03}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := NewPolygonProvider(server.URL, []string{"k1", "k2"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.backoff = time.Millisecond
+	return p, &keys
+}
+
+func TestPolygonGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		wantKeys []string
+	}{
+		{"ok", []int{http.StatusOK}, false, []string{"k1"}},
+		{"rate limited rotates key", []int{http.StatusTooManyRequests, http.StatusOK}, false, []string{"k1", "k2"}},
+		{"server error retried", []int{http.StatusBadGateway, http.StatusOK}, false, []string{"k1", "k1"}},
+		{"server error exhausted", []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}, true, []string{"k1", "k1", "k1"}},
+		{"unauthorized not retried", []int{http.StatusUnauthorized}, true, []string{"k1"}},
+		{"forbidden not retried", []int{http.StatusForbidden}, true, []string{"k1"}},
+		{"not found not retried", []int{http.StatusNotFound}, true, []string{"k1"}},
+	}
+	for _, tt := range tests {
+		p, keys := testPolygonServer(t, tt.statuses...)
+		var out AggregatesResponse
+		err := p.get(context.Background(), "/v2/aggs", nil, &out)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if len(*keys) != len(tt.wantKeys) {
+			t.Errorf("%s: %d requests with keys %q, want %q", tt.name, len(*keys), *keys, tt.wantKeys)
+			continue
+		}
+		for i := range tt.wantKeys {
+			if (*keys)[i] != tt.wantKeys[i] {
+				t.Errorf("%s: keys = %q, want %q", tt.name, *keys, tt.wantKeys)
+				break
+			}
+		}
+		if !tt.wantErr && (len(out.Results) != 1 || out.Results[0].Close != 194.03) {
+			t.Errorf("%s: results = %+v", tt.name, out.Results)
+		}
+	}
+}
+
+func TestRotateApiKey(t *testing.T) {
+	// 重复的key也不会导致轮换卡住
+	p, err := NewPolygonProvider("", []string{"k", "k"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.rotateApiKey("k"); got != "k" || p.keyIdx != 1 {
+		t.Errorf("rotateApiKey = %q at %d, want k at 1", got, p.keyIdx)
+	}
+
+	p, _ = NewPolygonProvider("", []string{"a", "b", "c"}, time.Second)
+	for _, want := range []string{"b", "c", "a"} {
+		if got := p.rotateApiKey(p.currentApiKey()); got != want {
+			t.Errorf("rotateApiKey = %q, want %q", got, want)
+		}
+	}
+	// 其它请求已经切换过时不再切换
+	if got := p.rotateApiKey("c"); got != "a" {
+		t.Errorf("rotateApiKey(stale) = %q, want a", got)
+	}
+}
@@ -0,0 +1,121 @@
+package marketdata
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+)
+
+const (
+	ProviderPolygon = "polygon" // polygon.io 行情接口
+	ProviderReplay  = "replay"  // 本地CSV/JSON回放
+	ProviderMock    = "mock"    // 确定性模拟数据
+
+	defaultTimeout = 10 * time.Second
+
+	ActionTypeSplit    = "split"    // 拆股/合股
+	ActionTypeDividend = "dividend" // 现金分红
+
+	DateLayout = "2006-01-02"
+)
+
+// Bar 单根K线（日线聚合）
+type Bar struct {
+	Timestamp int64   `json:"t"` // 开盘时间（毫秒）
+	Open      float64 `json:"o"`
+	High      float64 `json:"h"`
+	Low       float64 `json:"l"`
+	Close     float64 `json:"c"`
+	Volume    float64 `json:"v"`
+	ItemCount int     `json:"n"` // 成交笔数
+}
+
+// TickerDetails 股票基础参考信息
+type TickerDetails struct {
+	Ticker            string  `json:"ticker"`
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	MarketCap         float64 `json:"market_cap"`
+	SharesOutstanding int64   `json:"share_class_shares_outstanding"`
+	CurrencyName      string  `json:"currency_name"`
+}
+
+// CorporateAction 公司行为（拆股、分红），日期均为纽约时间的日期(2006-01-02)
+type CorporateAction struct {
+	Ticker     string  `json:"ticker"`
+	Type       string  `json:"type"`        // split / dividend
+	ExDate     string  `json:"ex_date"`     // 除权除息日，拆股为执行日
+	PayDate    string  `json:"pay_date"`    // 派息日，仅分红
+	SplitFrom  float64 `json:"split_from"`  // 拆股前股数，如1拆4为1
+	SplitTo    float64 `json:"split_to"`    // 拆股后股数，如1拆4为4
+	CashAmount float64 `json:"cash_amount"` // 每股分红金额
+	Currency   string  `json:"currency"`
+}
+
+// MarketDataProvider 行情数据源，屏蔽具体的数据供应商
+type MarketDataProvider interface {
+	// Name 数据源名称
+	Name() string
+	// GetDailyAggregates 获取[from, to]区间内未复权的日线数据，按时间升序返回，拆股复权由调用方根据公司行为处理
+	GetDailyAggregates(ctx context.Context, ticker string, from, to time.Time) ([]Bar, error)
+	// GetTickerDetails 获取股票的名称、描述、市值等参考信息
+	GetTickerDetails(ctx context.Context, ticker string) (*TickerDetails, error)
+	// GetCorporateActions 获取除权除息日在[from, to]区间内的拆股及分红，包含已公告但尚未发生的
+	GetCorporateActions(ctx context.Context, ticker string, from, to time.Time) ([]CorporateAction, error)
+}
+
+// New 根据配置创建行情数据源，未配置时默认使用polygon
+func New(c *config.MarketData) (MarketDataProvider, error) {
+	if c == nil {
+		c = &config.MarketData{}
+	}
+
+	timeout := defaultTimeout
+	if c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+
+	switch c.Provider {
+	case "", ProviderPolygon:
+		return NewPolygonProvider(c.BaseURL, c.ApiKeys, timeout)
+	case ProviderReplay:
+		return NewReplayProvider(c.ReplayDir)
+	case ProviderMock:
+		return NewMockProvider(), nil
+	default:
+		return nil, errors.Errorf("unsupported market data provider: %s", c.Provider)
+	}
+}
+
+// filterBars 过滤出[from, to]区间内的K线
+func filterBars(bars []Bar, from, to time.Time) []Bar {
+	start, end := from.UnixMilli(), to.UnixMilli()
+	filtered := make([]Bar, 0, len(bars))
+	for _, bar := range bars {
+		if bar.Timestamp >= start && bar.Timestamp <= end {
+			filtered = append(filtered, bar)
+		}
+	}
+
+	return filtered
+}
+
+// filterActions 过滤出除权除息日在[from, to]区间内的公司行为，并按日期升序排列
+func filterActions(actions []CorporateAction, from, to time.Time) []CorporateAction {
+	start, end := from.Format(DateLayout), to.Format(DateLayout)
+	filtered := make([]CorporateAction, 0, len(actions))
+	for _, action := range actions {
+		if action.ExDate >= start && action.ExDate <= end {
+			filtered = append(filtered, action)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].ExDate < filtered[j].ExDate
+	})
+
+	return filtered
+}
@@ -0,0 +1,160 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReplayProvider 从本地目录回放行情数据，用于离线/CI环境
+// 目录结构:
+//
+//	<dir>/<TICKER>.csv          日线数据，表头为 t,o,h,l,c,v,n（t为毫秒时间戳）
+//	<dir>/<TICKER>.json         日线数据，格式与polygon聚合接口返回一致
+//	<dir>/<TICKER>.ticker.json  参考信息，格式与polygon tickers接口返回一致
+//	<dir>/<TICKER>.actions.json 公司行为，CorporateAction数组，文件不存在时视为没有公司行为
+type ReplayProvider struct {
+	dir string
+}
+
+func NewReplayProvider(dir string) (*ReplayProvider, error) {
+	if dir == "" {
+		return nil, errors.New("replay_dir is required for replay provider")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on stat replay dir")
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("replay dir %s is not a directory", dir)
+	}
+
+	return &ReplayProvider{dir: dir}, nil
+}
+
+func (p *ReplayProvider) Name() string {
+	return ProviderReplay
+}
+
+func (p *ReplayProvider) GetDailyAggregates(ctx context.Context, ticker string, from, to time.Time) ([]Bar, error) {
+	var bars []Bar
+	var err error
+	csvPath := filepath.Join(p.dir, ticker+".csv")
+	if _, statErr := os.Stat(csvPath); statErr == nil {
+		bars, err = readCSVBars(csvPath)
+	} else {
+		bars, err = readJSONBars(filepath.Join(p.dir, ticker+".json"))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on replay %s aggregates", ticker)
+	}
+
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Timestamp < bars[j].Timestamp
+	})
+	return filterBars(bars, from, to), nil
+}
+
+func (p *ReplayProvider) GetTickerDetails(ctx context.Context, ticker string) (*TickerDetails, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, ticker+".ticker.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on replay %s ticker details", ticker)
+	}
+
+	var result TickerResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrapf(err, "failed on parse %s ticker details", ticker)
+	}
+	return &result.Results, nil
+}
+
+func (p *ReplayProvider) GetCorporateActions(ctx context.Context, ticker string, from, to time.Time) ([]CorporateAction, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, ticker+".actions.json"))
+	if os.IsNotExist(err) {
+		return []CorporateAction{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on replay %s corporate actions", ticker)
+	}
+
+	var actions []CorporateAction
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, errors.Wrapf(err, "failed on parse %s corporate actions", ticker)
+	}
+	for i := range actions {
+		actions[i].Ticker = ticker
+	}
+	return filterActions(actions, from, to), nil
+}
+
+func readJSONBars(path string) ([]Bar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result AggregatesResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrap(err, "failed on parse json bars")
+	}
+	return result.Results, nil
+}
+
+func readCSVBars(path string) ([]Bar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on read csv header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, name := range []string{"t", "o", "h", "l", "c", "v"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("csv column %s is missing", name)
+		}
+	}
+
+	var bars []Bar
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on read csv record")
+		}
+
+		var bar Bar
+		if bar.Timestamp, err = strconv.ParseInt(record[columns["t"]], 10, 64); err != nil {
+			return nil, errors.Wrapf(err, "invalid timestamp %s", record[columns["t"]])
+		}
+		for name, field := range map[string]*float64{"o": &bar.Open, "h": &bar.High, "l": &bar.Low, "c": &bar.Close, "v": &bar.Volume} {
+			if *field, err = strconv.ParseFloat(record[columns[name]], 64); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s value %s", name, record[columns[name]])
+			}
+		}
+		if idx, ok := columns["n"]; ok {
+			bar.ItemCount, _ = strconv.Atoi(record[idx])
+		}
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
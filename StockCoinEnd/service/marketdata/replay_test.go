@@ -0,0 +1,96 @@
+package marketdata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 与config.toml中replay_dir使用同一份回放数据
+const testReplayDir = "../../testdata/marketdata"
+
+func TestReplayProviderCSV(t *testing.T) {
+	p, err := NewReplayProvider(testReplayDir)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+
+	// 文件中的记录未排序，返回时按时间升序并只保留区间内的K线
+	from, to := time.UnixMilli(1717473600000), time.UnixMilli(1717646400000)
+	bars, err := p.GetDailyAggregates(context.Background(), "AAPL", from, to)
+	if err != nil {
+		t.Fatalf("GetDailyAggregates: %v", err)
+	}
+	want := []Bar{
+		{Timestamp: 1717473600000, Open: 194.64, High: 195.32, Low: 193.03, Close: 194.35, Volume: 47471445, ItemCount: 536110},
+		{Timestamp: 1717560000000, Open: 195.40, High: 196.90, Low: 194.87, Close: 195.87, Volume: 54156785, ItemCount: 611843},
+		{Timestamp: 1717646400000, Open: 195.69, High: 196.50, Low: 194.17, Close: 194.48, Volume: 41181753, ItemCount: 498471},
+	}
+	if !reflect.DeepEqual(bars, want) {
+		t.Errorf("bars = %+v, want %+v", bars, want)
+	}
+
+	details, err := p.GetTickerDetails(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("GetTickerDetails: %v", err)
+	}
+	if details.Name != "Apple Inc." || details.SharesOutstanding != 15334082000 {
+		t.Errorf("details = %+v", details)
+	}
+}
+
+func TestReplayProviderJSON(t *testing.T) {
+	p, err := NewReplayProvider(testReplayDir)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+
+	bars, err := p.GetDailyAggregates(context.Background(), "TSLA", time.UnixMilli(0), time.UnixMilli(1717560000000))
+	if err != nil {
+		t.Fatalf("GetDailyAggregates: %v", err)
+	}
+	if len(bars) != 3 {
+		t.Fatalf("got %d bars, want 3", len(bars))
+	}
+	if bars[0].Timestamp != 1717387200000 || bars[2].Close != 175.00 || bars[1].ItemCount != 812504 {
+		t.Errorf("bars = %+v", bars)
+	}
+
+	// 没有公司行为文件时返回空列表
+	actions, err := p.GetCorporateActions(context.Background(), "TSLA", time.UnixMilli(0), time.Now())
+	if err != nil || len(actions) != 0 {
+		t.Errorf("GetCorporateActions(TSLA) = %+v, %v, want empty", actions, err)
+	}
+}
+
+func TestReplayProviderCorporateActions(t *testing.T) {
+	p, err := NewReplayProvider(testReplayDir)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	actions, err := p.GetCorporateActions(context.Background(), "AAPL", from, to)
+	if err != nil {
+		t.Fatalf("GetCorporateActions: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Type != ActionTypeDividend || actions[0].Ticker != "AAPL" || actions[0].CashAmount != 0.25 {
+		t.Errorf("actions = %+v, want the 2024 dividend only", actions)
+	}
+}
+
+func TestReplayProviderMissing(t *testing.T) {
+	if _, err := NewReplayProvider(testReplayDir + "/missing"); err == nil {
+		t.Errorf("NewReplayProvider(missing dir): expected an error")
+	}
+
+	p, err := NewReplayProvider(testReplayDir)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	if _, err := p.GetDailyAggregates(context.Background(), "MSFT", time.UnixMilli(0), time.Now()); err == nil {
+		t.Errorf("GetDailyAggregates(MSFT): expected an error for a ticker without replay data")
+	}
+}
@@ -0,0 +1,195 @@
+package oracleupdate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+	"github.com/locey/CryptoStock/StockCoinEnd/contract"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// OracleUpdate 单个预言机的更新数据
+type OracleUpdate struct {
+	OracleType contract.OracleType
+	Address    common.Address
+	Data       [][]byte
+	Fee        *big.Int // 该预言机需要的更新费用（wei）
+}
+
+// Result 调用getAggregatedPrice及买卖预估所需的全部预言机更新数据
+type Result struct {
+	Symbol  string
+	Oracles []OracleUpdate // 与PriceAggregator的预言机列表一一对应
+	Fee     *big.Int       // 调用时需要附带的msg.value（wei）
+}
+
+// UpdateData 按预言机顺序排列的updateData，可直接作为合约的bytes[][]参数
+func (r *Result) UpdateData() [][][]byte {
+	data := make([][][]byte, len(r.Oracles))
+	for i, oracle := range r.Oracles {
+		data[i] = oracle.Data
+	}
+	return data
+}
+
+// oracleRegistry 提供PriceAggregator中注册的预言机列表
+type oracleRegistry interface {
+	GetOracles(ctx context.Context) ([]contract.OracleInfo, error)
+}
+
+// pythFeeds 提供Pyth价格源的feed id及更新费用
+type pythFeeds interface {
+	FeedID(ctx context.Context, feed common.Address, symbol string) (common.Hash, error)
+	UpdateFee(ctx context.Context, feed common.Address, updateData [][]byte) (*big.Int, error)
+}
+
+// Builder 读取PriceAggregator注册的预言机，并从Pyth Hermes、RedStone网关获取对应的更新数据
+type Builder struct {
+	aggregator oracleRegistry
+	pythFeed   pythFeeds
+	client     *http.Client
+
+	hermesURL             string
+	redstoneURL           string
+	redstoneDataServiceID string
+	redstoneUniqueSigners int
+}
+
+// New 创建预言机更新数据构造器，接口地址未配置时使用官方地址
+func New(c *config.OracleUpdate, aggregator *contract.PriceAggregatorContract, pythFeed *contract.PythPriceFeedContract) *Builder {
+	if c == nil {
+		c = &config.OracleUpdate{}
+	}
+
+	timeout := defaultTimeout
+	if c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+	b := &Builder{
+		aggregator:            aggregator,
+		pythFeed:              pythFeed,
+		client:                &http.Client{Timeout: timeout},
+		hermesURL:             strings.TrimRight(c.HermesURL, "/"),
+		redstoneURL:           strings.TrimRight(c.RedstoneGatewayURL, "/"),
+		redstoneDataServiceID: c.RedstoneDataServiceID,
+		redstoneUniqueSigners: c.RedstoneUniqueSigners,
+	}
+	if b.hermesURL == "" {
+		b.hermesURL = defaultHermesURL
+	}
+	if b.redstoneURL == "" {
+		b.redstoneURL = defaultRedstoneGatewayURL
+	}
+	if b.redstoneDataServiceID == "" {
+		b.redstoneDataServiceID = defaultRedstoneDataServiceID
+	}
+	if b.redstoneUniqueSigners <= 0 {
+		b.redstoneUniqueSigners = defaultRedstoneUniqueSigners
+	}
+	return b
+}
+
+// Build 构造symbol的预言机更新数据及需要支付的费用
+func (b *Builder) Build(ctx context.Context, symbol string) (*Result, error) {
+	oracles, err := b.aggregator.GetOracles(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on get oracles")
+	}
+	if len(oracles) == 0 {
+		return nil, errors.New("no oracle registered in price aggregator")
+	}
+
+	res := &Result{Symbol: symbol, Oracles: make([]OracleUpdate, 0, len(oracles))}
+	maxFee := big.NewInt(0)
+	for _, oracle := range oracles {
+		update := OracleUpdate{
+			OracleType: oracle.OracleType,
+			Address:    oracle.OracleAddress,
+			Data:       [][]byte{},
+			Fee:        big.NewInt(0),
+		}
+
+		switch oracle.OracleType {
+		case contract.OracleTypePyth:
+			if err := b.buildPyth(ctx, symbol, &update); err != nil {
+				return nil, err
+			}
+		case contract.OracleTypeRedStone:
+			payload, err := b.fetchRedstonePayload(ctx, symbol)
+			if err != nil {
+				return nil, err
+			}
+			update.Data = [][]byte{payload}
+		default:
+			return nil, errors.Errorf("unsupported oracle type %d at %s", oracle.OracleType, oracle.OracleAddress.Hex())
+		}
+
+		if update.Fee.Cmp(maxFee) > 0 {
+			maxFee = update.Fee
+		}
+		res.Oracles = append(res.Oracles, update)
+	}
+
+	// PriceAggregator将msg.value平均分给每个预言机，需保证每份都够支付费用最高的预言机
+	res.Fee = new(big.Int).Mul(maxFee, big.NewInt(int64(len(oracles))))
+	return res, nil
+}
+
+func (b *Builder) buildPyth(ctx context.Context, symbol string, update *OracleUpdate) error {
+	feedID, err := b.pythFeed.FeedID(ctx, update.Address, symbol)
+	if err != nil {
+		return errors.Wrapf(err, "failed on get pyth feed id of %s", symbol)
+	}
+	if feedID == (common.Hash{}) {
+		return errors.Errorf("pyth feed id of %s is not configured", symbol)
+	}
+
+	data, err := b.fetchPythUpdateData(ctx, feedID)
+	if err != nil {
+		return err
+	}
+	fee, err := b.pythFeed.UpdateFee(ctx, update.Address, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed on get pyth update fee of %s", symbol)
+	}
+
+	update.Data = data
+	update.Fee = fee
+	return nil
+}
+
+func (b *Builder) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed on create request")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed on do request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed on read response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("API错误: %s - %s", resp.Status, string(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return errors.Wrap(err, "JSON解析失败")
+	}
+	return nil
+}
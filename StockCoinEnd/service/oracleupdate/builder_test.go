@@ -0,0 +1,157 @@
+package oracleupdate
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+	"github.com/locey/CryptoStock/StockCoinEnd/contract"
+)
+
+const testSignature = "AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4/QEE="
+
+type fakeRegistry []contract.OracleInfo
+
+func (r fakeRegistry) GetOracles(ctx context.Context) ([]contract.OracleInfo, error) {
+	return r, nil
+}
+
+// fakePythFeeds 每个Pyth价格源按地址返回固定费用
+type fakePythFeeds map[common.Address]int64
+
+func (f fakePythFeeds) FeedID(ctx context.Context, feed common.Address, symbol string) (common.Hash, error) {
+	return common.HexToHash("0x49f6b65cb1de6b10eaf75e7c03ca029c306d0357e91b5311b175084a5ad55688"), nil
+}
+
+func (f fakePythFeeds) UpdateFee(ctx context.Context, feed common.Address, updateData [][]byte) (*big.Int, error) {
+	return big.NewInt(f[feed]), nil
+}
+
+func TestBuild(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/updates/price/latest", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"binary":{"encoding":"hex","data":["0x504e4155"]}}`))
+	})
+	mux.HandleFunc("/data-packages/latest/test-service", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"AAPL": []map[string]interface{}{{
+				"timestampMilliseconds": 1700000000000,
+				"signature":             testSignature,
+				"dataPoints":            []map[string]interface{}{{"dataFeedId": "AAPL", "value": 123.45}},
+			}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	redstone := common.HexToAddress("0x01")
+	pythA := common.HexToAddress("0x02")
+	pythB := common.HexToAddress("0x03")
+	b := New(&config.OracleUpdate{
+		HermesURL:             server.URL,
+		RedstoneGatewayURL:    server.URL + "/",
+		RedstoneDataServiceID: "test-service",
+	}, nil, nil)
+	b.aggregator = fakeRegistry{
+		{OracleType: contract.OracleTypeRedStone, OracleAddress: redstone},
+		{OracleType: contract.OracleTypePyth, OracleAddress: pythA},
+		{OracleType: contract.OracleTypePyth, OracleAddress: pythB},
+	}
+	b.pythFeed = fakePythFeeds{pythA: 3, pythB: 7}
+
+	res, err := b.Build(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	wantOracles := []common.Address{redstone, pythA, pythB}
+	if len(res.Oracles) != len(wantOracles) {
+		t.Fatalf("got %d oracles, want %d", len(res.Oracles), len(wantOracles))
+	}
+	for i, want := range wantOracles {
+		if res.Oracles[i].Address != want {
+			t.Errorf("oracle %d = %s, want %s", i, res.Oracles[i].Address.Hex(), want.Hex())
+		}
+	}
+	// 聚合器把msg.value平均分给3个预言机，每份需覆盖最高的7 wei
+	if res.Fee.Cmp(big.NewInt(21)) != 0 {
+		t.Errorf("fee = %s, want 21", res.Fee)
+	}
+
+	data := res.UpdateData()
+	if len(data[1]) != 1 || !bytes.Equal(data[1][0], []byte("PNAU")) {
+		t.Errorf("pyth update data = %x, want %x", data[1], "PNAU")
+	}
+	if len(data[0]) != 1 || !bytes.HasSuffix(data[0][0], redstoneMarker) {
+		t.Errorf("redstone payload = %x, want a payload ending with the marker", data[0])
+	}
+}
+
+func TestEncodeRedstonePayload(t *testing.T) {
+	var packages []redstoneDataPackage
+	if err := json.Unmarshal([]byte(`[{
+		"timestampMilliseconds": 1700000000000,
+		"signature": "`+testSignature+`",
+		"dataPoints": [{"dataFeedId": "AAPL", "value": 123.45}]
+	}]`), &packages); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := encodeRedstonePayload(packages)
+	if err != nil {
+		t.Fatalf("encodeRedstonePayload: %v", err)
+	}
+
+	want := "" +
+		// dataFeedId "AAPL"右侧补0到32字节
+		"4141504c00000000000000000000000000000000000000000000000000000000" +
+		// 123.45按8位精度放大为12345000000
+		"00000000000000000000000000000000000000000000000000000002dfd1c040" +
+		"018bcfe56800" + // timestamp
+		"00000020" + // value长度
+		"000001" + // 数据点数量
+		"0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20" +
+		"2122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f4041" +
+		"0001" + // 数据包数量
+		"000000" + // unsigned metadata长度
+		"000002ed57011e0000"
+	if got := hex.EncodeToString(payload); got != want {
+		t.Errorf("payload =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRedstoneValueBytes(t *testing.T) {
+	six := int32(6)
+	tests := []struct {
+		name     string
+		raw      string
+		decimals *int32
+		want     string
+	}{
+		{"default decimals", `123.45`, nil, "00000000000000000000000000000000000000000000000000000002dfd1c040"},
+		{"explicit decimals", `1.5`, &six, "000000000000000000000000000000000000000000000000000000000016e360"},
+		{"base64 bytes", `"AQID"`, nil, "0000000000000000000000000000000000000000000000000000000000010203"},
+	}
+	for _, tt := range tests {
+		got, err := redstoneValueBytes(json.RawMessage(tt.raw), tt.decimals)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s: value = %x, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := redstoneValueBytes(json.RawMessage(`-1`), nil); err == nil {
+		t.Errorf("negative value: expected an error")
+	}
+}
@@ -0,0 +1,46 @@
+package oracleupdate
+
+import (
+	"context"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const defaultHermesURL = "https://hermes.pyth.network"
+
+// hermesLatestResponse Hermes /v2/updates/price/latest 接口返回结构（只取更新数据部分）
+type hermesLatestResponse struct {
+	Binary struct {
+		Encoding string   `json:"encoding"`
+		Data     []string `json:"data"`
+	} `json:"binary"`
+}
+
+// fetchPythUpdateData 从Hermes获取feedID最新的价格更新数据（VAA），可直接作为updatePriceFeeds的参数
+func (b *Builder) fetchPythUpdateData(ctx context.Context, feedID common.Hash) ([][]byte, error) {
+	params := url.Values{}
+	params.Set("ids[]", feedID.Hex())
+	params.Set("encoding", "hex")
+
+	var result hermesLatestResponse
+	if err := b.getJSON(ctx, b.hermesURL+"/v2/updates/price/latest?"+params.Encode(), &result); err != nil {
+		return nil, errors.Wrapf(err, "failed on get pyth update data of %s", feedID.Hex())
+	}
+	if len(result.Binary.Data) == 0 {
+		return nil, errors.Errorf("empty pyth update data of %s", feedID.Hex())
+	}
+
+	data := make([][]byte, 0, len(result.Binary.Data))
+	for _, item := range result.Binary.Data {
+		raw, err := hex.DecodeString(strings.TrimPrefix(item, "0x"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on decode pyth update data")
+		}
+		data = append(data, raw)
+	}
+	return data, nil
+}
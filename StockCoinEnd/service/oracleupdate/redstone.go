@@ -0,0 +1,139 @@
+package oracleupdate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultRedstoneGatewayURL    = "https://oracle-gateway-1.a.redstone.finance"
+	defaultRedstoneDataServiceID = "redstone-main-demo"
+	defaultRedstoneUniqueSigners = 1
+
+	redstoneDefaultDecimals = 8  // 数值型数据点默认按8位精度编码
+	redstoneValueByteSize   = 32 // 数据点取值按uint256编码
+	redstoneSignatureSize   = 65
+)
+
+// redstoneMarker RedStone payload末尾的固定标识
+var redstoneMarker = []byte{0x00, 0x00, 0x02, 0xed, 0x57, 0x01, 0x1e, 0x00, 0x00}
+
+// redstoneDataPackage RedStone网关返回的已签名数据包
+type redstoneDataPackage struct {
+	TimestampMilliseconds int64  `json:"timestampMilliseconds"`
+	Signature             string `json:"signature"` // base64编码的r||s||v
+	SignerAddress         string `json:"signerAddress"`
+	DataPoints            []struct {
+		DataFeedID string          `json:"dataFeedId"`
+		Value      json.RawMessage `json:"value"`
+		Decimals   *int32          `json:"decimals"`
+	} `json:"dataPoints"`
+}
+
+// fetchRedstonePayload 从RedStone网关获取symbol最新的签名数据包，并按RedStone协议编码为合约可验证的payload
+func (b *Builder) fetchRedstonePayload(ctx context.Context, symbol string) ([]byte, error) {
+	var result map[string][]redstoneDataPackage
+	path := fmt.Sprintf("%s/data-packages/latest/%s", b.redstoneURL, url.PathEscape(b.redstoneDataServiceID))
+	if err := b.getJSON(ctx, path, &result); err != nil {
+		return nil, errors.Wrapf(err, "failed on get redstone data packages of %s", symbol)
+	}
+
+	packages := result[symbol]
+	if len(packages) < b.redstoneUniqueSigners {
+		return nil, errors.Errorf("redstone data packages of %s not enough: got %d, need %d", symbol, len(packages), b.redstoneUniqueSigners)
+	}
+
+	payload, err := encodeRedstonePayload(packages[:b.redstoneUniqueSigners])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on encode redstone payload of %s", symbol)
+	}
+	return payload, nil
+}
+
+// encodeRedstonePayload 编码格式：
+// [数据包...][数据包数量 2字节][unsigned metadata][metadata长度 3字节][marker 9字节]
+// 每个数据包：[dataFeedId 32字节 + value 32字节]... [timestamp 6字节][value长度 4字节][数据点数量 3字节][签名 65字节]
+func encodeRedstonePayload(packages []redstoneDataPackage) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, pkg := range packages {
+		for _, point := range pkg.DataPoints {
+			if len(point.DataFeedID) > 32 {
+				return nil, errors.Errorf("data feed id too long: %s", point.DataFeedID)
+			}
+			feedID := make([]byte, 32)
+			copy(feedID, point.DataFeedID)
+			buf.Write(feedID)
+
+			value, err := redstoneValueBytes(point.Value, point.Decimals)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value of %s", point.DataFeedID)
+			}
+			buf.Write(value)
+		}
+
+		buf.Write(uintBytes(uint64(pkg.TimestampMilliseconds), 6))
+		buf.Write(uintBytes(redstoneValueByteSize, 4))
+		buf.Write(uintBytes(uint64(len(pkg.DataPoints)), 3))
+
+		signature, err := base64.StdEncoding.DecodeString(pkg.Signature)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on decode signature")
+		}
+		if len(signature) != redstoneSignatureSize {
+			return nil, errors.Errorf("unexpected signature length: %d", len(signature))
+		}
+		buf.Write(signature)
+	}
+
+	buf.Write(uintBytes(uint64(len(packages)), 2))
+	// 不附带unsigned metadata
+	buf.Write(uintBytes(0, 3))
+	buf.Write(redstoneMarker)
+	return buf.Bytes(), nil
+}
+
+// redstoneValueBytes 数值型数据点按精度放大后编码为32字节，字符串型数据点为base64编码的原始字节
+func redstoneValueBytes(raw json.RawMessage, decimals *int32) ([]byte, error) {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > redstoneValueByteSize {
+			return nil, errors.Errorf("value too long: %d bytes", len(value))
+		}
+		padded := make([]byte, redstoneValueByteSize)
+		copy(padded[redstoneValueByteSize-len(value):], value)
+		return padded, nil
+	}
+
+	value, err := decimal.NewFromString(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	exp := int32(redstoneDefaultDecimals)
+	if decimals != nil {
+		exp = *decimals
+	}
+	scaled := value.Shift(exp).Round(0).BigInt()
+	if scaled.Sign() < 0 || scaled.BitLen() > redstoneValueByteSize*8 {
+		return nil, errors.Errorf("value out of range: %s", value)
+	}
+	return scaled.FillBytes(make([]byte, redstoneValueByteSize)), nil
+}
+
+// uintBytes 大端编码v，取低size字节
+func uintBytes(v uint64, size int) []byte {
+	full := make([]byte, 8)
+	binary.BigEndian.PutUint64(full, v)
+	return full[8-size:]
+}
@@ -0,0 +1,151 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/stores/xkv"
+)
+
+const (
+	// PriceChannel 行情更新的Redis频道，所有API副本订阅同一频道后推送给各自的连接
+	PriceChannel = "stock:price:updates"
+
+	subscriberBuffer = 64
+	resubscribeDelay = 5 * time.Second
+	publishTimeout   = 5 * time.Second
+)
+
+// PriceUpdate 单只股票的价格及摘要更新
+type PriceUpdate struct {
+	Ticker         string          `json:"ticker"`
+	Price          decimal.Decimal `json:"price"`
+	AsOf           int64           `json:"as_of"`
+	IsStale        bool            `json:"is_stale"`
+	AvgGain        decimal.Decimal `json:"avg_gain"`         // 7日平均涨幅
+	AvgGainPercent float64         `json:"avg_gain_percent"` // 7日平均涨幅百分比
+	AvgVolume      float64         `json:"avg_volume"`       // 7日平均成交量
+}
+
+// Subscriber 一个推送连接，只接收订阅的ticker的更新
+type Subscriber struct {
+	C       <-chan PriceUpdate
+	ch      chan PriceUpdate
+	tickers map[string]bool
+}
+
+// Hub 订阅Redis行情频道，将更新分发给本副本上的连接
+type Hub struct {
+	store       *xkv.Store
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+}
+
+func NewHub(store *xkv.Store) *Hub {
+	return &Hub{
+		store:       store,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Run 订阅行情频道并持续分发，订阅失败时定时重试，直到ctx结束
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		pubsub, err := h.store.Subscribe(ctx, PriceChannel)
+		if err != nil {
+			log.Printf("订阅行情频道失败: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(resubscribeDelay):
+				continue
+			}
+		}
+
+		// go-redis在连接断开时会自动重新订阅，channel只在Close后关闭
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				pubsub.Close()
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					pubsub.Close()
+					return
+				}
+				var updates []PriceUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &updates); err != nil {
+					log.Printf("解析行情更新失败: %v", err)
+					continue
+				}
+				h.dispatch(updates)
+			}
+		}
+	}
+}
+
+// Publish 将一轮行情更新发布到Redis，由各副本的Hub推送给连接
+func (h *Hub) Publish(updates []PriceUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(updates)
+	if err != nil {
+		return errors.Wrap(err, "failed on marshal price updates")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	_, err = h.store.Publish(ctx, PriceChannel, string(payload))
+	return err
+}
+
+// Subscribe 注册一个只接收tickers更新的连接，断开时需调用Unsubscribe
+func (h *Hub) Subscribe(tickers []string) *Subscriber {
+	ch := make(chan PriceUpdate, subscriberBuffer)
+	sub := &Subscriber{
+		C:       ch,
+		ch:      ch,
+		tickers: make(map[string]bool, len(tickers)),
+	}
+	for _, ticker := range tickers {
+		sub.tickers[ticker] = true
+	}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+}
+
+// dispatch 非阻塞地推送给订阅了对应ticker的连接，缓冲区满的慢连接会丢弃本次更新
+func (h *Hub) dispatch(updates []PriceUpdate) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		for _, update := range updates {
+			if !sub.tickers[update.Ticker] {
+				continue
+			}
+			select {
+			case sub.ch <- update:
+			default:
+			}
+		}
+	}
+}
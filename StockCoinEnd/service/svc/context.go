@@ -16,17 +16,31 @@ import (
 	"github.com/locey/CryptoStock/StockCoinEnd/config"
 	"github.com/locey/CryptoStock/StockCoinEnd/contract"
 	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/asset"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/fx"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/oracleupdate"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/stream"
 )
 
 type ServerCtx struct {
 	C  *config.Config
 	DB *gorm.DB
 	//ImageMgr image.ImageManager
-	Dao           *dao.Dao
-	KvStore       *xkv.Store
-	RankKey       string
-	NodeSrvs      map[int64]*nftchainservice.Service
-	AirdropClient contract.CSTokenContract
+	Dao             *dao.Dao
+	KvStore         *xkv.Store
+	RankKey         string
+	NodeSrvs        map[int64]*nftchainservice.Service
+	AirdropClient   contract.CSTokenContract
+	MarketData      marketdata.MarketDataProvider
+	FX              fx.RateProvider
+	Asset           *asset.Service
+	TokenFactory    *contract.TokenFactoryContract
+	ChainReader     *contract.ChainReader
+	PriceAggregator *contract.PriceAggregatorContract
+	StockToken      *contract.StockTokenContract
+	OracleUpdate    *oracleupdate.Builder
+	PriceHub        *stream.Hub
 }
 
 func NewServiceContext(c *config.Config) (*ServerCtx, error) {
@@ -73,6 +87,79 @@ func NewServiceContext(c *config.Config) (*ServerCtx, error) {
 		}
 	}
 
+	// 未配置[market_data]时不创建行情数据源，行情轮询、K线回补等随之关闭
+	var marketData marketdata.MarketDataProvider
+	if c.MarketData != nil {
+		marketData, err = marketdata.New(c.MarketData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create market data provider")
+		}
+	}
+
+	var fxProvider fx.RateProvider
+	if c.FX != nil {
+		fxProvider, err = fx.New(c.FX)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create fx provider")
+		}
+	}
+
+	var assetSrv *asset.Service
+	if c.Asset != nil {
+		assetSrv, err = asset.New(c.Asset)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create asset service")
+		}
+	}
+
+	// 共享的链上只读客户端，所有合约查询复用同一个连接
+	var chainReader *contract.ChainReader
+	if c.ChainReader != nil {
+		chainReader, err = contract.NewChainReader(c.ChainReader.RPCEndpoint, c.ChainReader.MulticallAddress, c.ChainReader.BatchSize, c.ChainReader.CallFrom)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create chain reader")
+		}
+	}
+
+	var stockToken *contract.StockTokenContract
+	if chainReader != nil {
+		stockToken, err = contract.NewStockTokenContract(chainReader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create stock token contract")
+		}
+	}
+
+	var tokenFactory *contract.TokenFactoryContract
+	if c.TokenFactory != nil {
+		if chainReader == nil {
+			return nil, errors.New("token_factory requires chain_reader config")
+		}
+		tokenFactory, err = contract.NewTokenFactoryContract(chainReader, c.TokenFactory.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create token factory contract")
+		}
+	}
+
+	var priceAgg *contract.PriceAggregatorContract
+	if c.PriceAggregator != nil {
+		if chainReader == nil {
+			return nil, errors.New("price_aggregator requires chain_reader config")
+		}
+		priceAgg, err = contract.NewPriceAggregatorContract(chainReader, c.PriceAggregator.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create price aggregator contract")
+		}
+	}
+
+	var oracleUpdate *oracleupdate.Builder
+	if priceAgg != nil {
+		pythFeed, err := contract.NewPythPriceFeedContract(chainReader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on create pyth price feed contract")
+		}
+		oracleUpdate = oracleupdate.New(c.OracleUpdate, priceAgg, pythFeed)
+	}
+
 	dao := dao.New(context.Background(), db, store)
 	serverCtx := NewServerCtx(
 		WithDB(db),
@@ -83,6 +170,18 @@ func NewServiceContext(c *config.Config) (*ServerCtx, error) {
 	serverCtx.C = c
 
 	serverCtx.NodeSrvs = nodeSrvs
+	serverCtx.MarketData = marketData
+	serverCtx.FX = fxProvider
+	serverCtx.Asset = assetSrv
+	serverCtx.TokenFactory = tokenFactory
+	serverCtx.ChainReader = chainReader
+	serverCtx.PriceAggregator = priceAgg
+	serverCtx.StockToken = stockToken
+	serverCtx.OracleUpdate = oracleUpdate
+	// 行情推送通过Redis pub/sub在多个API副本间分发
+	if c.PriceStream != nil {
+		serverCtx.PriceHub = stream.NewHub(store)
+	}
 
 	return serverCtx, nil
 }
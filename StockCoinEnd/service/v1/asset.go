@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/asset"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+)
+
+// GetAsset 获取图片代理中的图片，size为缩略图尺寸，0为原图
+func GetAsset(ctx context.Context, svcCtx *svc.ServerCtx, id string, size int) (*asset.Image, error) {
+	if svcCtx.Asset == nil {
+		return nil, errors.New("asset service is not configured")
+	}
+	return svcCtx.Asset.Get(ctx, id, size)
+}
+
+// GetAssetPlaceholder 获取seed对应的占位图
+func GetAssetPlaceholder(svcCtx *svc.ServerCtx, seed string, size int) (*asset.Image, error) {
+	if svcCtx.Asset == nil {
+		return nil, errors.New("asset service is not configured")
+	}
+	return svcCtx.Asset.Placeholder(seed, size)
+}
+
+// stockLogoURL 股票logo的代理地址，未配置logo来源时使用占位图；未启用图片代理时返回空
+func stockLogoURL(ctx context.Context, svcCtx *svc.ServerCtx, ticker string) string {
+	if svcCtx.Asset == nil {
+		return ""
+	}
+
+	for symbol, source := range svcCtx.C.Asset.StockLogos {
+		// viper读取配置时map的key会被转为小写
+		if !strings.EqualFold(symbol, ticker) {
+			continue
+		}
+		url, err := svcCtx.Asset.URL(ctx, source)
+		if err != nil {
+			log.Printf("%s logo地址无效: %v", ticker, err)
+			break
+		}
+		return url
+	}
+	return svcCtx.Asset.PlaceholderURL(ticker)
+}
+
+// proxiedImageURL 将第三方图片地址替换为图片代理地址，无法代理时使用占位图
+func proxiedImageURL(ctx context.Context, svcCtx *svc.ServerCtx, imageURI string, seed string) string {
+	if svcCtx.Asset == nil {
+		return imageURI
+	}
+	if imageURI == "" {
+		return svcCtx.Asset.PlaceholderURL(seed)
+	}
+
+	url, err := svcCtx.Asset.URL(ctx, imageURI)
+	if err != nil {
+		log.Printf("图片地址 %s 无法代理: %v", imageURI, err)
+		return svcCtx.Asset.PlaceholderURL(seed)
+	}
+	return url
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	candleBackfillDays  = 365                // 回补的历史天数
+	candleBackfillSlack = 7 * 24 * time.Hour // 回补起点附近的休市天数容差
+)
+
+// GetStockCandles 查询K线，1w/1M由日线在服务端汇总
+func GetStockCandles(ctx context.Context, svcCtx *svc.ServerCtx, ticker, interval string, from, to time.Time) (*types.StockCandlesResp, error) {
+	var bucket func(t time.Time) time.Time
+	switch interval {
+	case dao.CandleInterval1d:
+	case dao.CandleInterval1w:
+		bucket = weekStart
+	case dao.CandleInterval1M:
+		bucket = monthStart
+	default:
+		return nil, errcode.NewCustomErr("unsupported interval: " + interval)
+	}
+
+	// 汇总时需要把起始时间对齐到周期起点，保证第一根K线完整
+	if bucket != nil {
+		from = bucket(from.UTC())
+	}
+
+	candles, err := svcCtx.Dao.QueryCandles(ctx, ticker, dao.CandleInterval1d, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query candles")
+	}
+	// K线表保存未复权数据，返回前按拆股记录前复权
+	splits, err := svcCtx.Dao.QuerySplitsByTickers(ctx, []string{ticker})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+
+	resp := &types.StockCandlesResp{
+		Ticker:   ticker,
+		Interval: interval,
+		Candles:  make([]types.StockCandle, 0, len(candles)),
+	}
+	for _, c := range candles {
+		factor := splitPriceFactor(splits[ticker], c.Timestamp, now)
+		resp.Candles = append(resp.Candles, types.StockCandle{
+			Timestamp: c.Timestamp,
+			Open:      c.Open * factor,
+			High:      c.High * factor,
+			Low:       c.Low * factor,
+			Close:     c.Close * factor,
+			Volume:    c.Volume / factor,
+			ItemCount: c.ItemCount,
+		})
+	}
+	if bucket != nil {
+		resp.Candles = rollupCandles(resp.Candles, bucket)
+	}
+
+	return resp, nil
+}
+
+// rollupCandles 将按时间排序的日线按bucket所在周期汇总，K线时间为周期起点
+func rollupCandles(daily []types.StockCandle, bucket func(t time.Time) time.Time) []types.StockCandle {
+	candles := make([]types.StockCandle, 0, len(daily))
+	for _, candle := range daily {
+		candle.Timestamp = bucket(time.UnixMilli(candle.Timestamp).UTC()).UnixMilli()
+		n := len(candles)
+		if n == 0 || candles[n-1].Timestamp != candle.Timestamp {
+			candles = append(candles, candle)
+			continue
+		}
+		last := &candles[n-1]
+		if candle.High > last.High {
+			last.High = candle.High
+		}
+		if candle.Low < last.Low {
+			last.Low = candle.Low
+		}
+		last.Close = candle.Close
+		last.Volume += candle.Volume
+		last.ItemCount += candle.ItemCount
+	}
+	return candles
+}
+
+// saveCandles 将数据源返回的日线写入K线表
+func saveCandles(ctx context.Context, svcCtx *svc.ServerCtx, ticker string, bars []marketdata.Bar) error {
+	candles := make([]dao.StockCandle, 0, len(bars))
+	for _, bar := range bars {
+		candles = append(candles, dao.StockCandle{
+			Ticker:    ticker,
+			Interval:  dao.CandleInterval1d,
+			Timestamp: bar.Timestamp,
+			Open:      bar.Open,
+			High:      bar.High,
+			Low:       bar.Low,
+			Close:     bar.Close,
+			Volume:    bar.Volume,
+			ItemCount: int64(bar.ItemCount),
+		})
+	}
+
+	return svcCtx.Dao.BatchUpsertCandles(ctx, candles)
+}
+
+// backfillCandles 指定ticker的日线不足candleBackfillDays天时回补缺少的历史（如只有行情轮询写入的最近几天），
+// 已有完整历史时跳过；未配置行情数据源时跳过
+func backfillCandles(svcCtx *svc.ServerCtx, ticker string) {
+	if svcCtx.MarketData == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	earliest, err := svcCtx.Dao.GetEarliestCandle(ctx, ticker, dao.CandleInterval1d)
+	if err != nil {
+		log.Printf("GetEarliestCandle error: %v", err)
+		return
+	}
+	now := time.Now()
+	from, to, ok := candleBackfillRange(earliest, now)
+	if !ok {
+		return
+	}
+
+	bars, err := svcCtx.MarketData.GetDailyAggregates(ctx, ticker, from, to)
+	if err != nil {
+		log.Printf("%s 回补K线失败: %v", ticker, err)
+		return
+	}
+	if err := saveCandles(ctx, svcCtx, ticker, bars); err != nil {
+		log.Printf("%s 保存K线失败: %v", ticker, err)
+	}
+}
+
+// candleBackfillRange 计算需要回补的日线区间：没有K线时回补最近candleBackfillDays天，
+// 最早的K线晚于回补起点（考虑周末及节假日留出candleBackfillSlack）时回补到最早K线之前
+func candleBackfillRange(earliest *dao.StockCandle, now time.Time) (time.Time, time.Time, bool) {
+	from := now.AddDate(0, 0, -candleBackfillDays)
+	if earliest == nil {
+		return from, now, true
+	}
+	first := time.UnixMilli(earliest.Timestamp)
+	if !first.After(from.Add(candleBackfillSlack)) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, first.Add(-time.Millisecond), true
+}
+
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7 // 周一为一周起点
+	return day.AddDate(0, 0, -offset)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+func utcDay(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want time.Time
+	}{
+		{utcDay(2024, time.March, 6), utcDay(2024, time.March, 4)},
+		// 周日属于前一个周一开始的周
+		{utcDay(2024, time.March, 10).Add(23 * time.Hour), utcDay(2024, time.March, 4)},
+		{utcDay(2024, time.March, 11).Add(9 * time.Hour), utcDay(2024, time.March, 11)},
+		// 跨年的周
+		{utcDay(2025, time.January, 1), utcDay(2024, time.December, 30)},
+	}
+	for _, tt := range tests {
+		if got := weekStart(tt.in); !got.Equal(tt.want) {
+			t.Errorf("weekStart(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMonthStart(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want time.Time
+	}{
+		{utcDay(2024, time.February, 29).Add(15 * time.Hour), utcDay(2024, time.February, 1)},
+		{utcDay(2024, time.March, 1), utcDay(2024, time.March, 1)},
+	}
+	for _, tt := range tests {
+		if got := monthStart(tt.in); !got.Equal(tt.want) {
+			t.Errorf("monthStart(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRollupCandles(t *testing.T) {
+	daily := []types.StockCandle{
+		{Timestamp: utcDay(2024, time.February, 29).UnixMilli(), Open: 10, High: 12, Low: 9, Close: 11, Volume: 100, ItemCount: 1},
+		{Timestamp: utcDay(2024, time.March, 1).UnixMilli(), Open: 11, High: 13, Low: 10, Close: 12, Volume: 200, ItemCount: 2},
+		{Timestamp: utcDay(2024, time.March, 4).UnixMilli(), Open: 12, High: 15, Low: 8, Close: 14, Volume: 300, ItemCount: 3},
+		{Timestamp: utcDay(2024, time.March, 5).UnixMilli(), Open: 14, High: 14, Low: 11, Close: 13, Volume: 400, ItemCount: 4},
+	}
+
+	tests := []struct {
+		name   string
+		bucket func(t time.Time) time.Time
+		want   []types.StockCandle
+	}{
+		{
+			name:   "week",
+			bucket: weekStart,
+			want: []types.StockCandle{
+				{Timestamp: utcDay(2024, time.February, 26).UnixMilli(), Open: 10, High: 13, Low: 9, Close: 12, Volume: 300, ItemCount: 3},
+				{Timestamp: utcDay(2024, time.March, 4).UnixMilli(), Open: 12, High: 15, Low: 8, Close: 13, Volume: 700, ItemCount: 7},
+			},
+		},
+		{
+			name:   "month",
+			bucket: monthStart,
+			want: []types.StockCandle{
+				{Timestamp: utcDay(2024, time.February, 1).UnixMilli(), Open: 10, High: 12, Low: 9, Close: 11, Volume: 100, ItemCount: 1},
+				{Timestamp: utcDay(2024, time.March, 1).UnixMilli(), Open: 11, High: 15, Low: 8, Close: 13, Volume: 900, ItemCount: 9},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rollupCandles(daily, tt.bucket)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d candles, got %+v", len(tt.want), got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("candle %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// 汇总不应修改传入的日线
+	if daily[1].Timestamp != utcDay(2024, time.March, 1).UnixMilli() {
+		t.Errorf("rollupCandles modified the daily candles")
+	}
+}
+
+func TestCandleBackfillRange(t *testing.T) {
+	now := utcDay(2025, time.October, 11)
+	from := now.AddDate(0, 0, -candleBackfillDays)
+
+	// 没有K线时回补完整历史
+	if gotFrom, gotTo, ok := candleBackfillRange(nil, now); !ok || !gotFrom.Equal(from) || !gotTo.Equal(now) {
+		t.Errorf("candleBackfillRange(nil) = %v, %v, %v, want %v, %v, true", gotFrom, gotTo, ok, from, now)
+	}
+
+	// 只有行情轮询写入的最近几天K线时，回补到最早K线之前
+	recent := &dao.StockCandle{Timestamp: now.AddDate(0, 0, -7).UnixMilli()}
+	gotFrom, gotTo, ok := candleBackfillRange(recent, now)
+	if !ok || !gotFrom.Equal(from) || gotTo.UnixMilli() != recent.Timestamp-1 {
+		t.Errorf("candleBackfillRange(recent) = %v, %v, %v, want %v, %d, true", gotFrom, gotTo.UnixMilli(), ok, from, recent.Timestamp-1)
+	}
+
+	// 最早K线在回补起点附近（休市天数容差内）视为已有完整历史
+	for _, days := range []int{candleBackfillDays + 10, candleBackfillDays, candleBackfillDays - 5} {
+		earliest := &dao.StockCandle{Timestamp: now.AddDate(0, 0, -days).UnixMilli()}
+		if _, _, ok := candleBackfillRange(earliest, now); ok {
+			t.Errorf("candleBackfillRange(%d days ago) should skip", days)
+		}
+	}
+}
@@ -808,6 +808,6 @@ func GetItemImage(ctx context.Context, svcCtx *svc.ServerCtx, chain string, coll
 	return &types.ItemImage{
 		CollectionAddress: collectionAddress,
 		TokenID:           tokenId,
-		ImageUri:          imageUri,
+		ImageUri:          proxiedImageURL(ctx, svcCtx, imageUri, collectionAddress+"/"+tokenId),
 	}, nil
 }
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	// 同步公司行为的时间窗口：回溯一年的历史，并包含未来已公告的
+	actionLookbackDays  = 365
+	actionLookaheadDays = 90
+)
+
+// StartCorporateActionSync 定时从行情数据源同步拆股、分红信息
+func StartCorporateActionSync(svcCtx *svc.ServerCtx, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	syncCorporateActions(svcCtx)
+	for {
+		select {
+		case <-ticker.C:
+			syncCorporateActions(svcCtx)
+		}
+	}
+}
+
+func syncCorporateActions(svcCtx *svc.ServerCtx) {
+	tickers, err := svcCtx.Dao.ListActiveTickers()
+	if err != nil {
+		log.Printf("ListActiveTickers error: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, ticker := range tickers {
+		// 逐只拉取，避免触发数据源的速率限制
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		actions, err := svcCtx.MarketData.GetCorporateActions(ctx, ticker, now.AddDate(0, 0, -actionLookbackDays), now.AddDate(0, 0, actionLookaheadDays))
+		if err != nil {
+			cancel()
+			log.Printf("%s 获取公司行为失败: %v", ticker, err)
+			continue
+		}
+
+		records := make([]dao.CorporateAction, 0, len(actions))
+		for _, action := range actions {
+			record, err := toCorporateAction(action)
+			if err != nil {
+				log.Printf("%s 公司行为数据无效: %v", ticker, err)
+				continue
+			}
+			records = append(records, record)
+		}
+		if err := svcCtx.Dao.BatchUpsertCorporateActions(ctx, records); err != nil {
+			log.Printf("%s 保存公司行为失败: %v", ticker, err)
+		}
+		cancel()
+	}
+}
+
+func toCorporateAction(action marketdata.CorporateAction) (dao.CorporateAction, error) {
+	exDate, err := time.ParseInLocation(marketdata.DateLayout, action.ExDate, timeUtil.NewYork())
+	if err != nil {
+		return dao.CorporateAction{}, errors.Wrapf(err, "invalid ex date %s", action.ExDate)
+	}
+
+	record := dao.CorporateAction{
+		Ticker:     action.Ticker,
+		ExDate:     exDate.UnixMilli(),
+		SplitFrom:  action.SplitFrom,
+		SplitTo:    action.SplitTo,
+		CashAmount: decimal.NewFromFloat(action.CashAmount),
+		Currency:   action.Currency,
+	}
+	switch action.Type {
+	case marketdata.ActionTypeSplit:
+		if action.SplitFrom <= 0 || action.SplitTo <= 0 {
+			return dao.CorporateAction{}, errors.Errorf("invalid split ratio %v:%v", action.SplitFrom, action.SplitTo)
+		}
+		record.ActionType = dao.ActionTypeSplit
+	case marketdata.ActionTypeDividend:
+		record.ActionType = dao.ActionTypeDividend
+	default:
+		return dao.CorporateAction{}, errors.Errorf("unsupported action type %s", action.Type)
+	}
+	if action.PayDate != "" {
+		payDate, err := time.ParseInLocation(marketdata.DateLayout, action.PayDate, timeUtil.NewYork())
+		if err != nil {
+			return dao.CorporateAction{}, errors.Wrapf(err, "invalid pay date %s", action.PayDate)
+		}
+		record.PayDate = payDate.UnixMilli()
+	}
+
+	return record, nil
+}
+
+// GetCorporateActions 查询股票的公司行为，区分已发生和即将发生
+func GetCorporateActions(ctx context.Context, svcCtx *svc.ServerCtx, ticker, actionType string) (*types.CorporateActionsResp, error) {
+	actions, err := svcCtx.Dao.QueryCorporateActions(ctx, ticker, actionType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	resp := &types.CorporateActionsResp{
+		Ticker:  ticker,
+		Actions: make([]types.CorporateAction, 0, len(actions)),
+	}
+	for _, action := range actions {
+		resp.Actions = append(resp.Actions, types.CorporateAction{
+			Type:       action.ActionType,
+			ExDate:     action.ExDate,
+			PayDate:    action.PayDate,
+			SplitFrom:  action.SplitFrom,
+			SplitTo:    action.SplitTo,
+			CashAmount: action.CashAmount,
+			Currency:   action.Currency,
+			Upcoming:   action.ExDate > now,
+		})
+	}
+
+	return resp, nil
+}
+
+// splitPriceFactor 时间戳ts（毫秒）的价格需要乘以的复权系数：ts之后、now之前已生效的拆股比例之积
+func splitPriceFactor(splits []dao.CorporateAction, ts, now int64) float64 {
+	factor := 1.0
+	for _, split := range splits {
+		if split.ExDate > ts && split.ExDate <= now && split.SplitFrom > 0 && split.SplitTo > 0 {
+			factor *= split.SplitFrom / split.SplitTo
+		}
+	}
+	return factor
+}
+
+// adjustBarsForSplits 对日线做拆股前复权：价格乘以复权系数，成交量除以复权系数
+func adjustBarsForSplits(bars []marketdata.Bar, splits []dao.CorporateAction, now int64) []marketdata.Bar {
+	if len(splits) == 0 {
+		return bars
+	}
+
+	adjusted := make([]marketdata.Bar, len(bars))
+	for i, bar := range bars {
+		factor := splitPriceFactor(splits, bar.Timestamp, now)
+		bar.Open *= factor
+		bar.High *= factor
+		bar.Low *= factor
+		bar.Close *= factor
+		bar.Volume /= factor
+		adjusted[i] = bar
+	}
+	return adjusted
+}
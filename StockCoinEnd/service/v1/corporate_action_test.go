@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+)
+
+func TestSplitPriceFactor(t *testing.T) {
+	// 1拆4在1000生效，1拆2在2000生效，3000的拆股尚未生效
+	splits := []dao.CorporateAction{
+		{ActionType: dao.ActionTypeSplit, ExDate: 1000, SplitFrom: 1, SplitTo: 4},
+		{ActionType: dao.ActionTypeSplit, ExDate: 2000, SplitFrom: 1, SplitTo: 2},
+		{ActionType: dao.ActionTypeSplit, ExDate: 3000, SplitFrom: 1, SplitTo: 10},
+		// 比例不合法的记录被忽略
+		{ActionType: dao.ActionTypeSplit, ExDate: 1500, SplitFrom: 0, SplitTo: 3},
+	}
+	now := int64(2500)
+
+	tests := []struct {
+		name string
+		ts   int64
+		want float64
+	}{
+		{"before both splits", 500, 0.125},
+		{"on the ex date of the first split", 1000, 0.5},
+		{"between the splits", 1500, 0.5},
+		{"after both splits", 2000, 1},
+		{"after now", 2800, 1},
+	}
+	for _, tt := range tests {
+		if got := splitPriceFactor(splits, tt.ts, now); got != tt.want {
+			t.Errorf("%s: splitPriceFactor(%d) = %v, want %v", tt.name, tt.ts, got, tt.want)
+		}
+	}
+
+	if got := splitPriceFactor(nil, 500, now); got != 1 {
+		t.Errorf("splitPriceFactor without splits = %v, want 1", got)
+	}
+}
+
+func TestAdjustBarsForSplits(t *testing.T) {
+	bars := []marketdata.Bar{
+		{Timestamp: 500, Open: 400, High: 420, Low: 380, Close: 410, Volume: 100, ItemCount: 7},
+		{Timestamp: 1500, Open: 100, High: 110, Low: 90, Close: 105, Volume: 400, ItemCount: 8},
+	}
+	splits := []dao.CorporateAction{
+		{ActionType: dao.ActionTypeSplit, ExDate: 1000, SplitFrom: 1, SplitTo: 4},
+	}
+
+	adjusted := adjustBarsForSplits(bars, splits, 2000)
+	want := []marketdata.Bar{
+		{Timestamp: 500, Open: 100, High: 105, Low: 95, Close: 102.5, Volume: 400, ItemCount: 7},
+		{Timestamp: 1500, Open: 100, High: 110, Low: 90, Close: 105, Volume: 400, ItemCount: 8},
+	}
+	if len(adjusted) != len(want) {
+		t.Fatalf("expected %d bars, got %d", len(want), len(adjusted))
+	}
+	for i := range want {
+		if adjusted[i] != want[i] {
+			t.Errorf("bar %d: got %+v, want %+v", i, adjusted[i], want[i])
+		}
+	}
+
+	// 复权不应修改传入的日线
+	if bars[0].Open != 400 {
+		t.Errorf("adjustBarsForSplits modified the input bars")
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/fx"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const defaultFxCacheSeconds = 600
+
+func fxRateCacheKey(currency string) string {
+	return fmt.Sprintf("cache:fx:%s:%s", strings.ToLower(fx.BaseCurrency), strings.ToLower(currency))
+}
+
+// GetFxQuote 获取美元兑currency的汇率，currency为空或USD时汇率为1；汇率缓存在redis中
+func GetFxQuote(ctx context.Context, svcCtx *svc.ServerCtx, currency string) (*types.FxQuote, error) {
+	currency = fx.NormalizeCurrency(currency)
+	if currency == "" || currency == fx.BaseCurrency {
+		return &types.FxQuote{Currency: fx.BaseCurrency, Rate: decimal.NewFromInt(1), AsOf: time.Now().UnixMilli()}, nil
+	}
+	// 未配置[fx]时只支持美元
+	if svcCtx.FX == nil || !isSupportedCurrency(svcCtx, currency) {
+		return nil, errcode.NewCustomErr("unsupported currency: " + currency)
+	}
+
+	cacheSeconds := defaultFxCacheSeconds
+	if svcCtx.C.FX.CacheSeconds > 0 {
+		cacheSeconds = svcCtx.C.FX.CacheSeconds
+	}
+
+	var quote types.FxQuote
+	err := svcCtx.KvStore.ReadOrGet(fxRateCacheKey(currency), &quote, func() (interface{}, error) {
+		rates, err := svcCtx.FX.GetRates(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed on get fx rates from %s", svcCtx.FX.Name())
+		}
+		rate, ok := rates.Rates[currency]
+		if !ok || !rate.IsPositive() {
+			return nil, errors.Errorf("fx rate of %s is unavailable", currency)
+		}
+		return &types.FxQuote{Currency: currency, Rate: rate, AsOf: rates.AsOf}, nil
+	}, cacheSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+func isSupportedCurrency(svcCtx *svc.ServerCtx, currency string) bool {
+	if svcCtx.C.FX == nil {
+		return false
+	}
+	for _, supported := range svcCtx.C.FX.Currencies {
+		if fx.NormalizeCurrency(supported) == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// convertStockSummaries 将股票列表中的价格、市值换算为quote对应的币种，成交量等数量不换算
+func convertStockSummaries(summaries []StockSummary, quote *types.FxQuote) {
+	if quote.Currency == fx.BaseCurrency {
+		return
+	}
+	for i := range summaries {
+		summaries[i].AvgGain = summaries[i].AvgGain.Mul(quote.Rate)
+		summaries[i].CurrentPrice = summaries[i].CurrentPrice.Mul(quote.Rate)
+		summaries[i].MarketCap = summaries[i].MarketCap.Mul(quote.Rate)
+		summaries[i].StockPoolMarketCap = summaries[i].StockPoolMarketCap.Mul(quote.Rate)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+func TestGetFxQuoteWithoutFx(t *testing.T) {
+	// 未配置[fx]
+	svcCtx := &svc.ServerCtx{C: &config.Config{}}
+
+	for _, currency := range []string{"", "usd", " USD "} {
+		quote, err := GetFxQuote(context.Background(), svcCtx, currency)
+		if err != nil {
+			t.Errorf("GetFxQuote(%q): %v", currency, err)
+			continue
+		}
+		if quote.Currency != "USD" || !quote.Rate.Equal(decimal.NewFromInt(1)) {
+			t.Errorf("GetFxQuote(%q) = %+v, want USD at rate 1", currency, quote)
+		}
+	}
+
+	_, err := GetFxQuote(context.Background(), svcCtx, "cny")
+	if e, ok := err.(*errcode.Err); !ok || e.Error() != "unsupported currency: CNY" {
+		t.Errorf("GetFxQuote(cny) err = %v, want unsupported currency", err)
+	}
+}
+
+func TestConvertStockSummaries(t *testing.T) {
+	d := decimal.RequireFromString
+	newSummaries := func() []StockSummary {
+		return []StockSummary{{
+			AvgGain:            d("1.5"),
+			AvgGainPercent:     2.5,
+			AvgVolume:          1000,
+			CurrentPrice:       d("100"),
+			MarketCap:          d("1000000"),
+			StockPoolMarketCap: d("5000"),
+		}}
+	}
+
+	summaries := newSummaries()
+	convertStockSummaries(summaries, &types.FxQuote{Currency: "CNY", Rate: d("7.2")})
+	s := summaries[0]
+	if !s.AvgGain.Equal(d("10.8")) || !s.CurrentPrice.Equal(d("720")) ||
+		!s.MarketCap.Equal(d("7200000")) || !s.StockPoolMarketCap.Equal(d("36000")) {
+		t.Errorf("converted amounts = %s/%s/%s/%s", s.AvgGain, s.CurrentPrice, s.MarketCap, s.StockPoolMarketCap)
+	}
+	// 百分比及成交量与币种无关
+	if s.AvgGainPercent != 2.5 || s.AvgVolume != 1000 {
+		t.Errorf("percent/volume = %v/%v, want unchanged", s.AvgGainPercent, s.AvgVolume)
+	}
+
+	summaries = newSummaries()
+	convertStockSummaries(summaries, &types.FxQuote{Currency: "USD", Rate: decimal.NewFromInt(1)})
+	if !summaries[0].CurrentPrice.Equal(d("100")) {
+		t.Errorf("USD quote changed the price to %s", summaries[0].CurrentPrice)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"time"
+
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+)
+
+const (
+	// 计算涨幅、成交量均值使用的交易日数
+	statsTradingDays = 7
+
+	defaultExtendedPollFactor = 5
+	defaultClosedPollInterval = time.Hour
+	defaultStaleFactor        = 3
+	defaultStaleAfter         = 15 * time.Minute
+)
+
+// pollInterval 按交易时段决定行情拉取频率：盘中最频繁，盘前盘后次之，休市时最低
+func pollInterval(c *config.MarketData, session timeUtil.MarketSession) time.Duration {
+	regular := time.Duration(c.PollInterval) * time.Second
+	switch session {
+	case timeUtil.SessionRegular:
+		return regular
+	case timeUtil.SessionPreMarket, timeUtil.SessionAfterHours:
+		if c.ExtendedPollInterval > 0 {
+			return time.Duration(c.ExtendedPollInterval) * time.Second
+		}
+		return regular * defaultExtendedPollFactor
+	default:
+		if c.ClosedPollInterval > 0 {
+			return time.Duration(c.ClosedPollInterval) * time.Second
+		}
+		return defaultClosedPollInterval
+	}
+}
+
+// nextPollDelay 下一次拉取的等待时间，不跨越时段边界，保证开盘、收盘后及时切换频率并拿到收盘价
+func nextPollDelay(c *config.MarketData, now time.Time) time.Duration {
+	delay := pollInterval(c, timeUtil.NYSESession(now))
+	if change := timeUtil.NextNYSESessionChange(now); now.Add(delay).After(change) {
+		delay = change.Sub(now)
+	}
+	return delay
+}
+
+// quoteAsOf 最新日线对应的行情时间：收盘前为拉取时间，收盘后为当日收盘时间
+func quoteAsOf(bar marketdata.Bar, fetchedAt time.Time) time.Time {
+	_, closeAt, ok := timeUtil.NYSEOpenClose(time.UnixMilli(bar.Timestamp))
+	if !ok || fetchedAt.Before(closeAt) {
+		return fetchedAt
+	}
+	return closeAt
+}
+
+// isQuoteStale 盘中超过stale_after未更新，或休市时不是最近一个交易日的收盘价，视为过期
+func isQuoteStale(c *config.MarketData, asOf int64, now time.Time) bool {
+	if asOf == 0 {
+		return true
+	}
+
+	t := time.UnixMilli(asOf)
+	if timeUtil.NYSESession(now) == timeUtil.SessionRegular {
+		return now.Sub(t) > staleAfter(c)
+	}
+	return t.Before(timeUtil.LastNYSEClose(now))
+}
+
+func staleAfter(c *config.MarketData) time.Duration {
+	switch {
+	case c == nil:
+		return defaultStaleAfter
+	case c.StaleAfter > 0:
+		return time.Duration(c.StaleAfter) * time.Second
+	case c.PollInterval > 0:
+		return time.Duration(c.PollInterval) * time.Second * defaultStaleFactor
+	}
+	return defaultStaleAfter
+}
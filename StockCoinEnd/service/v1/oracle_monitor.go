@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+	"github.com/locey/CryptoStock/StockCoinEnd/contract"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+)
+
+// OracleDeviationAlert 偏差告警推送内容
+type OracleDeviationAlert struct {
+	Ticker       string          `json:"ticker"`
+	OraclePrice  decimal.Decimal `json:"oracle_price"`
+	MarketPrice  decimal.Decimal `json:"market_price"`
+	DeviationBps int64           `json:"deviation_bps"`
+	ThresholdBps int64           `json:"threshold_bps"`
+	Recovered    bool            `json:"recovered"` // true表示偏差已回落到阈值以内
+	Timestamp    int64           `json:"timestamp"`
+}
+
+// oracleMonitor 记录各ticker当前是否处于告警状态，只在状态切换时推送，避免每轮重复告警
+type oracleMonitor struct {
+	svcCtx   *svc.ServerCtx
+	alerting map[string]bool
+	client   *http.Client
+}
+
+// StartOracleMonitor 定时比较PriceAggregator聚合价格与市场价格，记录偏差并在超过阈值时告警
+func StartOracleMonitor(svcCtx *svc.ServerCtx, interval time.Duration) {
+	if svcCtx.PriceAggregator == nil {
+		log.Println("PriceAggregator未配置，跳过预言机偏差监控")
+		return
+	}
+
+	m := &oracleMonitor{
+		svcCtx:   svcCtx,
+		alerting: make(map[string]bool),
+		client:   &http.Client{Timeout: timeout},
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.check()
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+func (m *oracleMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+
+	tickers, err := m.svcCtx.Dao.ListActiveTickers()
+	if err != nil {
+		log.Printf("获取股票列表失败: %v", err)
+		return
+	}
+	supported, err := m.supportedSymbols(ctx)
+	if err != nil {
+		log.Printf("获取预言机支持的股票代码失败: %v", err)
+		return
+	}
+	// 只检查已上架且预言机支持的股票：没有喂价的股票无法比较，未上架的代码没有市场价格
+	tickers = intersectTickers(tickers, supported)
+	if len(tickers) == 0 {
+		return
+	}
+	stocks, err := m.svcCtx.Dao.GetByTickers(tickers)
+	if err != nil {
+		log.Printf("获取股票信息失败: %v", err)
+		return
+	}
+
+	threshold := m.svcCtx.C.OracleMonitor.ThresholdBps
+	now := time.Now()
+	deviations := make([]dao.OracleDeviation, 0, len(stocks))
+	for _, stock := range stocks {
+		if !stock.CurrentPrice.IsPositive() {
+			continue
+		}
+
+		updateData, fee, err := buildOracleUpdateData(ctx, m.svcCtx, stock.Ticker)
+		if err != nil {
+			log.Printf("%s 构造预言机更新数据失败: %v", stock.Ticker, err)
+			continue
+		}
+		price, err := m.svcCtx.PriceAggregator.GetAggregatedPrice(ctx, stock.Ticker, updateData, fee)
+		if err != nil {
+			log.Printf("%s 获取预言机价格失败: %v", stock.Ticker, err)
+			continue
+		}
+
+		oraclePrice := decimal.NewFromBigInt(price, -contract.PriceDecimals)
+		deviation := dao.OracleDeviation{
+			Ticker:       stock.Ticker,
+			OraclePrice:  oraclePrice,
+			MarketPrice:  stock.CurrentPrice,
+			DeviationBps: deviationBps(oraclePrice, stock.CurrentPrice),
+			IsStale:      isDeviationStale(m.svcCtx.C.MarketData, stock.PriceAsOf, now),
+			Timestamp:    now.UnixMilli(),
+		}
+		deviation.IsAlert = !deviation.IsStale && threshold > 0 && abs64(deviation.DeviationBps) >= threshold
+		deviations = append(deviations, deviation)
+
+		// 市场价格不可信时只记录采样，不告警也不切换告警状态
+		if deviation.IsStale {
+			continue
+		}
+		if deviation.IsAlert != m.alerting[stock.Ticker] {
+			m.alerting[stock.Ticker] = deviation.IsAlert
+			m.raiseAlert(OracleDeviationAlert{
+				Ticker:       deviation.Ticker,
+				OraclePrice:  deviation.OraclePrice,
+				MarketPrice:  deviation.MarketPrice,
+				DeviationBps: deviation.DeviationBps,
+				ThresholdBps: threshold,
+				Recovered:    !deviation.IsAlert,
+				Timestamp:    deviation.Timestamp,
+			})
+		}
+	}
+
+	if err := m.svcCtx.Dao.BatchCreateOracleDeviations(ctx, deviations); err != nil {
+		log.Printf("保存预言机偏差失败: %v", err)
+	}
+}
+
+// supportedSymbols PriceAggregator中各Pyth预言机支持的股票代码（RedStone预言机不提供代码列表）
+func (m *oracleMonitor) supportedSymbols(ctx context.Context) ([]string, error) {
+	if m.svcCtx.ChainReader == nil {
+		return nil, errors.New("chain reader not configured")
+	}
+	oracles, err := m.svcCtx.PriceAggregator.GetOracles(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on get oracles")
+	}
+
+	var symbols []string
+	for _, oracle := range oracles {
+		if oracle.OracleType != contract.OracleTypePyth {
+			continue
+		}
+		feedSymbols, err := m.svcCtx.ChainReader.SupportedSymbols(ctx, oracle.OracleAddress)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed on get supported symbols of %s", oracle.OracleAddress.Hex())
+		}
+		symbols = append(symbols, feedSymbols...)
+	}
+	return symbols, nil
+}
+
+// intersectTickers 返回同时出现在tickers及symbols中的股票代码（不区分大小写），按tickers顺序去重
+func intersectTickers(tickers, symbols []string) []string {
+	supported := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		supported[strings.ToUpper(symbol)] = true
+	}
+
+	result := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		key := strings.ToUpper(ticker)
+		if supported[key] {
+			result = append(result, ticker)
+			supported[key] = false
+		}
+	}
+	return result
+}
+
+// raiseAlert 记录告警日志，配置了webhook时推送告警内容
+func (m *oracleMonitor) raiseAlert(alert OracleDeviationAlert) {
+	if alert.Recovered {
+		log.Printf("[预言机偏差恢复] %s 预言机价格 %s 市场价格 %s 偏差 %d bps",
+			alert.Ticker, alert.OraclePrice, alert.MarketPrice, alert.DeviationBps)
+	} else {
+		log.Printf("[预言机偏差告警] %s 预言机价格 %s 市场价格 %s 偏差 %d bps 超过阈值 %d bps",
+			alert.Ticker, alert.OraclePrice, alert.MarketPrice, alert.DeviationBps, alert.ThresholdBps)
+	}
+
+	webhook := m.svcCtx.C.OracleMonitor.AlertWebhook
+	if webhook == "" {
+		return
+	}
+	if err := m.postAlert(webhook, alert); err != nil {
+		log.Printf("推送预言机偏差告警失败: %v", err)
+	}
+}
+
+func (m *oracleMonitor) postAlert(webhook string, alert OracleDeviationAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed on marshal alert")
+	}
+
+	resp, err := m.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed on post alert")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// isDeviationStale 非盘中时段或市场价格已过期时，偏差反映的是行情滞后而不是预言机异常
+func isDeviationStale(c *config.MarketData, priceAsOf int64, now time.Time) bool {
+	return timeUtil.NYSESession(now) != timeUtil.SessionRegular || isQuoteStale(c, priceAsOf, now)
+}
+
+// deviationBps 计算(oracle-market)/market，单位基点
+func deviationBps(oraclePrice, marketPrice decimal.Decimal) int64 {
+	return oraclePrice.Sub(marketPrice).Div(marketPrice).Mul(decimal.NewFromInt(10000)).Round(0).IntPart()
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
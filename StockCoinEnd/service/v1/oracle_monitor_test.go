@@ -0,0 +1,80 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/config"
+)
+
+func TestDeviationBps(t *testing.T) {
+	tests := []struct {
+		name   string
+		oracle string
+		market string
+		want   int64
+	}{
+		{"equal", "100", "100", 0},
+		{"oracle above", "101", "100", 100},
+		{"oracle below", "99.5", "100", -50},
+		{"round half up", "100.005", "100", 1},
+		{"round down", "100.004", "100", 0},
+		{"negative rounds away from zero", "99.995", "100", -1},
+		{"large deviation", "150", "100", 5000},
+	}
+	for _, tt := range tests {
+		got := deviationBps(decimal.RequireFromString(tt.oracle), decimal.RequireFromString(tt.market))
+		if got != tt.want {
+			t.Errorf("%s: deviationBps(%s, %s) = %d, want %d", tt.name, tt.oracle, tt.market, got, tt.want)
+		}
+	}
+}
+
+func TestIntersectTickers(t *testing.T) {
+	tests := []struct {
+		name    string
+		tickers []string
+		symbols []string
+		want    []string
+	}{
+		{"no symbols", []string{"AAPL", "TSLA"}, nil, []string{}},
+		{"keeps ticker order", []string{"TSLA", "AAPL", "MSFT"}, []string{"AAPL", "TSLA"}, []string{"TSLA", "AAPL"}},
+		{"case insensitive", []string{"aapl", "TSLA"}, []string{"AAPL", "tsla"}, []string{"aapl", "TSLA"}},
+		{"deduplicated", []string{"AAPL", "aapl", "AAPL"}, []string{"AAPL", "AAPL"}, []string{"AAPL"}},
+		{"symbol not listed", []string{"AAPL"}, []string{"GOOG"}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := intersectTickers(tt.tickers, tt.symbols); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: intersectTickers = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsDeviationStale(t *testing.T) {
+	c := &config.MarketData{PollInterval: 60, StaleAfter: 300}
+	// 纽约时间2024-03-06（周三）10:00，盘中
+	regular := time.Date(2024, time.March, 6, 15, 0, 0, 0, time.UTC)
+	// 纽约时间2024-03-06 17:00，盘后
+	afterHours := time.Date(2024, time.March, 6, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		priceAsOf int64
+		now       time.Time
+		want      bool
+	}{
+		{"fresh regular session quote", regular.Add(-time.Minute).UnixMilli(), regular, false},
+		{"outdated regular session quote", regular.Add(-10 * time.Minute).UnixMilli(), regular, true},
+		{"never updated", 0, regular, true},
+		{"after hours with closing price", afterHours.Add(-time.Hour).UnixMilli(), afterHours, true},
+		{"weekend", regular.Add(-time.Minute).UnixMilli(), regular.AddDate(0, 0, 3), true},
+	}
+	for _, tt := range tests {
+		if got := isDeviationStale(c, tt.priceAsOf, tt.now); got != tt.want {
+			t.Errorf("%s: isDeviationStale = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/contract"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/oracleupdate"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+// GetOracleUpdateData 获取ticker最新的预言机更新数据及需要支付的费用，前端可直接用于buy/sell调用
+func GetOracleUpdateData(ctx context.Context, svcCtx *svc.ServerCtx, ticker string) (*types.OracleUpdateData, error) {
+	if _, err := getStockTokenAddress(svcCtx, ticker); err != nil {
+		return nil, err
+	}
+
+	res, err := fetchOracleUpdate(ctx, svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.OracleUpdateData{
+		Ticker:     ticker,
+		Oracles:    make([]types.OracleUpdateItem, 0, len(res.Oracles)),
+		UpdateData: make([][]string, 0, len(res.Oracles)),
+		Fee:        res.Fee.String(),
+		BuiltAt:    time.Now().UnixMilli(),
+	}
+	for _, oracle := range res.Oracles {
+		data.Oracles = append(data.Oracles, types.OracleUpdateItem{
+			OracleType: oracleTypeName(oracle.OracleType),
+			Address:    oracle.Address.Hex(),
+			Fee:        oracle.Fee.String(),
+		})
+		items := make([]string, 0, len(oracle.Data))
+		for _, item := range oracle.Data {
+			items = append(items, hexutil.Encode(item))
+		}
+		data.UpdateData = append(data.UpdateData, items)
+	}
+	return data, nil
+}
+
+// buildOracleUpdateData 构造调用getAggregatedPrice及买卖预估所需的预言机更新数据，
+// updateData与PriceAggregator的预言机列表一一对应，fee为需要随调用支付的更新费用
+func buildOracleUpdateData(ctx context.Context, svcCtx *svc.ServerCtx, symbol string) ([][][]byte, *big.Int, error) {
+	res, err := fetchOracleUpdate(ctx, svcCtx, symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.UpdateData(), res.Fee, nil
+}
+
+func fetchOracleUpdate(ctx context.Context, svcCtx *svc.ServerCtx, symbol string) (*oracleupdate.Result, error) {
+	if svcCtx.OracleUpdate == nil {
+		return nil, errors.New("price aggregator is not configured")
+	}
+
+	res, err := svcCtx.OracleUpdate.Build(ctx, symbol)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on build oracle update data")
+	}
+	return res, nil
+}
+
+func oracleTypeName(oracleType contract.OracleType) string {
+	switch oracleType {
+	case contract.OracleTypePyth:
+		return "pyth"
+	case contract.OracleTypeRedStone:
+		return "redstone"
+	}
+	return "unknown"
+}
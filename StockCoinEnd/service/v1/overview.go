@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	overviewSnapshotTimeout = 2 * time.Minute
+	maxOverviewPoints       = 500 // 走势最多返回的采样点数量，超过时按时间均匀抽样
+)
+
+// 支持的走势区间
+var overviewRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+}
+
+const DefaultOverviewRange = "30d"
+
+// StartOverviewSnapshot 定时记录平台总览快照（总市值、币股池价值、24小时链上成交额、股票数量）
+func StartOverviewSnapshot(svcCtx *svc.ServerCtx, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	takeSnapshot := func() {
+		if err := takeOverviewSnapshot(svcCtx, interval); err != nil {
+			log.Printf("记录平台总览快照失败: %v", err)
+		}
+	}
+
+	takeSnapshot()
+	for {
+		select {
+		case <-ticker.C:
+			takeSnapshot()
+		}
+	}
+}
+
+// takeOverviewSnapshot 任一查询失败都不写入快照，避免走势中出现错误的零值
+func takeOverviewSnapshot(svcCtx *svc.ServerCtx, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), overviewSnapshotTimeout)
+	defer cancel()
+
+	now := time.Now()
+	count, sumMarketCap, _, _, err := svcCtx.Dao.Count()
+	if err != nil {
+		return err
+	}
+
+	snapshot := &dao.OverviewSnapshot{
+		// 快照时间按间隔对齐，多个副本同时运行时只会写入一条
+		SnapshotAt:      now.Truncate(interval).UnixMilli(),
+		TotalMarketCap:  sumMarketCap,
+		PoolTokenValue:  decimal.Zero,
+		PoolUsdtReserve: decimal.Zero,
+		Volume24h:       decimal.Zero,
+		StockCount:      count,
+	}
+
+	// 未配置链上客户端时不记录币股池价值
+	if svcCtx.StockToken != nil {
+		stocks, err := svcCtx.Dao.ListAll()
+		if err != nil {
+			return errors.Wrap(err, "failed on list stocks")
+		}
+		prices := make(map[common.Address]decimal.Decimal)
+		tokens := make([]common.Address, 0, len(stocks))
+		for _, stock := range stocks {
+			if !common.IsHexAddress(stock.TokenAddress) {
+				continue
+			}
+			token := common.HexToAddress(stock.TokenAddress)
+			prices[token] = stock.CurrentPrice
+			tokens = append(tokens, token)
+		}
+
+		reserves, err := svcCtx.StockToken.GetReserves(ctx, tokens)
+		if err != nil {
+			return errors.Wrap(err, "failed on get pool reserves")
+		}
+		for token, reserve := range reserves {
+			tokenAmount := decimal.NewFromBigInt(reserve.TokenReserve, -stockTokenDecimals)
+			snapshot.PoolTokenValue = snapshot.PoolTokenValue.Add(tokenAmount.Mul(prices[token]))
+			snapshot.PoolUsdtReserve = snapshot.PoolUsdtReserve.Add(decimal.NewFromBigInt(reserve.USDTReserve, -usdtDecimals))
+		}
+	}
+
+	// 24小时链上成交额来自StockCoinSync汇总的成交统计
+	if snapshot.Volume24h, err = svcCtx.Dao.SumStockVolume24h(ctx, stockTokenChain(svcCtx)); err != nil {
+		return err
+	}
+
+	return svcCtx.Dao.CreateOverviewSnapshot(ctx, snapshot)
+}
+
+// GetOverviewHistory 获取平台总览走势，rangeStr为24h/7d/30d/90d/1y；
+// 金额按currency的当前汇率换算（不保存历史汇率）
+func GetOverviewHistory(ctx context.Context, svcCtx *svc.ServerCtx, rangeStr string, currency string) (*types.OverviewHistoryResp, error) {
+	duration, ok := overviewRanges[rangeStr]
+	if !ok {
+		return nil, errcode.NewCustomErr("unsupported range: " + rangeStr)
+	}
+	quote, err := GetFxQuote(ctx, svcCtx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now().UnixMilli()
+	from := to - duration.Milliseconds()
+	snapshots, err := svcCtx.Dao.QueryOverviewSnapshots(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	step := 1
+	if len(snapshots) > maxOverviewPoints {
+		step = (len(snapshots) + maxOverviewPoints - 1) / maxOverviewPoints
+	}
+	points := make([]types.OverviewHistoryPoint, 0, len(snapshots)/step+1)
+	for i := 0; i < len(snapshots); i += step {
+		// 抽样时保留最后一个点，保证走势末端是最新数据
+		if i+step >= len(snapshots) {
+			i = len(snapshots) - 1
+		}
+		snapshot := snapshots[i]
+		points = append(points, types.OverviewHistoryPoint{
+			Timestamp:       snapshot.SnapshotAt,
+			TotalMarketCap:  snapshot.TotalMarketCap.Mul(quote.Rate),
+			PoolTokenValue:  snapshot.PoolTokenValue.Mul(quote.Rate),
+			PoolUsdtReserve: snapshot.PoolUsdtReserve.Mul(quote.Rate),
+			Tvl:             snapshot.PoolTokenValue.Add(snapshot.PoolUsdtReserve).Mul(quote.Rate),
+			Volume24h:       snapshot.Volume24h.Mul(quote.Rate),
+			StockCount:      snapshot.StockCount,
+		})
+	}
+
+	return &types.OverviewHistoryResp{
+		Range:   rangeStr,
+		From:    from,
+		To:      to,
+		Points:  points,
+		FxQuote: *quote,
+	}, nil
+}
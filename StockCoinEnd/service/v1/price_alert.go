@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	maxPriceAlerts         = 50   // 每个用户最多的价格提醒数量
+	defaultAlertCooldown   = 3600 // 未指定冷却时间时的默认值（秒）
+	alertEvaluationTimeout = 30 * time.Second
+)
+
+var ErrPriceAlertNotFound = errcode.NewCustomErr("price alert not found")
+
+// alertQuote 一轮行情更新中单只股票用于评估提醒的数据
+type alertQuote struct {
+	prevPrice     decimal.Decimal // 本轮更新前的价格，用于判断是否穿越阈值
+	price         decimal.Decimal
+	changePercent float64 // 当日涨跌幅（百分比）
+	hasChange     bool    // 日线不足两根时无法计算涨跌幅
+	asOf          int64
+}
+
+// GetPriceAlerts 获取用户的全部价格提醒
+func GetPriceAlerts(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string) ([]types.PriceAlert, error) {
+	alerts, err := svcCtx.Dao.QueryPriceAlerts(ctx, userAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]types.PriceAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		res = append(res, toPriceAlert(alert))
+	}
+	return res, nil
+}
+
+// CreatePriceAlert 创建价格提醒
+func CreatePriceAlert(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, req *types.CreatePriceAlertReq) (*types.PriceAlert, error) {
+	if !req.Threshold.IsPositive() {
+		return nil, errcode.NewCustomErr("threshold must be positive")
+	}
+
+	ticker := strings.ToUpper(strings.TrimSpace(req.Ticker))
+	stocks, err := svcCtx.Dao.GetByTickers([]string{ticker})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query stocks")
+	}
+	if len(stocks) == 0 {
+		return nil, errcode.NewCustomErr("unknown ticker: " + ticker)
+	}
+
+	alerts, err := svcCtx.Dao.QueryPriceAlerts(ctx, userAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) >= maxPriceAlerts {
+		return nil, errcode.NewCustomErr(fmt.Sprintf("at most %d price alerts are allowed", maxPriceAlerts))
+	}
+
+	alert := &dao.PriceAlert{
+		UserAddress:     userAddress,
+		Ticker:          ticker,
+		Condition:       req.Condition,
+		Threshold:       req.Threshold,
+		Mode:            req.Mode,
+		CooldownSeconds: defaultAlertCooldown,
+		IsActive:        true,
+	}
+	if alert.Mode == "" {
+		alert.Mode = dao.AlertModeOnce
+	}
+	if req.CooldownSeconds != nil {
+		alert.CooldownSeconds = *req.CooldownSeconds
+	}
+	if err := svcCtx.Dao.CreatePriceAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	res := toPriceAlert(*alert)
+	return &res, nil
+}
+
+// SetPriceAlertActive 启用或停用价格提醒，一次性提醒触发后可重新启用
+func SetPriceAlertActive(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64, isActive bool) error {
+	alert, err := svcCtx.Dao.GetPriceAlert(ctx, userAddress, id)
+	if err != nil {
+		return err
+	}
+	if alert == nil {
+		return ErrPriceAlertNotFound
+	}
+
+	return svcCtx.Dao.SetPriceAlertActive(ctx, userAddress, id, isActive)
+}
+
+// DeletePriceAlert 删除价格提醒
+func DeletePriceAlert(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64) error {
+	alert, err := svcCtx.Dao.GetPriceAlert(ctx, userAddress, id)
+	if err != nil {
+		return err
+	}
+	if alert == nil {
+		return ErrPriceAlertNotFound
+	}
+
+	return svcCtx.Dao.DeletePriceAlert(ctx, userAddress, id)
+}
+
+// GetPriceAlertDeliveries 分页获取用户的提醒触发记录
+func GetPriceAlertDeliveries(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, page, pageSize int) (*types.PriceAlertDeliveriesResp, error) {
+	deliveries, count, err := svcCtx.Dao.QueryAlertDeliveries(ctx, userAddress, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.PriceAlertDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, types.PriceAlertDelivery{
+			ID:            delivery.ID,
+			AlertID:       delivery.AlertID,
+			Ticker:        delivery.Ticker,
+			Condition:     delivery.Condition,
+			Threshold:     delivery.Threshold,
+			Price:         delivery.Price,
+			ChangePercent: delivery.ChangePercent,
+			TriggeredAt:   delivery.TriggeredAt,
+			Status:        delivery.Status,
+		})
+	}
+
+	return &types.PriceAlertDeliveriesResp{Result: result, Count: count}, nil
+}
+
+// evaluatePriceAlerts 在行情写入后评估相关股票上启用中的提醒，触发的提醒生成待投递记录
+func evaluatePriceAlerts(svcCtx *svc.ServerCtx, quotes map[string]alertQuote) {
+	if len(quotes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertEvaluationTimeout)
+	defer cancel()
+
+	tickers := make([]string, 0, len(quotes))
+	for ticker := range quotes {
+		tickers = append(tickers, ticker)
+	}
+	alerts, err := svcCtx.Dao.QueryActivePriceAlerts(ctx, tickers)
+	if err != nil {
+		log.Printf("QueryActivePriceAlerts error: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range alerts {
+		alert := &alerts[i]
+		quote := quotes[alert.Ticker]
+		if !shouldTriggerAlert(alert, quote, now) {
+			continue
+		}
+
+		delivery := &dao.PriceAlertDelivery{
+			AlertID:       alert.ID,
+			UserAddress:   alert.UserAddress,
+			Ticker:        alert.Ticker,
+			Condition:     alert.Condition,
+			Threshold:     alert.Threshold,
+			Price:         quote.price,
+			ChangePercent: quote.changePercent,
+			TriggeredAt:   now.UnixMilli(),
+			Status:        dao.AlertDeliveryPending,
+		}
+		triggered, err := svcCtx.Dao.TriggerPriceAlert(ctx, alert, delivery)
+		if err != nil {
+			log.Printf("%s 价格提醒 %d 触发失败: %v", alert.Ticker, alert.ID, err)
+			continue
+		}
+		if triggered {
+			log.Printf("[价格提醒] %s %s %s 当前价格 %s 当日涨跌幅 %.2f%% 用户 %s",
+				alert.Ticker, alert.Condition, alert.Threshold, quote.price, quote.changePercent, alert.UserAddress)
+		}
+	}
+}
+
+// shouldTriggerAlert 判断提醒在本轮行情下是否满足触发条件
+func shouldTriggerAlert(alert *dao.PriceAlert, quote alertQuote, now time.Time) bool {
+	if !quote.price.IsPositive() {
+		return false
+	}
+	if alert.LastTriggeredAt > 0 && now.Sub(time.UnixMilli(alert.LastTriggeredAt)) < time.Duration(alert.CooldownSeconds)*time.Second {
+		return false
+	}
+
+	switch alert.Condition {
+	case dao.AlertConditionPriceAbove:
+		// 只在穿越时触发，价格持续高于阈值不会重复提醒
+		return quote.prevPrice.IsPositive() && quote.prevPrice.LessThan(alert.Threshold) && quote.price.GreaterThanOrEqual(alert.Threshold)
+	case dao.AlertConditionPriceBelow:
+		return quote.prevPrice.IsPositive() && quote.prevPrice.GreaterThan(alert.Threshold) && quote.price.LessThanOrEqual(alert.Threshold)
+	case dao.AlertConditionDayChange:
+		if !quote.hasChange || decimal.NewFromFloat(quote.changePercent).Abs().LessThan(alert.Threshold) {
+			return false
+		}
+		// 只评估当天的行情（休市时的旧数据不算当日涨跌），且同一交易日只提醒一次
+		day := nyDate(quote.asOf)
+		return day == nyDate(now.UnixMilli()) && (alert.LastTriggeredAt == 0 || nyDate(alert.LastTriggeredAt) != day)
+	}
+	return false
+}
+
+// dayChangePercent 最近一根日线相对前一交易日收盘的涨跌幅（百分比）
+func dayChangePercent(bars []marketdata.Bar) (float64, bool) {
+	n := len(bars)
+	if n < 2 || bars[n-2].Close <= 0 {
+		return 0, false
+	}
+	return (bars[n-1].Close - bars[n-2].Close) / bars[n-2].Close * 100, true
+}
+
+func nyDate(ts int64) string {
+	return time.UnixMilli(ts).In(timeUtil.NewYork()).Format(marketdata.DateLayout)
+}
+
+func toPriceAlert(alert dao.PriceAlert) types.PriceAlert {
+	return types.PriceAlert{
+		ID:              alert.ID,
+		Ticker:          alert.Ticker,
+		Condition:       alert.Condition,
+		Threshold:       alert.Threshold,
+		Mode:            alert.Mode,
+		CooldownSeconds: alert.CooldownSeconds,
+		IsActive:        alert.IsActive,
+		LastTriggeredAt: alert.LastTriggeredAt,
+		TriggerCount:    alert.TriggerCount,
+		CreatedAt:       alert.CreatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+)
+
+func TestShouldTriggerAlert(t *testing.T) {
+	// 纽约时间2024-03-06 10:00
+	now := time.Date(2024, time.March, 6, 15, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour).UnixMilli()
+	price := func(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+	tests := []struct {
+		name  string
+		alert dao.PriceAlert
+		quote alertQuote
+		want  bool
+	}{
+		{
+			name:  "price above crossing",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100")},
+			quote: alertQuote{prevPrice: price("99"), price: price("100")},
+			want:  true,
+		},
+		{
+			name:  "price above already above",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100")},
+			quote: alertQuote{prevPrice: price("101"), price: price("102")},
+		},
+		{
+			name:  "price above without previous price",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100")},
+			quote: alertQuote{price: price("102")},
+		},
+		{
+			name:  "price below crossing",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceBelow, Threshold: price("100")},
+			quote: alertQuote{prevPrice: price("101"), price: price("99.5")},
+			want:  true,
+		},
+		{
+			name:  "price below moving up",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceBelow, Threshold: price("100")},
+			quote: alertQuote{prevPrice: price("99"), price: price("99.5")},
+		},
+		{
+			name:  "zero price is ignored",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceBelow, Threshold: price("100")},
+			quote: alertQuote{prevPrice: price("101"), price: decimal.Zero},
+		},
+		{
+			name: "within cooldown",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100"),
+				CooldownSeconds: 3600, LastTriggeredAt: now.Add(-30 * time.Minute).UnixMilli()},
+			quote: alertQuote{prevPrice: price("99"), price: price("101")},
+		},
+		{
+			name: "after cooldown",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100"),
+				CooldownSeconds: 3600, LastTriggeredAt: now.Add(-2 * time.Hour).UnixMilli()},
+			quote: alertQuote{prevPrice: price("99"), price: price("101")},
+			want:  true,
+		},
+		{
+			name: "zero cooldown",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionPriceAbove, Threshold: price("100"),
+				CooldownSeconds: 0, LastTriggeredAt: now.Add(-time.Second).UnixMilli()},
+			quote: alertQuote{prevPrice: price("99"), price: price("101")},
+			want:  true,
+		},
+		{
+			name:  "day change over threshold",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5")},
+			quote: alertQuote{price: price("90"), changePercent: -6.5, hasChange: true, asOf: now.UnixMilli()},
+			want:  true,
+		},
+		{
+			name:  "day change under threshold",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5")},
+			quote: alertQuote{price: price("90"), changePercent: 4.9, hasChange: true, asOf: now.UnixMilli()},
+		},
+		{
+			name:  "day change without previous close",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5")},
+			quote: alertQuote{price: price("90"), asOf: now.UnixMilli()},
+		},
+		{
+			name:  "day change on stale quote",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5")},
+			quote: alertQuote{price: price("90"), changePercent: 8, hasChange: true, asOf: yesterday},
+		},
+		{
+			name: "day change already triggered today",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5"),
+				LastTriggeredAt: now.Add(-10 * time.Minute).UnixMilli()},
+			quote: alertQuote{price: price("90"), changePercent: 8, hasChange: true, asOf: now.UnixMilli()},
+		},
+		{
+			name: "day change triggered on a previous day",
+			alert: dao.PriceAlert{Condition: dao.AlertConditionDayChange, Threshold: price("5"),
+				LastTriggeredAt: yesterday},
+			quote: alertQuote{price: price("90"), changePercent: 8, hasChange: true, asOf: now.UnixMilli()},
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		if got := shouldTriggerAlert(&tt.alert, tt.quote, now); got != tt.want {
+			t.Errorf("%s: shouldTriggerAlert = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDayChangePercent(t *testing.T) {
+	if _, ok := dayChangePercent([]marketdata.Bar{{Close: 100}}); ok {
+		t.Errorf("expected no change with a single bar")
+	}
+	if _, ok := dayChangePercent([]marketdata.Bar{{Close: 0}, {Close: 100}}); ok {
+		t.Errorf("expected no change when the previous close is zero")
+	}
+	change, ok := dayChangePercent([]marketdata.Bar{{Close: 80}, {Close: 100}, {Close: 95}})
+	if !ok || change != -5 {
+		t.Errorf("dayChangePercent = %v, %v, want -5, true", change, ok)
+	}
+}
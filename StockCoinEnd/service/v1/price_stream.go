@@ -0,0 +1,68 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/stream"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+)
+
+// ParseStreamTickers 解析逗号分隔的订阅列表，统一为大写并去重
+func ParseStreamTickers(raw string) []string {
+	seen := make(map[string]bool)
+	tickers := make([]string, 0)
+	for _, ticker := range strings.Split(raw, ",") {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		tickers = append(tickers, ticker)
+	}
+	return tickers
+}
+
+// GetPriceSnapshot 建立推送连接时先返回订阅股票的当前价格
+func GetPriceSnapshot(svcCtx *svc.ServerCtx, tickers []string) ([]stream.PriceUpdate, error) {
+	stocks, err := svcCtx.Dao.GetByTickers(tickers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query stocks")
+	}
+
+	now := time.Now()
+	updates := make([]stream.PriceUpdate, 0, len(stocks))
+	for _, stock := range stocks {
+		if !stock.CurrentPrice.IsPositive() {
+			continue
+		}
+		updates = append(updates, toPriceUpdate(svcCtx, stock, now))
+	}
+	return updates, nil
+}
+
+// publishPriceUpdates 发布一轮行情更新，未开启推送时不做处理
+func publishPriceUpdates(svcCtx *svc.ServerCtx, updates []stream.PriceUpdate) {
+	if svcCtx.PriceHub == nil {
+		return
+	}
+	if err := svcCtx.PriceHub.Publish(updates); err != nil {
+		log.Printf("发布行情更新失败: %v", err)
+	}
+}
+
+func toPriceUpdate(svcCtx *svc.ServerCtx, stock dao.StockInfo, now time.Time) stream.PriceUpdate {
+	return stream.PriceUpdate{
+		Ticker:         stock.Ticker,
+		Price:          stock.CurrentPrice,
+		AsOf:           stock.PriceAsOf,
+		IsStale:        isQuoteStale(svcCtx.C.MarketData, stock.PriceAsOf, now),
+		AvgGain:        stock.AvgGain,
+		AvgGainPercent: stock.AvgGainPercent,
+		AvgVolume:      stock.AvgVolume,
+	}
+}
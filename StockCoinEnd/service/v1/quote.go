@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	DefaultSlippageBps = 100  // 默认滑点容忍度1%
+	MaxSlippageBps     = 1000 // 与合约maxSlippage的上限一致，最大10%
+)
+
+// GetStockQuote 以最新的预言机更新数据模拟调用getBuyEstimate/getSellEstimate，
+// 返回可得数量、手续费、价格及按滑点容忍度建议的minTokenAmount/minUsdtAmount
+func GetStockQuote(ctx context.Context, svcCtx *svc.ServerCtx, ticker, side string, amount decimal.Decimal, slippageBps int64) (*types.StockQuote, error) {
+	if svcCtx.StockToken == nil {
+		return nil, errors.New("chain reader is not configured")
+	}
+	if !amount.IsPositive() {
+		return nil, errcode.NewCustomErr("amount must be positive")
+	}
+
+	token, err := getStockTokenAddress(svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	updateData, updateFee, err := buildOracleUpdateData(ctx, svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &types.StockQuote{
+		Ticker:       ticker,
+		TokenAddress: token.Hex(),
+		Side:         side,
+		Amount:       amount,
+		SlippageBps:  slippageBps,
+		UpdateFee:    updateFee.String(),
+		QuotedAt:     time.Now().UnixMilli(),
+	}
+
+	var inputDecimals, outputDecimals int32
+	var estimate func(context.Context, common.Address, *big.Int, [][][]byte, *big.Int) (*big.Int, *big.Int, error)
+	switch side {
+	case types.QuoteSideBuy:
+		inputDecimals, outputDecimals = usdtDecimals, stockTokenDecimals
+		estimate = svcCtx.StockToken.GetBuyEstimate
+	case types.QuoteSideSell:
+		inputDecimals, outputDecimals = stockTokenDecimals, usdtDecimals
+		estimate = svcCtx.StockToken.GetSellEstimate
+	default:
+		return nil, errcode.NewCustomErr("unsupported side: " + side)
+	}
+
+	amountIn := amount.Shift(inputDecimals).Truncate(0).BigInt()
+	if amountIn.Sign() <= 0 {
+		return nil, errcode.NewCustomErr("amount is below token precision")
+	}
+	output, fee, err := estimate(ctx, token, amountIn, updateData, updateFee)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on estimate %s of %s", side, ticker)
+	}
+
+	applyQuoteEstimate(quote, output, fee, outputDecimals)
+
+	quote.Warnings = quoteWarnings(ctx, svcCtx, quote)
+	return quote, nil
+}
+
+// applyQuoteEstimate 由合约预估的可得数量及手续费（outputDecimals精度整数）计算预估结果中的数量、价格及min参数
+func applyQuoteEstimate(quote *types.StockQuote, output, fee *big.Int, outputDecimals int32) {
+	// 合约要求实际可得数量不低于min参数，按滑点容忍度向下取整
+	minOutput := new(big.Int).Mul(output, big.NewInt(10000-quote.SlippageBps))
+	minOutput.Quo(minOutput, big.NewInt(10000))
+
+	quote.ExpectedOutput = decimal.NewFromBigInt(output, -outputDecimals)
+	quote.Fee = decimal.NewFromBigInt(fee, -outputDecimals)
+	quote.MinOutput = decimal.NewFromBigInt(minOutput, -outputDecimals)
+
+	// 价格由扣费前的数量反推：买入 USDT/代币，卖出 USDT/代币
+	gross := quote.ExpectedOutput.Add(quote.Fee)
+	if quote.Side == types.QuoteSideBuy {
+		quote.MinTokenAmount = minOutput.String()
+		if gross.IsPositive() {
+			quote.Price = quote.Amount.Div(gross)
+		}
+	} else {
+		quote.MinUsdtAmount = minOutput.String()
+		quote.Price = gross.Div(quote.Amount)
+	}
+}
+
+// quoteWarnings 对照合约的交易参数及储备，提示按当前状态交易会revert的情况
+func quoteWarnings(ctx context.Context, svcCtx *svc.ServerCtx, quote *types.StockQuote) []string {
+	warnings := make([]string, 0)
+	info, err := GetStockTokenInfo(ctx, svcCtx, quote.Ticker)
+	if err != nil {
+		log.Printf("%s 获取代币交易参数失败: %v", quote.Ticker, err)
+		return warnings
+	}
+
+	if info.Paused {
+		warnings = append(warnings, "trading is paused")
+	}
+	gross := quote.ExpectedOutput.Add(quote.Fee)
+	if quote.Side == types.QuoteSideBuy {
+		if quote.Amount.LessThan(info.MinTradeAmount) {
+			warnings = append(warnings, "amount is below the minimum trade amount")
+		}
+		if info.TokenReserve.LessThan(gross) {
+			warnings = append(warnings, "insufficient token reserve in contract")
+		}
+	} else {
+		if quote.ExpectedOutput.LessThan(info.MinTradeAmount) {
+			warnings = append(warnings, "output is below the minimum trade amount")
+		}
+		if info.USDTReserve.LessThan(gross) {
+			warnings = append(warnings, "insufficient USDT reserve in contract")
+		}
+	}
+	return warnings
+}
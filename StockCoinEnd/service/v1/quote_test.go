@@ -0,0 +1,130 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+func TestApplyQuoteEstimate(t *testing.T) {
+	d := decimal.RequireFromString
+	n := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 10)
+		return v
+	}
+
+	tests := []struct {
+		name           string
+		side           string
+		amount         string
+		slippageBps    int64
+		output         *big.Int
+		fee            *big.Int
+		outputDecimals int32
+		expectedOutput string
+		wantFee        string
+		price          string
+		minOutput      string
+		minAmount      string
+	}{
+		{
+			// 100 USDT买入，可得0.99个代币，手续费0.01个代币
+			name:           "buy",
+			side:           types.QuoteSideBuy,
+			amount:         "100",
+			slippageBps:    100,
+			output:         n("990000000000000000"),
+			fee:            n("10000000000000000"),
+			outputDecimals: stockTokenDecimals,
+			expectedOutput: "0.99",
+			wantFee:        "0.01",
+			price:          "100",
+			minOutput:      "0.9801",
+			minAmount:      "980100000000000000",
+		},
+		{
+			// 卖出2个代币，可得199.4 USDT，手续费0.6 USDT
+			name:           "sell",
+			side:           types.QuoteSideSell,
+			amount:         "2",
+			slippageBps:    50,
+			output:         n("199400000"),
+			fee:            n("600000"),
+			outputDecimals: usdtDecimals,
+			expectedOutput: "199.4",
+			wantFee:        "0.6",
+			price:          "100",
+			minOutput:      "198.403",
+			minAmount:      "198403000",
+		},
+		{
+			// 999*9999/10000=998.9001，向下取整
+			name:           "slippage rounds down",
+			side:           types.QuoteSideSell,
+			amount:         "1",
+			slippageBps:    1,
+			output:         n("999"),
+			fee:            n("1"),
+			outputDecimals: usdtDecimals,
+			expectedOutput: "0.000999",
+			wantFee:        "0.000001",
+			price:          "0.001",
+			minOutput:      "0.000998",
+			minAmount:      "998",
+		},
+		{
+			name:           "zero slippage",
+			side:           types.QuoteSideBuy,
+			amount:         "50",
+			slippageBps:    0,
+			output:         n("500000000000000000"),
+			fee:            n("0"),
+			outputDecimals: stockTokenDecimals,
+			expectedOutput: "0.5",
+			wantFee:        "0",
+			price:          "100",
+			minOutput:      "0.5",
+			minAmount:      "500000000000000000",
+		},
+		{
+			// 可得数量为0时不计算买入价格
+			name:           "buy without output",
+			side:           types.QuoteSideBuy,
+			amount:         "50",
+			slippageBps:    100,
+			output:         n("0"),
+			fee:            n("0"),
+			outputDecimals: stockTokenDecimals,
+			expectedOutput: "0",
+			wantFee:        "0",
+			price:          "0",
+			minOutput:      "0",
+			minAmount:      "0",
+		},
+	}
+	for _, tt := range tests {
+		quote := &types.StockQuote{Side: tt.side, Amount: d(tt.amount), SlippageBps: tt.slippageBps}
+		applyQuoteEstimate(quote, tt.output, tt.fee, tt.outputDecimals)
+
+		if !quote.ExpectedOutput.Equal(d(tt.expectedOutput)) || !quote.Fee.Equal(d(tt.wantFee)) {
+			t.Errorf("%s: output/fee = %s/%s, want %s/%s", tt.name, quote.ExpectedOutput, quote.Fee, tt.expectedOutput, tt.wantFee)
+		}
+		if !quote.Price.Equal(d(tt.price)) {
+			t.Errorf("%s: price = %s, want %s", tt.name, quote.Price, tt.price)
+		}
+		if !quote.MinOutput.Equal(d(tt.minOutput)) {
+			t.Errorf("%s: min output = %s, want %s", tt.name, quote.MinOutput, tt.minOutput)
+		}
+
+		minAmount, other := quote.MinTokenAmount, quote.MinUsdtAmount
+		if tt.side == types.QuoteSideSell {
+			minAmount, other = quote.MinUsdtAmount, quote.MinTokenAmount
+		}
+		if minAmount != tt.minAmount || other != "" {
+			t.Errorf("%s: min token/usdt amount = %q/%q, want %s", tt.name, quote.MinTokenAmount, quote.MinUsdtAmount, tt.minAmount)
+		}
+	}
+}
@@ -2,119 +2,116 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"log"
 	"math/big"
-	"net/http"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/anyswap/CrossChain-Bridge/common"
-	"github.com/ethereum/go-ethereum"
-	common2 "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/locey/CryptoStock/StockCoinEnd/common/utils"
+	"github.com/ethereum/go-ethereum/common"
+	timeUtil "github.com/locey/CryptoStock/StockCoinBase/kit/time"
+	"github.com/locey/CryptoStock/StockCoinEnd/contract"
 	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/marketdata"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/stream"
 	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
-	"golang.org/x/exp/rand"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 const (
-	tokenFactoryAddress = "0xf5E1a44A68815fa627c1588e071fd089478aEB9C"
-	baseURL             = "https://api.polygon.io/v2/aggs/ticker"
-	timeout             = 10 * time.Second
-	companies           = "AAPL,TSLA,GOOGL,MSFT,AMZN,NVDA"
-	multiplier          = 1
-	timespan            = "day"
+	timeout            = 10 * time.Second
+	stockTokenDecimals = 18 // StockToken精度
+	registryTimeout    = 60 * time.Second
 )
 
-var (
-	apiKeys = []string{"rtJCR35B93aRr6gb6TvXqDmv_jc3DiNE", "itCVT8rqa0bWUc5TYcFs7XeDyAi2xfR7"}
-	// 使用map结构定义股票logo信息
-	stockLogoMap = map[string]string{
-		"AAPL":  "https://logo.clearbit.com/apple.com",
-		"TSLA":  "https://logo.clearbit.com/tesla.com",
-		"GOOGL": "https://logo.clearbit.com/google.com",
-		"MSFT":  "https://logo.clearbit.com/microsoft.com",
-		"AMZN":  "https://logo.clearbit.com/amazon.com",
-		"NVDA":  "https://logo.clearbit.com/nvidia.com",
-	}
-)
-
-type StockResponse struct {
-	Ticker       string `json:"ticker"`
-	QueryCount   int    `json:"queryCount"`
-	ResultsCount int    `json:"resultsCount"`
-	Adjusted     bool   `json:"adjusted"`
-	Results      []struct {
-		Volume    float64 `json:"v"`
-		Open      float64 `json:"o"`
-		Close     float64 `json:"c"`
-		High      float64 `json:"h"`
-		Low       float64 `json:"l"`
-		Timestamp int64   `json:"t"`
-		ItemCount int     `json:"n"`
-	} `json:"results"`
-}
-
 type StockSummary struct {
-	Ticker              string  `json:"ticker"`                 // 股票代码
-	Name                string  `json:"name"`                   //股票名称
-	Description         string  `json:"description"`            //股票描述
-	Logo                string  `json:"logo"`                   //股票logo地址
-	TokenAddress        string  `json:"token_address"`          //代币地址
-	AvgGain             float64 `json:"avg_gain"`               //7日平均涨幅
-	AvgGainPercent      float64 `json:"avg_gain_percent"`       //7日平均涨幅百分比
-	AvgVolume           float64 `json:"avg_volume"`             //7日平均成交量
-	CurrentPrice        float64 `json:"current_price"`          //当前价格
-	MarketCap           float64 `json:"market_cap"`             //市值
-	StockPoolTokenCount big.Int `json:"stock_pool_token_count"` //币股池代币量
-	StockPoolMarketCap  float64 `json:"stock_pool_market_cap"`  //币股池股票总市值
+	Ticker              string                  `json:"ticker"`                 // 股票代码
+	Name                string                  `json:"name"`                   //股票名称
+	Description         string                  `json:"description"`            //股票描述
+	Logo                string                  `json:"logo"`                   //股票logo地址
+	TokenAddress        string                  `json:"token_address"`          //代币地址
+	AvgGain             decimal.Decimal         `json:"avg_gain"`               //7日平均涨幅
+	AvgGainPercent      float64                 `json:"avg_gain_percent"`       //7日平均涨幅百分比
+	AvgVolume           float64                 `json:"avg_volume"`             //7日平均成交量
+	OnChain             types.StockOnChainStats `json:"on_chain"`               //链上成交统计（USDT）
+	CurrentPrice        decimal.Decimal         `json:"current_price"`          //当前价格
+	MarketCap           decimal.Decimal         `json:"market_cap"`             //市值
+	StockPoolTokenCount big.Int                 `json:"stock_pool_token_count"` //币股池代币量
+	StockPoolMarketCap  decimal.Decimal         `json:"stock_pool_market_cap"`  //币股池股票总市值
+	AsOf                int64                   `json:"as_of"`                  //当前价格对应的时间（毫秒）
+	IsStale             bool                    `json:"is_stale"`               //价格是否过期（如休市时为上一交易日收盘价之前的数据）
+}
 
+// StockPrice 股票价格及行情时间
+type StockPrice struct {
+	Ticker    string                  `json:"ticker"`
+	Price     decimal.Decimal         `json:"price"`
+	AsOf      int64                   `json:"as_of"`
+	IsStale   bool                    `json:"is_stale"`
+	AvgVolume float64                 `json:"avg_volume"` //7日平均成交量
+	OnChain   types.StockOnChainStats `json:"on_chain"`   //链上成交统计（USDT）
+	types.FxQuote
 }
 
 type StockOverview struct {
-	TotalMarketCap float64 `json:"total_market_cap"` //总市值
-	TotalVolume    float64 `json:"total_volume"`     //成交总量
-	StockCount     int64   `json:"stock_count"`      //股票总数
-	AvgGainPercent float64 `json:"avg_gain_percent"` //涨幅百分比
+	TotalMarketCap decimal.Decimal `json:"total_market_cap"` //总市值
+	TotalVolume    float64         `json:"total_volume"`     //成交总量
+	StockCount     int64           `json:"stock_count"`      //股票总数
+	AvgGainPercent float64         `json:"avg_gain_percent"` //涨幅百分比
+	types.FxQuote
 }
 
-type TickerResponse struct {
-	RequestID string `json:"request_id"`
-	Results   struct {
-		Ticker                      string  `json:"ticker"`
-		Name                        string  `json:"name"`
-		Description                 string  `json:"description"`
-		MarketCap                   float64 `json:"market_cap"`
-		ShareClassSharesOutstanding int64   `json:"share_class_shares_outstanding"`
-		CurrencyName                string  `json:"currency_name"`
-	} `json:"results"`
-	Status string `json:"status"`
+// GetStockList 按条件搜索、筛选、排序并分页查询股票列表，返回总数；价格、市值按currency换算
+func GetStockList(ctx context.Context, serverCtx *svc.ServerCtx, filter types.StockFilterParams, currency string) (*types.StockListResp, error) {
+	quote, err := GetFxQuote(ctx, serverCtx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, count, err := serverCtx.Dao.QueryStocks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := buildStockSummaries(ctx, serverCtx, stocks)
+	if err != nil {
+		return nil, err
+	}
+	convertStockSummaries(summaries, quote)
+
+	return &types.StockListResp{
+		Result:  summaries,
+		Count:   count,
+		FxQuote: *quote,
+	}, nil
 }
 
-func GetStockList(serverCtx *svc.ServerCtx, page int, pageNum int) ([]StockSummary, error) {
-	//分页查询
-	stocks, err := serverCtx.Dao.GetByPage(page, pageNum)
+// buildStockSummaries 将StockInfo转换为StockSummary，并补充链上发行量、链上成交统计及行情时间
+func buildStockSummaries(ctx context.Context, serverCtx *svc.ServerCtx, stocks []dao.StockInfo) ([]StockSummary, error) {
+	// 当前页的代币发行量通过一次multicall批量查询
+	supplies, err := getTotalSupplies(ctx, serverCtx, stocks)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		tickers = append(tickers, stock.Ticker)
+	}
+	onChainStats, err := getStockOnChainStats(ctx, serverCtx, tickers)
 	if err != nil {
 		return nil, err
 	}
+
 	//stocks转成[]StockSummary
-	stockSummaries := make([]StockSummary, 0)
+	now := time.Now()
+	stockSummaries := make([]StockSummary, 0, len(stocks))
 	for _, ticker := range stocks {
-		supply, err := GetTotalSupply(ticker.TokenAddress)
-		if err != nil {
-			return nil, err
-		}
-		//string转int64
 		stockPoolTokenCount := new(big.Int)
-		stockPoolTokenCount.SetString(supply, 10)
-		// 将big.Int转换为float64用于计算
-		stockPoolTokenCountFloat, _ := new(big.Float).SetInt(stockPoolTokenCount).Float64()
+		if supply, ok := supplies[ticker.TokenAddress]; ok {
+			stockPoolTokenCount.Set(supply)
+		}
 		stockSummary := StockSummary{
 			Ticker:              ticker.Ticker,
 			Name:                ticker.Name,
@@ -124,10 +121,13 @@ func GetStockList(serverCtx *svc.ServerCtx, page int, pageNum int) ([]StockSumma
 			AvgGain:             ticker.AvgGain,
 			AvgGainPercent:      ticker.AvgGainPercent,
 			AvgVolume:           ticker.AvgVolume,
+			OnChain:             onChainStats[strings.ToUpper(ticker.Ticker)],
 			CurrentPrice:        ticker.CurrentPrice,
 			MarketCap:           ticker.MarketCap,
 			StockPoolTokenCount: *stockPoolTokenCount,
-			StockPoolMarketCap:  ticker.CurrentPrice * stockPoolTokenCountFloat,
+			StockPoolMarketCap:  ticker.CurrentPrice.Mul(decimal.NewFromBigInt(stockPoolTokenCount, -stockTokenDecimals)),
+			AsOf:                ticker.PriceAsOf,
+			IsStale:             isQuoteStale(serverCtx.C.MarketData, ticker.PriceAsOf, now),
 		}
 		stockSummaries = append(stockSummaries, stockSummary)
 	}
@@ -135,71 +135,215 @@ func GetStockList(serverCtx *svc.ServerCtx, page int, pageNum int) ([]StockSumma
 	return stockSummaries, nil
 }
 
-// 定时获取股票信息
-func StartStockDataPoller(ctx *svc.ServerCtx, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// getTotalSupplies 批量查询代币发行量，key为数据库中的代币地址；未上链或地址无效的股票跳过
+func getTotalSupplies(ctx context.Context, svcCtx *svc.ServerCtx, stocks []dao.StockInfo) (map[string]*big.Int, error) {
+	supplies := make(map[string]*big.Int, len(stocks))
+	if svcCtx.ChainReader == nil {
+		return supplies, nil
+	}
+
+	tokens := make([]common.Address, 0, len(stocks))
+	for _, stock := range stocks {
+		if common.IsHexAddress(stock.TokenAddress) {
+			tokens = append(tokens, common.HexToAddress(stock.TokenAddress))
+		}
+	}
+	if len(tokens) == 0 {
+		return supplies, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	for {
-		select {
-		case <-ticker.C:
-			log.Println("开始更新股票数据...")
-			updateStockData(ctx)
+	result, err := svcCtx.ChainReader.TotalSupplies(ctx, tokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on batch get total supply")
+	}
+	for _, stock := range stocks {
+		if !common.IsHexAddress(stock.TokenAddress) {
+			continue
 		}
+		if supply, ok := result[common.HexToAddress(stock.TokenAddress)]; ok {
+			supplies[stock.TokenAddress] = supply
+		}
+	}
+	return supplies, nil
+}
+
+// 定时获取股票信息，拉取频率随美股交易时段调整
+func StartStockDataPoller(ctx *svc.ServerCtx) {
+	for {
+		log.Printf("开始更新股票数据（%s）...", timeUtil.NYSESession(time.Now()))
+		updateStockData(ctx)
+		time.Sleep(nextPollDelay(ctx.C.MarketData, time.Now()))
 	}
 }
 
 // 定时获取股票信息，市值需要更新（其它基础信息不需要更新）
+// 股票列表来自TokenFactory注册表：新上架的代币自动建档，已停用的代币标记为非活跃
 func Init(ctx *svc.ServerCtx) {
-	//通过abi接口获取股票对应的代币地址
-	symbols, err := GetSymbols("0x071304F5010BDdC9665c2666b6B930d7a60cf5bB")
+	if ctx.TokenFactory == nil {
+		log.Println("TokenFactory未配置，跳过股票列表同步")
+		return
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	tokens, err := getRegistryTokens(c, ctx)
+	if err != nil {
+		// 注册表读取失败时不能据此停用股票
+		log.Printf("getRegistryTokens error: %v", err)
+		return
+	}
+	if len(tokens) == 0 {
+		// 类别配置错误或工厂合约重新部署时注册表可能为空，此时停用会把所有股票标记为非活跃
+		log.Println("警告：TokenFactory注册表中没有活跃代币，跳过股票列表同步")
+		return
+	}
+
+	existing, err := ctx.Dao.ListAll()
 	if err != nil {
-		log.Println("GetSymbols error: %v", err)
+		log.Printf("ListAll error: %v", err)
+		return
 	}
+	existingStocks := make(map[string]dao.StockInfo, len(existing))
+	for _, stock := range existing {
+		existingStocks[stock.Ticker] = stock
+	}
+
 	stocks := make([]*dao.StockInfo, 0)
-	nowApiKey := GetApiKey("")
-	for i := range symbols {
-		data, err := GetStockBaseData(symbols[i], nowApiKey)
+	activeTickers := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		activeTickers = append(activeTickers, token.Symbol)
+		data, err := GetStockBaseData(ctx, token.Symbol)
 		if err != nil {
-			log.Println("GetStockBaseData error: %v,尝试第二次获取股票信息....", err)
-			nowApiKey = GetApiKey(nowApiKey)
-			data, err = GetStockBaseData(symbols[i], nowApiKey)
-			if err != nil {
-				log.Println("GetStockBaseData error: %v", err)
-				continue
+			log.Printf("GetStockBaseData error: %v", err)
+		}
+
+		logo := stockLogoURL(c, ctx, token.Symbol)
+		if existingStock, ok := existingStocks[token.Symbol]; ok {
+			if err := ctx.Dao.UpdateTokenRegistry(token.Symbol, token.TokenAddress.Hex(), token.Category.String(), true); err != nil {
+				log.Printf("UpdateTokenRegistry error: %v", err)
 			}
-			log.Println("第二次获取股票信息成功.....")
+			if logo != "" && existingStock.Logo != logo {
+				if err := ctx.Dao.UpdateLogo(token.Symbol, logo); err != nil {
+					log.Printf("UpdateLogo error: %v", err)
+				}
+			}
+			if data != nil {
+				if err := ctx.Dao.UpdateMarketCap(token.Symbol, decimal.NewFromFloat(data.MarketCap)); err != nil {
+					log.Printf("UpdateMarketCap error: %v", err)
+				}
+			}
+			continue
+		}
+
+		stock := &dao.StockInfo{
+			Ticker:       token.Symbol,
+			Name:         token.Symbol,
+			Logo:         logo,
+			TokenAddress: token.TokenAddress.Hex(),
+			Category:     token.Category.String(),
+			IsActive:     true,
+		}
+		if data != nil {
+			stock.Name = data.Name
+			stock.Description = data.Description
+			stock.MarketCap = decimal.NewFromFloat(data.MarketCap)
 		}
-		address, err := GetTokenAddress(tokenFactoryAddress, data.Results.Ticker)
+		stocks = append(stocks, stock)
+	}
+
+	if len(stocks) > 0 {
+		if err := ctx.Dao.BatchCreate(stocks); err != nil {
+			log.Printf("BatchCreate error: %v", err)
+		}
+		for _, stock := range stocks {
+			log.Printf("新增股票 %s (%s)", stock.Ticker, stock.TokenAddress)
+		}
+	}
+
+	// 已有股票也可能只有行情轮询写入的最近几天K线，已有完整历史的股票会被跳过
+	for _, ticker := range activeTickers {
+		backfillCandles(ctx, ticker)
+	}
+
+	if err := ctx.Dao.DeactivateExcept(activeTickers); err != nil {
+		log.Printf("DeactivateExcept error: %v", err)
+	}
+}
+
+// getRegistryTokens 读取TokenFactory中处于活跃状态、且属于配置类别的代币
+func getRegistryTokens(ctx context.Context, svcCtx *svc.ServerCtx) ([]*contract.TokenInfo, error) {
+	var symbols []string
+	if len(svcCtx.C.TokenFactory.Categories) == 0 {
+		activeSymbols, err := svcCtx.TokenFactory.GetActiveTokens(ctx)
 		if err != nil {
-			log.Println("GetTokenAddress error: %v", err)
+			return nil, err
 		}
-		stocks = append(stocks, &dao.StockInfo{
-			Ticker:       data.Results.Ticker,
-			Name:         data.Results.Name,
-			Description:  data.Results.Description,
-			Logo:         stockLogoMap[data.Results.Ticker],
-			TokenAddress: address.String(),
-			MarketCap:    data.Results.MarketCap,
-		})
+		symbols = activeSymbols
+	} else {
+		for _, name := range svcCtx.C.TokenFactory.Categories {
+			category, err := contract.ParseTokenCategory(name)
+			if err != nil {
+				return nil, err
+			}
+			categorySymbols, err := svcCtx.TokenFactory.GetTokensByCategory(ctx, category)
+			if err != nil {
+				return nil, err
+			}
+			symbols = append(symbols, categorySymbols...)
+		}
+	}
 
+	tokens := make([]*contract.TokenInfo, 0, len(symbols))
+	for _, symbol := range symbols {
+		info, err := svcCtx.TokenFactory.GetTokenInfo(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsActive {
+			tokens = append(tokens, info)
+		}
 	}
 
-	ctx.Dao.BatchCreate(stocks)
+	return tokens, nil
 }
 
 func updateStockData(serverCtx *svc.ServerCtx) {
-	client := &http.Client{Timeout: timeout}
-	stockCodes := strings.Split(companies, ",")
+	stockCodes, err := serverCtx.Dao.ListActiveTickers()
+	if err != nil {
+		log.Printf("ListActiveTickers error: %v", err)
+		return
+	}
+
+	// 涨幅统计需要按拆股复权，否则拆股会被算成大幅下跌
+	splits, err := serverCtx.Dao.QuerySplitsByTickers(context.Background(), stockCodes)
+	if err != nil {
+		log.Printf("QuerySplitsByTickers error: %v", err)
+		return
+	}
+
+	// 记录更新前的价格，用于判断价格提醒是否穿越阈值
+	prevPrices := make(map[string]decimal.Decimal, len(stockCodes))
+	if len(stockCodes) > 0 {
+		stocks, err := serverCtx.Dao.GetByTickers(stockCodes)
+		if err != nil {
+			log.Printf("GetByTickers error: %v", err)
+		}
+		for _, stock := range stocks {
+			prevPrices[stock.Ticker] = stock.CurrentPrice
+		}
+	}
 
 	var wg sync.WaitGroup
-	results := make(chan StockSummary, len(stockCodes))
+	results := make(chan stockFetchResult, len(stockCodes))
 
 	for _, code := range stockCodes {
 		wg.Add(1)
 		go func(ticker string) {
 			defer wg.Done()
-			fetchSingleStock(client, ticker, results)
+			fetchSingleStock(serverCtx.MarketData, ticker, splits[ticker], results)
 		}(code)
 	}
 
@@ -208,276 +352,151 @@ func updateStockData(serverCtx *svc.ServerCtx) {
 		close(results)
 	}()
 
+	quotes := make(map[string]alertQuote, len(stockCodes))
+	updates := make([]stream.PriceUpdate, 0, len(stockCodes))
+	now := time.Now()
 	for res := range results {
-		if res.Ticker != "" && res.CurrentPrice > 0 {
-			err := serverCtx.Dao.UpdateStockSummary(res.Ticker, res.AvgGain, res.AvgGainPercent, res.AvgVolume, res.CurrentPrice)
+		if len(res.bars) > 0 {
+			if err := saveCandles(context.Background(), serverCtx, res.summary.Ticker, res.bars); err != nil {
+				log.Printf("saveCandles error: %v", err)
+			}
+		}
+		if res.summary.Ticker != "" && res.summary.CurrentPrice.IsPositive() {
+			err := serverCtx.Dao.UpdateStockSummary(res.summary.Ticker, res.summary.AvgGain, res.summary.AvgGainPercent, res.summary.AvgVolume, res.summary.CurrentPrice, res.summary.AsOf)
 			if err != nil {
-				log.Println("UpdateStockSummary error: %v", err)
+				log.Printf("UpdateStockSummary error: %v", err)
+				continue
+			}
+			quotes[res.summary.Ticker] = alertQuote{
+				prevPrice:     prevPrices[res.summary.Ticker],
+				price:         res.summary.CurrentPrice,
+				changePercent: res.dayChange,
+				hasChange:     res.hasDayChange,
+				asOf:          res.summary.AsOf,
 			}
+			updates = append(updates, toPriceUpdate(serverCtx, dao.StockInfo{
+				Ticker:         res.summary.Ticker,
+				AvgGain:        res.summary.AvgGain,
+				AvgGainPercent: res.summary.AvgGainPercent,
+				AvgVolume:      res.summary.AvgVolume,
+				CurrentPrice:   res.summary.CurrentPrice,
+				PriceAsOf:      res.summary.AsOf,
+			}, now))
 		}
 	}
-}
-
-func fetchSingleStock(client *http.Client, ticker string, results chan<- StockSummary) {
-	// 保持原有请求逻辑，但添加重试机制
-	now := time.Now()
-	oneWeekAgo := now.AddDate(0, 0, -7)
-	fromDate := oneWeekAgo.Format("2006-01-02")
-	toDate := now.Format("2006-01-02")
-	maxRetries := 3
 
-	nowApiKey := GetApiKey("")
-	for i := 0; i < maxRetries; i++ {
-
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/range/%d/%s/%s/%s",
-			baseURL, ticker, multiplier, timespan, fromDate, toDate), nil)
-		if err != nil {
-			log.Printf("%s 请求创建失败 (尝试%d/%d): %v", ticker, i+1, maxRetries, err)
-			continue
-		}
-
-		q := req.URL.Query()
-		q.Add("apiKey", nowApiKey)
-		req.URL.RawQuery = q.Encode()
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("%s 请求执行失败 (尝试%d/%d): %v", ticker, i+1, maxRetries, err)
-			continue
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusTooManyRequests {
-			log.Printf("%s 触发了 API 速率限制 (尝试%d/%d)", ticker, i+1, maxRetries)
-			nowApiKey = GetApiKey(nowApiKey)
-			continue
-		}
-		if resp.StatusCode != http.StatusOK {
-			//log.Printf("%s 返回状态码: %d (尝试%d/%d)", ticker, resp.StatusCode, i+1, maxRetries)
-			continue
-		}
+	publishPriceUpdates(serverCtx, updates)
+	evaluatePriceAlerts(serverCtx, quotes)
+}
 
-		var result StockResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			log.Printf("%s 数据解析失败 (尝试%d/%d): %v", ticker, i+1, maxRetries, err)
-			continue
-		}
+// stockFetchResult 单只股票的拉取结果：汇总数据及原始日线
+type stockFetchResult struct {
+	summary      StockSummary
+	bars         []marketdata.Bar
+	dayChange    float64 // 复权后的当日涨跌幅（百分比）
+	hasDayChange bool
+}
 
-		//if len(result.Results) < 7 {
-		//	log.Printf("%s 数据不足7天 (尝试%d/%d)", ticker, i+1, maxRetries)
-		//	continue
-		//}
+func fetchSingleStock(provider marketdata.MarketDataProvider, ticker string, splits []dao.CorporateAction, results chan<- stockFetchResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-		summary := calculate7DayStats(ticker, result.Results)
-		results <- summary
+	// 按交易日而非自然日回溯，跳过周末和节假日
+	now := time.Now()
+	bars, err := provider.GetDailyAggregates(ctx, ticker, timeUtil.AddNYSETradingDays(now, -statsTradingDays), now)
+	if err != nil || len(bars) == 0 {
+		log.Printf("%s 获取失败: %v", ticker, err)
+		results <- stockFetchResult{summary: StockSummary{Ticker: ticker}} // 发送空数据表示失败
 		return
 	}
 
-	log.Printf("%s 获取失败 (最大重试次数已达)", ticker)
-	results <- StockSummary{Ticker: ticker} // 发送空数据表示失败
+	adjusted := adjustBarsForSplits(bars, splits, now.UnixMilli())
+	summary := calculate7DayStats(ticker, adjusted)
+	summary.AsOf = quoteAsOf(bars[len(bars)-1], now).UnixMilli()
+	dayChange, hasDayChange := dayChangePercent(adjusted)
+	results <- stockFetchResult{summary: summary, bars: bars, dayChange: dayChange, hasDayChange: hasDayChange}
 }
 
-func calculate7DayStats(ticker string, data []struct {
-	Volume    float64 `json:"v"`
-	Open      float64 `json:"o"`
-	Close     float64 `json:"c"`
-	High      float64 `json:"h"`
-	Low       float64 `json:"l"`
-	Timestamp int64   `json:"t"`
-	ItemCount int     `json:"n"`
-}) StockSummary {
-	var totalGain, totalGainPercent, totalVolume float64
+func calculate7DayStats(ticker string, data []marketdata.Bar) StockSummary {
+	var totalGainPercent, totalVolume float64
+	totalGain := decimal.Zero
 	days := len(data)
+	if days < 2 {
+		return StockSummary{Ticker: ticker, CurrentPrice: decimal.NewFromFloat(data[days-1].Close)}
+	}
 
 	for i := 1; i < days; i++ {
-		prevClose := data[i-1].Close
-		currentClose := data[i].Close
-		gain := currentClose - prevClose
-		gainPercent := (gain / prevClose) * 100
+		prevClose := decimal.NewFromFloat(data[i-1].Close)
+		currentClose := decimal.NewFromFloat(data[i].Close)
+		gain := currentClose.Sub(prevClose)
+		gainPercent, _ := gain.Div(prevClose).Mul(decimal.NewFromInt(100)).Float64()
 
-		totalGain += gain
+		totalGain = totalGain.Add(gain)
 		totalGainPercent += gainPercent
 		totalVolume += data[i].Volume
 	}
 
 	return StockSummary{
 		Ticker:         ticker,
-		AvgGain:        totalGain / float64(days-1),
+		AvgGain:        totalGain.Div(decimal.NewFromInt(int64(days - 1))),
 		AvgGainPercent: totalGainPercent / float64(days-1),
 		AvgVolume:      totalVolume / float64(days-1),
-		CurrentPrice:   data[days-1].Close,
-	}
-}
-
-// 获取apiKey，传入非空的apiKey或者空字符串，返回一个新的apiKey
-func GetApiKey(apiKey string) string {
-	if apiKey == "" {
-		return apiKeys[0]
-	}
-	apiKeyIdx := rand.Intn(len(apiKeys))
-	for {
-		if apiKeys[apiKeyIdx] == apiKey {
-			apiKeyIdx = rand.Intn(len(apiKeys))
-		} else {
-			return apiKeys[apiKeyIdx]
-		}
+		CurrentPrice:   decimal.NewFromFloat(data[days-1].Close),
 	}
 }
 
-func GetSymbols(address string) ([]string, error) {
-	// 1. 连接以太坊节点
-	client, err := ethclient.Dial("https://ethereum-sepolia-rpc.publicnode.com")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Construct the path to the ABI file
-	abiPath := filepath.Join("..", "CryptoStockContract", "abi", "PriceAggregator.abi")
-	parsedABI, err := utils.ReadABI(abiPath)
-	if err != nil {
-		log.Fatal("ABI解析错误:", err)
-	}
-
-	// 使用正确的合约地址
-	contractAddress := common.HexToAddress(address) // OracleAggregator代理合约地址
-
-	// 3. 构造调用数据
-	data, err := parsedABI.Pack("getSupportedSymbols")
-	if err != nil {
-		log.Fatal("数据打包错误:", err)
-	}
-
-	result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   (*common2.Address)(&contractAddress),
-		Data: data,
-	}, nil)
-	if err != nil {
-		log.Fatal("合约调用错误:", err)
+// GetStockBaseData 通过行情数据源获取股票名称、描述、市值等基础信息
+func GetStockBaseData(svcCtx *svc.ServerCtx, ticker string) (*marketdata.TickerDetails, error) {
+	if svcCtx.MarketData == nil {
+		return nil, errors.New("market data provider is not configured")
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// 5. 解析返回数据
-	var supportedSymbols []string
-	err = parsedABI.UnpackIntoInterface(&supportedSymbols, "getSupportedSymbols", result)
-	if err != nil {
-		log.Fatal("数据解包错误:", err)
-	}
-	return supportedSymbols, nil
+	return svcCtx.MarketData.GetTickerDetails(ctx, ticker)
 }
 
-func GetTotalSupply(address string) (string, error) {
-	// 1. 连接以太坊节点
-	client, err := ethclient.Dial("https://ethereum-sepolia-rpc.publicnode.com")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 2. 解析ABI
-	// Construct the path to the ABI file
-	abiPath := filepath.Join("..", "CryptoStockContract", "abi", "StockToken.abi")
-	parsedABI, err := utils.ReadABI(abiPath)
-	if err != nil {
-		log.Fatal("ABI解析错误:", err)
-	}
-
-	// 3. 构造调用数据
-	data, err := parsedABI.Pack("totalSupply")
-	if err != nil {
-		log.Fatal("数据打包错误:", err)
-	}
-
-	// 4. 执行call操作
-	contractAddress := common.HexToAddress(address)
-	result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   (*common2.Address)(&contractAddress),
-		Data: data,
-	}, nil)
-	if err != nil {
-		log.Fatal("合约调用错误:", err)
-	}
-
-	// 5. 解析返回数据
-	var supply *big.Int
-	err = parsedABI.UnpackIntoInterface(&supply, "totalSupply", result)
-	if err != nil {
-		log.Fatal("数据解包错误:", err)
-	}
-
-	fmt.Printf("Total Supply: %s\n", supply.String())
-	return supply.String(), nil
-}
-func GetTokenAddress(tokenFactoryAddress string, symbol string) (common.Address, error) {
-	client, err := ethclient.Dial("https://ethereum-sepolia-rpc.publicnode.com")
-	if err != nil {
-		log.Fatal(err)
-	}
-	// Construct the path to the ABI file
-	abiPath := filepath.Join("..", "CryptoStockContract", "abi", "TokenFactory.abi")
-	parsedABI, err := utils.ReadABI(abiPath)
-	if err != nil {
-		log.Fatal(err)
-	}
-	pack, err := parsedABI.Pack("getTokenAddress", symbol)
-	if err != nil {
-		log.Fatal(err)
-	}
-	contractAddress := common.HexToAddress(tokenFactoryAddress)
-	result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   (*common2.Address)(&contractAddress),
-		Data: pack,
-	}, nil)
+func GetOverview(ctx context.Context, svcCtx *svc.ServerCtx, currency string) (StockOverview, error) {
+	quote, err := GetFxQuote(ctx, svcCtx, currency)
 	if err != nil {
-		log.Fatal(err)
-	}
-	//解析返回数据
-	var token common.Address
-	err = parsedABI.UnpackIntoInterface(&token, "getTokenAddress", result)
-	if err != nil {
-		log.Fatal("数据解包错误:", err)
-	}
-	return token, nil
-
-}
-
-func GetStockBaseData(ticker string, apiKey string) (*TickerResponse, error) {
-	url := fmt.Sprintf("https://api.polygon.io/v3/reference/tickers/%s?apiKey=%s", ticker, apiKey)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("API错误: %s - %s", resp.Status)
-	}
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API错误: %s - %s", resp.Status, string(body))
-	}
-
-	var result TickerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("JSON解析失败: %w", err)
+		return StockOverview{}, err
 	}
 
-	return &result, nil
-}
-
-func GetOverview(svcCtx *svc.ServerCtx) (StockOverview, error) {
 	count, sumMarketCap, sumVolume, avgGainPercent, err := svcCtx.Dao.Count()
 	if err != nil {
 		return StockOverview{}, err
 	}
 	return StockOverview{
-		TotalMarketCap: sumMarketCap,
+		TotalMarketCap: sumMarketCap.Mul(quote.Rate),
 		TotalVolume:    sumVolume,
 		StockCount:     count,
 		AvgGainPercent: avgGainPercent,
+		FxQuote:        *quote,
 	}, nil
 }
 
-// 通过股票代码获取股票价格
-func GetStockPrice(svcCtx *svc.ServerCtx, stockCode string) (float64, error) {
+// 通过股票代码获取股票价格，价格按currency换算
+func GetStockPrice(ctx context.Context, svcCtx *svc.ServerCtx, stockCode string, currency string) (*StockPrice, error) {
+	quote, err := GetFxQuote(ctx, svcCtx, currency)
+	if err != nil {
+		return nil, err
+	}
+
 	ticker, err := svcCtx.Dao.GetByTicker(stockCode)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return ticker.CurrentPrice, nil
+	onChainStats, err := getStockOnChainStats(ctx, svcCtx, []string{ticker.Ticker})
+	if err != nil {
+		return nil, err
+	}
+	return &StockPrice{
+		Ticker:    ticker.Ticker,
+		Price:     ticker.CurrentPrice.Mul(quote.Rate),
+		AsOf:      ticker.PriceAsOf,
+		IsStale:   isQuoteStale(svcCtx.C.MarketData, ticker.PriceAsOf, time.Now()),
+		AvgVolume: ticker.AvgVolume,
+		OnChain:   onChainStats[strings.ToUpper(ticker.Ticker)],
+		FxQuote:   *quote,
+	}, nil
 }
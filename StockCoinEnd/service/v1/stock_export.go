@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const priceDecimals = 18 // 合约事件中价格的精度
+
+// stockLot 一个买入批次的剩余数量及成本
+type stockLot struct {
+	quantity decimal.Decimal
+	cost     decimal.Decimal
+	time     int64
+	txHash   string
+}
+
+// lotBook 单个股票的买入批次，卖出时按method结转成本；平均成本方式下所有买入合并为一个批次
+type lotBook struct {
+	method string
+	lots   []stockLot
+}
+
+func (b *lotBook) buy(lot stockLot) {
+	if b.method == types.LotMethodAverage {
+		if len(b.lots) == 0 {
+			b.lots = append(b.lots, stockLot{})
+		}
+		b.lots[0].quantity = b.lots[0].quantity.Add(lot.quantity)
+		b.lots[0].cost = b.lots[0].cost.Add(lot.cost)
+		return
+	}
+	b.lots = append(b.lots, lot)
+}
+
+// sell 卖出quantity并返回对应到的批次（数量及成本为本次结转的部分），以及超出已有批次、没有成本的数量
+func (b *lotBook) sell(quantity decimal.Decimal) ([]stockLot, decimal.Decimal) {
+	var matched []stockLot
+	remaining := quantity
+	for remaining.IsPositive() && len(b.lots) > 0 {
+		i := 0
+		if b.method == types.LotMethodLIFO {
+			i = len(b.lots) - 1
+		}
+		lot := &b.lots[i]
+
+		take := decimal.Min(remaining, lot.quantity)
+		cost := lot.cost
+		if take.LessThan(lot.quantity) {
+			cost = lot.cost.Mul(take).Div(lot.quantity)
+		}
+		matched = append(matched, stockLot{quantity: take, cost: cost, time: lot.time, txHash: lot.txHash})
+
+		lot.quantity = lot.quantity.Sub(take)
+		lot.cost = lot.cost.Sub(cost)
+		remaining = remaining.Sub(take)
+		if !lot.quantity.IsPositive() {
+			b.lots = append(b.lots[:i], b.lots[i+1:]...)
+		}
+	}
+	return matched, remaining
+}
+
+func stockTradeSide(activityType int) string {
+	if activityType == stockmodel.Sell {
+		return "sell"
+	}
+	return "buy"
+}
+
+// GetStockTrades 获取userAddrs在[from, to]内的所有股票代币买卖记录（数据来自StockCoinSync同步的买卖事件），按成交顺序排列
+func GetStockTrades(ctx context.Context, svcCtx *svc.ServerCtx, userAddrs []string, from, to time.Time) (*types.StockTradesResp, error) {
+	activities, err := svcCtx.Dao.QueryStockActivities(ctx, stockTokenChain(svcCtx), userAddrs, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]types.StockTrade, 0, len(activities))
+	for _, activity := range activities {
+		trades = append(trades, types.StockTrade{
+			Time:         activity.EventTime * 1000,
+			Side:         stockTradeSide(activity.ActivityType),
+			Ticker:       strings.ToUpper(activity.TokenSymbol),
+			TokenAddress: activity.TokenAddress,
+			UserAddress:  strings.ToLower(activity.UserAddress),
+			Quantity:     activity.TokenAmount.Shift(-stockTokenDecimals),
+			Price:        activity.CurrentPrice.Shift(-priceDecimals),
+			Amount:       activity.CurrencyAmount.Shift(-usdtDecimals),
+			Fee:          activity.FeeAmount.Shift(-usdtDecimals),
+			BlockNumber:  activity.BlockNumber,
+			TxHash:       activity.TxHash,
+		})
+	}
+
+	return &types.StockTradesResp{
+		Addresses: userAddrs,
+		From:      from.UnixMilli(),
+		To:        to.UnixMilli(),
+		Trades:    trades,
+	}, nil
+}
+
+// GetStockRealizedGains 按method结转成本，计算userAddrs在[from, to]内卖出的已实现收益；
+// 多个地址视为同一账户合并计算，from之前的买卖同样参与成本结转。买入成本包含手续费，卖出所得为扣除手续费后的USDT
+func GetStockRealizedGains(ctx context.Context, svcCtx *svc.ServerCtx, userAddrs []string, method string, from, to time.Time) (*types.StockRealizedGainsResp, error) {
+	switch method {
+	case types.LotMethodFIFO, types.LotMethodLIFO, types.LotMethodAverage:
+	default:
+		return nil, errcode.NewCustomErr("unsupported lot method: " + method)
+	}
+
+	activities, err := svcCtx.Dao.QueryStockActivities(ctx, stockTokenChain(svcCtx), userAddrs, 0, to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := realizedGains(activities, method, from.Unix())
+	resp.Addresses = userAddrs
+	resp.From = from.UnixMilli()
+	resp.To = to.UnixMilli()
+	return resp, nil
+}
+
+// realizedGains 按成交顺序重放activities结转成本，from（秒）之前的卖出只结转成本，不计入收益
+func realizedGains(activities []stockmodel.Activity, method string, from int64) *types.StockRealizedGainsResp {
+	resp := &types.StockRealizedGainsResp{
+		Method:            method,
+		Gains:             []types.StockRealizedGain{},
+		TotalProceeds:     decimal.Zero,
+		TotalCostBasis:    decimal.Zero,
+		TotalGain:         decimal.Zero,
+		UnmatchedQuantity: map[string]decimal.Decimal{},
+	}
+	books := make(map[string]*lotBook)
+	for _, activity := range activities {
+		ticker := strings.ToUpper(activity.TokenSymbol)
+		book, ok := books[ticker]
+		if !ok {
+			book = &lotBook{method: method}
+			books[ticker] = book
+		}
+		quantity := activity.TokenAmount.Shift(-stockTokenDecimals)
+		amount := activity.CurrencyAmount.Shift(-usdtDecimals)
+		if !quantity.IsPositive() {
+			continue
+		}
+
+		if activity.ActivityType == stockmodel.Buy {
+			book.buy(stockLot{quantity: quantity, cost: amount, time: activity.EventTime * 1000, txHash: activity.TxHash})
+			continue
+		}
+
+		matched, unmatched := book.sell(quantity)
+		if activity.EventTime < from {
+			continue
+		}
+		for _, lot := range matched {
+			gain := types.StockRealizedGain{
+				Ticker:       ticker,
+				Quantity:     lot.quantity,
+				AcquiredTime: lot.time,
+				AcquiredTx:   lot.txHash,
+				DisposedTime: activity.EventTime * 1000,
+				DisposedTx:   activity.TxHash,
+				Proceeds:     amount.Mul(lot.quantity).Div(quantity),
+				CostBasis:    lot.cost,
+			}
+			gain.Gain = gain.Proceeds.Sub(gain.CostBasis)
+			resp.Gains = append(resp.Gains, gain)
+			resp.TotalProceeds = resp.TotalProceeds.Add(gain.Proceeds)
+			resp.TotalCostBasis = resp.TotalCostBasis.Add(gain.CostBasis)
+			resp.TotalGain = resp.TotalGain.Add(gain.Gain)
+		}
+		if unmatched.IsPositive() {
+			resp.UnmatchedQuantity[ticker] = resp.UnmatchedQuantity[ticker].Add(unmatched)
+		}
+	}
+	return resp
+}
+
+// formatExportTime 将毫秒时间戳格式化为UTC时间，0为空
+func formatExportTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+// WriteStockTradesCSV 将买卖记录写为CSV
+func WriteStockTradesCSV(w io.Writer, trades []types.StockTrade) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"time", "side", "ticker", "token_address", "user_address", "quantity", "price", "amount", "fee", "block_number", "tx_hash"}); err != nil {
+		return errors.Wrap(err, "failed on write csv")
+	}
+	for _, trade := range trades {
+		if err := writer.Write([]string{
+			formatExportTime(trade.Time),
+			trade.Side,
+			trade.Ticker,
+			trade.TokenAddress,
+			trade.UserAddress,
+			trade.Quantity.String(),
+			trade.Price.String(),
+			trade.Amount.String(),
+			trade.Fee.String(),
+			strconv.FormatInt(trade.BlockNumber, 10),
+			trade.TxHash,
+		}); err != nil {
+			return errors.Wrap(err, "failed on write csv")
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "failed on write csv")
+}
+
+// WriteStockRealizedGainsCSV 将已实现收益写为CSV：type为gain的明细行、total合计行，
+// 以及每个股票一行unmatched（卖出超出已有买入、没有成本的数量）
+func WriteStockRealizedGainsCSV(w io.Writer, resp *types.StockRealizedGainsResp) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"type", "ticker", "quantity", "acquired_time", "acquired_tx", "disposed_time", "disposed_tx", "proceeds", "cost_basis", "gain"}); err != nil {
+		return errors.Wrap(err, "failed on write csv")
+	}
+	for _, gain := range resp.Gains {
+		if err := writer.Write([]string{
+			"gain",
+			gain.Ticker,
+			gain.Quantity.String(),
+			formatExportTime(gain.AcquiredTime),
+			gain.AcquiredTx,
+			formatExportTime(gain.DisposedTime),
+			gain.DisposedTx,
+			gain.Proceeds.String(),
+			gain.CostBasis.String(),
+			gain.Gain.String(),
+		}); err != nil {
+			return errors.Wrap(err, "failed on write csv")
+		}
+	}
+	if err := writer.Write([]string{"total", "", "", "", "", "", "", resp.TotalProceeds.String(), resp.TotalCostBasis.String(), resp.TotalGain.String()}); err != nil {
+		return errors.Wrap(err, "failed on write csv")
+	}
+
+	tickers := make([]string, 0, len(resp.UnmatchedQuantity))
+	for ticker := range resp.UnmatchedQuantity {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	for _, ticker := range tickers {
+		if err := writer.Write([]string{"unmatched", ticker, resp.UnmatchedQuantity[ticker].String(), "", "", "", "", "", "", ""}); err != nil {
+			return errors.Wrap(err, "failed on write csv")
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "failed on write csv")
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	stockmodel "github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+// testActivity 构造一条买卖记录，quantity为代币数量，amount、fee为USDT数量（均为展示精度）
+func testActivity(activityType int, ticker, quantity, amount, fee string, eventTime int64, txHash string) stockmodel.Activity {
+	return stockmodel.Activity{
+		ActivityType:   activityType,
+		TokenSymbol:    ticker,
+		TokenAmount:    decimal.RequireFromString(quantity).Shift(stockTokenDecimals),
+		CurrencyAmount: decimal.RequireFromString(amount).Shift(usdtDecimals),
+		FeeAmount:      decimal.RequireFromString(fee).Shift(usdtDecimals),
+		EventTime:      eventTime,
+		TxHash:         txHash,
+	}
+}
+
+type expectedGain struct {
+	ticker     string
+	quantity   string
+	acquiredTx string
+	disposedTx string
+	proceeds   string
+	costBasis  string
+}
+
+func TestRealizedGains(t *testing.T) {
+	// 两次买入后部分卖出：第二个批次只结转一部分
+	partialLots := []stockmodel.Activity{
+		testActivity(stockmodel.Buy, "aapl", "10", "1000", "3", 100, "b1"),
+		testActivity(stockmodel.Buy, "AAPL", "10", "1200", "3.6", 200, "b2"),
+		testActivity(stockmodel.Sell, "AAPL", "15", "1800", "5.4", 300, "s1"),
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		from       int64
+		activities []stockmodel.Activity
+		gains      []expectedGain
+		proceeds   string
+		costBasis  string
+		gain       string
+		unmatched  map[string]string
+	}{
+		{
+			name:       "fifo partial lots",
+			method:     types.LotMethodFIFO,
+			activities: partialLots,
+			gains: []expectedGain{
+				{"AAPL", "10", "b1", "s1", "1200", "1000"},
+				{"AAPL", "5", "b2", "s1", "600", "600"},
+			},
+			proceeds:  "1800",
+			costBasis: "1600",
+			gain:      "200",
+		},
+		{
+			name:       "lifo partial lots",
+			method:     types.LotMethodLIFO,
+			activities: partialLots,
+			gains: []expectedGain{
+				{"AAPL", "10", "b2", "s1", "1200", "1200"},
+				{"AAPL", "5", "b1", "s1", "600", "500"},
+			},
+			proceeds:  "1800",
+			costBasis: "1700",
+			gain:      "100",
+		},
+		{
+			name:       "average cost merges lots",
+			method:     types.LotMethodAverage,
+			activities: partialLots,
+			gains: []expectedGain{
+				{"AAPL", "15", "", "s1", "1800", "1650"},
+			},
+			proceeds:  "1800",
+			costBasis: "1650",
+			gain:      "150",
+		},
+		{
+			// 买入金额已包含手续费、卖出金额已扣除手续费，FeeAmount不再重复计算
+			name:   "fees are already included in usdt amounts",
+			method: types.LotMethodFIFO,
+			activities: []stockmodel.Activity{
+				testActivity(stockmodel.Buy, "TSLA", "2", "503", "3", 100, "b1"),
+				testActivity(stockmodel.Sell, "TSLA", "2", "597", "3", 200, "s1"),
+			},
+			gains: []expectedGain{
+				{"TSLA", "2", "b1", "s1", "597", "503"},
+			},
+			proceeds:  "597",
+			costBasis: "503",
+			gain:      "94",
+		},
+		{
+			name:   "sell larger than holdings",
+			method: types.LotMethodFIFO,
+			activities: []stockmodel.Activity{
+				testActivity(stockmodel.Buy, "NVDA", "5", "500", "0", 100, "b1"),
+				testActivity(stockmodel.Sell, "NVDA", "8", "960", "0", 200, "s1"),
+			},
+			gains: []expectedGain{
+				{"NVDA", "5", "b1", "s1", "600", "500"},
+			},
+			proceeds:  "600",
+			costBasis: "500",
+			gain:      "100",
+			unmatched: map[string]string{"NVDA": "3"},
+		},
+		{
+			// from之前的卖出消耗批次但不计入收益
+			name:   "sell before from",
+			method: types.LotMethodFIFO,
+			from:   200,
+			activities: []stockmodel.Activity{
+				testActivity(stockmodel.Buy, "MSFT", "10", "1000", "0", 100, "b1"),
+				testActivity(stockmodel.Sell, "MSFT", "4", "480", "0", 150, "s1"),
+				testActivity(stockmodel.Buy, "MSFT", "10", "1500", "0", 160, "b2"),
+				testActivity(stockmodel.Sell, "MSFT", "8", "1200", "0", 300, "s2"),
+			},
+			gains: []expectedGain{
+				{"MSFT", "6", "b1", "s2", "900", "600"},
+				{"MSFT", "2", "b2", "s2", "300", "300"},
+			},
+			proceeds:  "1200",
+			costBasis: "900",
+			gain:      "300",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := realizedGains(tt.activities, tt.method, tt.from)
+
+			if len(resp.Gains) != len(tt.gains) {
+				t.Fatalf("expected %d gains, got %d", len(tt.gains), len(resp.Gains))
+			}
+			for i, want := range tt.gains {
+				got := resp.Gains[i]
+				if got.Ticker != want.ticker || got.AcquiredTx != want.acquiredTx || got.DisposedTx != want.disposedTx {
+					t.Errorf("gain %d: got %s %s->%s, want %s %s->%s", i, got.Ticker, got.AcquiredTx, got.DisposedTx, want.ticker, want.acquiredTx, want.disposedTx)
+				}
+				assertDecimal(t, "quantity", got.Quantity, want.quantity)
+				assertDecimal(t, "proceeds", got.Proceeds, want.proceeds)
+				assertDecimal(t, "cost basis", got.CostBasis, want.costBasis)
+				assertDecimal(t, "gain", got.Gain, decimal.RequireFromString(want.proceeds).Sub(decimal.RequireFromString(want.costBasis)).String())
+			}
+			assertDecimal(t, "total proceeds", resp.TotalProceeds, tt.proceeds)
+			assertDecimal(t, "total cost basis", resp.TotalCostBasis, tt.costBasis)
+			assertDecimal(t, "total gain", resp.TotalGain, tt.gain)
+
+			if len(resp.UnmatchedQuantity) != len(tt.unmatched) {
+				t.Fatalf("expected %d unmatched tickers, got %v", len(tt.unmatched), resp.UnmatchedQuantity)
+			}
+			for ticker, quantity := range tt.unmatched {
+				assertDecimal(t, "unmatched "+ticker, resp.UnmatchedQuantity[ticker], quantity)
+			}
+		})
+	}
+}
+
+func TestWriteStockRealizedGainsCSV(t *testing.T) {
+	resp := realizedGains([]stockmodel.Activity{
+		testActivity(stockmodel.Buy, "NVDA", "5", "500", "0", 100, "b1"),
+		testActivity(stockmodel.Sell, "NVDA", "8", "960", "0", 200, "s1"),
+	}, types.LotMethodFIFO, 0)
+
+	var buf bytes.Buffer
+	if err := WriteStockRealizedGainsCSV(&buf, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"type,ticker,quantity,acquired_time,acquired_tx,disposed_time,disposed_tx,proceeds,cost_basis,gain",
+		"gain,NVDA,5,1970-01-01T00:01:40Z,b1,1970-01-01T00:03:20Z,s1,600,500,100",
+		"total,,,,,,,600,500,100",
+		"unmatched,NVDA,3,,,,,,,",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %q", len(expected), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: got %q, want %q", i, lines[i], expected[i])
+		}
+	}
+}
+
+func assertDecimal(t *testing.T, name string, got decimal.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(decimal.RequireFromString(want)) {
+		t.Errorf("%s: got %s, want %s", name, got, want)
+	}
+}
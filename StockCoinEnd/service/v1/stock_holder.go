@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	DefaultStockHolderLimit = 20
+	MaxStockHolderLimit     = 100
+)
+
+// GetStockHolders 获取股票代币的持有人数量及余额最多的limit个持有人（数据来自StockCoinSync同步的Transfer记录）
+func GetStockHolders(ctx context.Context, svcCtx *svc.ServerCtx, ticker string, limit int) (*types.StockHoldersResp, error) {
+	token, err := getStockTokenAddress(svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	chain := stockTokenChain(svcCtx)
+
+	holderCount, err := svcCtx.Dao.CountStockHolders(ctx, chain, token.String())
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := svcCtx.Dao.SumStockBalances(ctx, chain, token.String())
+	if err != nil {
+		return nil, err
+	}
+	balances, err := svcCtx.Dao.QueryTopStockHolders(ctx, chain, token.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make([]types.StockHolder, 0, len(balances))
+	for _, balance := range balances {
+		holder := types.StockHolder{
+			Address: balance.HolderAddress,
+			Balance: balance.Balance.Shift(-stockTokenDecimals),
+		}
+		if totalSupply.IsPositive() {
+			holder.Share, _ = balance.Balance.Div(totalSupply).Float64()
+		}
+		holders = append(holders, holder)
+	}
+
+	return &types.StockHoldersResp{
+		Ticker:       ticker,
+		TokenAddress: token.String(),
+		HolderCount:  holderCount,
+		TotalSupply:  totalSupply.Shift(-stockTokenDecimals),
+		Holders:      holders,
+	}, nil
+}
+
+// GetStockHolderCountHistory 获取股票代币每天的持有人数量，rangeStr与总览走势相同；
+// 没有转账的日期沿用前一天的数量
+func GetStockHolderCountHistory(ctx context.Context, svcCtx *svc.ServerCtx, ticker string, rangeStr string) (*types.StockHolderCountHistoryResp, error) {
+	duration, ok := overviewRanges[rangeStr]
+	if !ok {
+		return nil, errcode.NewCustomErr("unsupported range: " + rangeStr)
+	}
+	token, err := getStockTokenAddress(svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	chain := stockTokenChain(svcCtx)
+
+	const day = 24 * time.Hour
+	now := time.Now().UTC()
+	toDay := now.Truncate(day)
+	fromDay := now.Add(-duration).Truncate(day)
+
+	counts, err := svcCtx.Dao.QueryStockHolderCounts(ctx, chain, token.String(), fromDay.Unix(), toDay.Unix())
+	if err != nil {
+		return nil, err
+	}
+	last, err := svcCtx.Dao.GetLastStockHolderCount(ctx, chain, token.String(), fromDay.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	var current int64
+	if last != nil {
+		current = last.HolderCount
+	}
+	points := make([]types.StockHolderCountPoint, 0, int(toDay.Sub(fromDay)/day)+1)
+	next := 0
+	for d := fromDay; !d.After(toDay); d = d.Add(day) {
+		for next < len(counts) && counts[next].Day <= d.Unix() {
+			current = counts[next].HolderCount
+			next++
+		}
+		points = append(points, types.StockHolderCountPoint{Day: d.UnixMilli(), HolderCount: current})
+	}
+
+	return &types.StockHolderCountHistoryResp{
+		Ticker: ticker,
+		Range:  rangeStr,
+		Points: points,
+	}, nil
+}
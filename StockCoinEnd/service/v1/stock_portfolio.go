@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+// stockPositionSum 多个地址在同一股票上的持仓合计
+type stockPositionSum struct {
+	tokenAddress string
+	quantity     decimal.Decimal
+	costBasis    decimal.Decimal
+	realizedPnl  decimal.Decimal
+	feesPaid     decimal.Decimal
+}
+
+// stockTokenChain 股票代币所在链的名称，未配置时使用第一个支持的链
+func stockTokenChain(svcCtx *svc.ServerCtx) string {
+	if svcCtx.C.ChainReader != nil && svcCtx.C.ChainReader.Chain != "" {
+		return svcCtx.C.ChainReader.Chain
+	}
+	if len(svcCtx.C.ChainSupported) > 0 {
+		return svcCtx.C.ChainSupported[0].Name
+	}
+	return ""
+}
+
+// GetStockPortfolio 获取userAddrs持有的股票代币及盈亏，多个地址合并计算；
+// 持有数量以链上余额为准（未配置链上客户端时使用同步的余额），成本按平均成本计算，
+// 超出买入记录的持仓（例如通过转账获得）成本未知，不计入成本及未实现盈亏
+func GetStockPortfolio(ctx context.Context, svcCtx *svc.ServerCtx, userAddrs []string, currency string) (*types.StockPortfolioResp, error) {
+	quote, err := GetFxQuote(ctx, svcCtx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := svcCtx.Dao.QueryStockPositions(ctx, stockTokenChain(svcCtx), userAddrs)
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]*stockPositionSum)
+	for _, position := range positions {
+		ticker := strings.ToUpper(position.TokenSymbol)
+		sum, ok := sums[ticker]
+		if !ok {
+			sum = &stockPositionSum{tokenAddress: position.TokenAddress}
+			sums[ticker] = sum
+		}
+		sum.quantity = sum.quantity.Add(position.Quantity)
+		sum.costBasis = sum.costBasis.Add(position.CostBasis)
+		sum.realizedPnl = sum.realizedPnl.Add(position.RealizedPnl)
+		sum.feesPaid = sum.feesPaid.Add(position.FeesPaid)
+	}
+
+	stocks, err := svcCtx.Dao.ListAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on list stocks")
+	}
+	stockMap := make(map[string]dao.StockInfo, len(stocks))
+	for _, stock := range stocks {
+		stockMap[strings.ToUpper(stock.Ticker)] = stock
+	}
+
+	balances, err := stockBalances(ctx, svcCtx, userAddrs, stocks)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.StockPortfolioResp{
+		Addresses:          userAddrs,
+		Holdings:           []types.StockHolding{},
+		TotalValue:         decimal.Zero,
+		TotalCostBasis:     decimal.Zero,
+		TotalUnrealizedPnl: decimal.Zero,
+		TotalRealizedPnl:   decimal.Zero,
+		TotalFeesPaid:      decimal.Zero,
+		FxQuote:            *quote,
+	}
+	for _, sum := range sums {
+		resp.TotalRealizedPnl = resp.TotalRealizedPnl.Add(sum.realizedPnl)
+		resp.TotalFeesPaid = resp.TotalFeesPaid.Add(sum.feesPaid)
+	}
+
+	for ticker, balance := range balances {
+		if !balance.IsPositive() {
+			continue
+		}
+		stock := stockMap[ticker]
+		holding := types.StockHolding{
+			Ticker:              ticker,
+			Name:                stock.Name,
+			Logo:                stock.Logo,
+			TokenAddress:        stock.TokenAddress,
+			Balance:             balance,
+			UnknownCostQuantity: balance,
+			Price:               stock.CurrentPrice,
+			Value:               balance.Mul(stock.CurrentPrice),
+			AvgCost:             decimal.Zero,
+			CostBasis:           decimal.Zero,
+			UnrealizedPnl:       decimal.Zero,
+			RealizedPnl:         decimal.Zero,
+			FeesPaid:            decimal.Zero,
+		}
+		if sum, ok := sums[ticker]; ok {
+			if holding.TokenAddress == "" {
+				holding.TokenAddress = sum.tokenAddress
+			}
+			applyHoldingCost(&holding, sum)
+			holding.RealizedPnl = sum.realizedPnl
+			holding.FeesPaid = sum.feesPaid
+		}
+
+		resp.TotalValue = resp.TotalValue.Add(holding.Value)
+		resp.TotalCostBasis = resp.TotalCostBasis.Add(holding.CostBasis)
+		resp.TotalUnrealizedPnl = resp.TotalUnrealizedPnl.Add(holding.UnrealizedPnl)
+		resp.Holdings = append(resp.Holdings, holding)
+	}
+
+	sort.Slice(resp.Holdings, func(i, j int) bool {
+		if !resp.Holdings[i].Value.Equal(resp.Holdings[j].Value) {
+			return resp.Holdings[i].Value.GreaterThan(resp.Holdings[j].Value)
+		}
+		return resp.Holdings[i].Ticker < resp.Holdings[j].Ticker
+	})
+	for i := range resp.Holdings {
+		holding := &resp.Holdings[i]
+		if resp.TotalValue.IsPositive() {
+			holding.Allocation, _ = holding.Value.Div(resp.TotalValue).Float64()
+		}
+		holding.Price = holding.Price.Mul(quote.Rate)
+		holding.Value = holding.Value.Mul(quote.Rate)
+		holding.AvgCost = holding.AvgCost.Mul(quote.Rate)
+		holding.CostBasis = holding.CostBasis.Mul(quote.Rate)
+		holding.UnrealizedPnl = holding.UnrealizedPnl.Mul(quote.Rate)
+		holding.RealizedPnl = holding.RealizedPnl.Mul(quote.Rate)
+		holding.FeesPaid = holding.FeesPaid.Mul(quote.Rate)
+	}
+	resp.TotalValue = resp.TotalValue.Mul(quote.Rate)
+	resp.TotalCostBasis = resp.TotalCostBasis.Mul(quote.Rate)
+	resp.TotalUnrealizedPnl = resp.TotalUnrealizedPnl.Mul(quote.Rate)
+	resp.TotalRealizedPnl = resp.TotalRealizedPnl.Mul(quote.Rate)
+	resp.TotalFeesPaid = resp.TotalFeesPaid.Mul(quote.Rate)
+
+	return resp, nil
+}
+
+// applyHoldingCost 按平均成本计算持仓成本及未实现盈亏：只有不超过买入记录持仓数量的部分有成本，
+// 超出部分（如通过转账获得）成本未知，计入UnknownCostQuantity且不计算盈亏
+func applyHoldingCost(holding *types.StockHolding, sum *stockPositionSum) {
+	if !sum.quantity.IsPositive() {
+		return
+	}
+
+	known := decimal.Min(holding.Balance, sum.quantity)
+	holding.UnknownCostQuantity = holding.Balance.Sub(known)
+	holding.AvgCost = sum.costBasis.Div(sum.quantity)
+	holding.CostBasis = holding.AvgCost.Mul(known)
+	holding.UnrealizedPnl = known.Mul(holding.Price).Sub(holding.CostBasis)
+	if holding.CostBasis.IsPositive() {
+		holding.UnrealizedPnlPercent, _ = holding.UnrealizedPnl.Div(holding.CostBasis).Mul(decimal.NewFromInt(100)).Float64()
+	}
+}
+
+// stockBalances 查询userAddrs在各股票代币上的链上余额之和，查询失败的代币（如地址无效）不计入；
+// 未配置链上客户端时使用StockCoinSync根据Transfer同步的余额
+func stockBalances(ctx context.Context, svcCtx *svc.ServerCtx, userAddrs []string, stocks []dao.StockInfo) (map[string]decimal.Decimal, error) {
+	balances := make(map[string]decimal.Decimal)
+	if svcCtx.ChainReader == nil {
+		indexed, err := svcCtx.Dao.QueryStockBalancesByHolders(ctx, stockTokenChain(svcCtx), userAddrs)
+		if err != nil {
+			return nil, err
+		}
+		for _, balance := range indexed {
+			ticker := strings.ToUpper(balance.TokenSymbol)
+			balances[ticker] = balances[ticker].Add(balance.Balance.Shift(-stockTokenDecimals))
+		}
+		return balances, nil
+	}
+
+	tickers := make(map[common.Address]string)
+	tokens := make([]common.Address, 0, len(stocks))
+	for _, stock := range stocks {
+		if !common.IsHexAddress(stock.TokenAddress) {
+			continue
+		}
+		token := common.HexToAddress(stock.TokenAddress)
+		tickers[token] = strings.ToUpper(stock.Ticker)
+		tokens = append(tokens, token)
+	}
+
+	for _, addr := range userAddrs {
+		if !common.IsHexAddress(addr) {
+			continue
+		}
+		result, err := svcCtx.ChainReader.BalancesOf(ctx, common.HexToAddress(addr), tokens)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on get stock token balances")
+		}
+		for token, balance := range result {
+			ticker := tickers[token]
+			balances[ticker] = balances[ticker].Add(decimal.NewFromBigInt(balance, -stockTokenDecimals))
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+func TestApplyHoldingCost(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name                string
+		balance             string
+		sum                 stockPositionSum
+		costBasis           string
+		unrealizedPnl       string
+		unrealizedPnlPct    float64
+		unknownCostQuantity string
+	}{
+		{
+			// 买入10个、均价100，当前价120
+			name:                "all bought",
+			balance:             "10",
+			sum:                 stockPositionSum{quantity: d("10"), costBasis: d("1000")},
+			costBasis:           "1000",
+			unrealizedPnl:       "200",
+			unrealizedPnlPct:    20,
+			unknownCostQuantity: "0",
+		},
+		{
+			// 另外转入5个，只有买入的10个有成本
+			name:                "extra tokens received by transfer",
+			balance:             "15",
+			sum:                 stockPositionSum{quantity: d("10"), costBasis: d("1000")},
+			costBasis:           "1000",
+			unrealizedPnl:       "200",
+			unrealizedPnlPct:    20,
+			unknownCostQuantity: "5",
+		},
+		{
+			// 转出了一部分，余额少于买入数量
+			name:                "part transferred out",
+			balance:             "4",
+			sum:                 stockPositionSum{quantity: d("10"), costBasis: d("1000")},
+			costBasis:           "400",
+			unrealizedPnl:       "80",
+			unrealizedPnlPct:    20,
+			unknownCostQuantity: "0",
+		},
+		{
+			name:                "no buys",
+			balance:             "3",
+			sum:                 stockPositionSum{},
+			costBasis:           "0",
+			unrealizedPnl:       "0",
+			unknownCostQuantity: "3",
+		},
+		{
+			// 成本为0（如空投）时不计算百分比
+			name:                "zero cost",
+			balance:             "2",
+			sum:                 stockPositionSum{quantity: d("2"), costBasis: decimal.Zero},
+			costBasis:           "0",
+			unrealizedPnl:       "240",
+			unknownCostQuantity: "0",
+		},
+	}
+	for _, tt := range tests {
+		holding := types.StockHolding{
+			Balance:             d(tt.balance),
+			Price:               d("120"),
+			UnknownCostQuantity: d(tt.balance),
+		}
+		applyHoldingCost(&holding, &tt.sum)
+		if !holding.CostBasis.Equal(d(tt.costBasis)) || !holding.UnrealizedPnl.Equal(d(tt.unrealizedPnl)) {
+			t.Errorf("%s: cost basis/pnl = %s/%s, want %s/%s", tt.name,
+				holding.CostBasis, holding.UnrealizedPnl, tt.costBasis, tt.unrealizedPnl)
+		}
+		if holding.UnrealizedPnlPercent != tt.unrealizedPnlPct {
+			t.Errorf("%s: pnl percent = %v, want %v", tt.name, holding.UnrealizedPnlPercent, tt.unrealizedPnlPct)
+		}
+		if !holding.UnknownCostQuantity.Equal(d(tt.unknownCostQuantity)) {
+			t.Errorf("%s: unknown cost quantity = %s, want %s", tt.name, holding.UnknownCostQuantity, tt.unknownCostQuantity)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	usdtDecimals = 6 // USDT精度
+
+	// 交易参数变化不频繁，储备随交易变化，短暂缓存即可
+	stockTokenInfoCacheSeconds = 15
+)
+
+func stockTokenInfoCacheKey(ticker string) string {
+	return fmt.Sprintf("cache:stock:token:%s:info", strings.ToLower(ticker))
+}
+
+// GetStockTokenInfo 读取股票代币合约的手续费率、最小交易金额、暂停状态及储备
+func GetStockTokenInfo(ctx context.Context, svcCtx *svc.ServerCtx, ticker string) (*types.StockTokenInfo, error) {
+	if svcCtx.StockToken == nil {
+		return nil, errors.New("chain reader is not configured")
+	}
+
+	var info types.StockTokenInfo
+	err := svcCtx.KvStore.ReadOrGet(stockTokenInfoCacheKey(ticker), &info, func() (interface{}, error) {
+		return fetchStockTokenInfo(ctx, svcCtx, ticker)
+	}, stockTokenInfoCacheSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func fetchStockTokenInfo(ctx context.Context, svcCtx *svc.ServerCtx, ticker string) (*types.StockTokenInfo, error) {
+	token, err := getStockTokenAddress(svcCtx, ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	tradingInfo, err := svcCtx.StockToken.GetTradingInfo(ctx, token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed on get trading info of %s", ticker)
+	}
+
+	return &types.StockTokenInfo{
+		Ticker:         ticker,
+		TokenAddress:   token.Hex(),
+		MinTradeAmount: decimal.NewFromBigInt(tradingInfo.MinTradeAmount, -usdtDecimals),
+		MaxSlippageBps: tradingInfo.MaxSlippage.Int64(),
+		TradeFeeBps:    tradingInfo.TradeFeeRate.Int64(),
+		FeeReceiver:    tradingInfo.FeeReceiver.Hex(),
+		Paused:         tradingInfo.Paused,
+		TokenReserve:   decimal.NewFromBigInt(tradingInfo.TokenReserve, -stockTokenDecimals),
+		USDTReserve:    decimal.NewFromBigInt(tradingInfo.USDTReserve, -usdtDecimals),
+		UpdatedAt:      time.Now().UnixMilli(),
+	}, nil
+}
+
+// getStockTokenAddress 获取StockInfo中记录的代币地址
+func getStockTokenAddress(svcCtx *svc.ServerCtx, ticker string) (common.Address, error) {
+	stocks, err := svcCtx.Dao.GetByTickers([]string{ticker})
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed on query stock")
+	}
+	if len(stocks) == 0 {
+		return common.Address{}, errcode.NewCustomErr("unknown ticker: " + ticker)
+	}
+	if !common.IsHexAddress(stocks[0].TokenAddress) {
+		return common.Address{}, errcode.NewCustomErr(ticker + " has no token address")
+	}
+
+	return common.HexToAddress(stocks[0].TokenAddress), nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+// getStockOnChainStats 批量查询股票代币的链上成交统计，key为大写的股票代码；没有统计记录的股票不在结果中
+func getStockOnChainStats(ctx context.Context, svcCtx *svc.ServerCtx, tickers []string) (map[string]types.StockOnChainStats, error) {
+	stats, err := svcCtx.Dao.QueryStockTradeStats(ctx, stockTokenChain(svcCtx), tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]types.StockOnChainStats, len(stats))
+	for _, stat := range stats {
+		result[strings.ToUpper(stat.TokenSymbol)] = types.StockOnChainStats{
+			Volume1h:   stat.Volume1h,
+			Volume24h:  stat.Volume24h,
+			Volume7d:   stat.Volume7d,
+			Trades1h:   stat.Trades1h,
+			Trades24h:  stat.Trades24h,
+			Trades7d:   stat.Trades7d,
+			Traders1h:  stat.Traders1h,
+			Traders24h: stat.Traders24h,
+			Traders7d:  stat.Traders7d,
+			UpdatedAt:  stat.StatTime * 1000,
+		}
+	}
+	return result, nil
+}
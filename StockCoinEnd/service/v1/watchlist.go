@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+	"github.com/locey/CryptoStock/StockCoinEnd/dao"
+	"github.com/locey/CryptoStock/StockCoinEnd/service/svc"
+	"github.com/locey/CryptoStock/StockCoinEnd/types/v1"
+)
+
+const (
+	maxWatchlists       = 20  // 每个用户最多的自选股列表数量
+	maxWatchlistTickers = 100 // 每个列表最多的股票数量
+	maxWatchlistNameLen = 64  // 列表名称最大字符数，与watchlists.name的长度一致
+)
+
+var ErrWatchlistNotFound = errcode.NewCustomErr("watchlist not found")
+
+// GetWatchlists 获取用户的全部自选股列表及其中股票的摘要
+func GetWatchlists(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string) ([]types.Watchlist, error) {
+	watchlists, err := svcCtx.Dao.QueryWatchlists(ctx, userAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildWatchlists(ctx, svcCtx, watchlists)
+}
+
+// GetWatchlist 获取用户的指定自选股列表
+func GetWatchlist(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64) (*types.Watchlist, error) {
+	watchlist, err := getOwnedWatchlist(ctx, svcCtx, userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := buildWatchlists(ctx, svcCtx, []dao.Watchlist{*watchlist})
+	if err != nil {
+		return nil, err
+	}
+	return &res[0], nil
+}
+
+// CreateWatchlist 创建自选股列表
+func CreateWatchlist(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, req *types.CreateWatchlistReq) (*types.Watchlist, error) {
+	name, err := normalizeWatchlistName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlists, err := svcCtx.Dao.QueryWatchlists(ctx, userAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(watchlists) >= maxWatchlists {
+		return nil, errcode.NewCustomErr(fmt.Sprintf("at most %d watchlists are allowed", maxWatchlists))
+	}
+	for _, w := range watchlists {
+		if strings.EqualFold(w.Name, name) {
+			return nil, errcode.NewCustomErr(fmt.Sprintf("watchlist %s already exists", name))
+		}
+	}
+
+	tickers, err := normalizeTickers(svcCtx, req.Tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist := &dao.Watchlist{
+		UserAddress: userAddress,
+		Name:        name,
+	}
+	if err := svcCtx.Dao.CreateWatchlist(ctx, watchlist, tickers); err != nil {
+		return nil, err
+	}
+
+	return GetWatchlist(ctx, svcCtx, userAddress, watchlist.ID)
+}
+
+// RenameWatchlist 修改自选股列表名称
+func RenameWatchlist(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64, name string) error {
+	name, err := normalizeWatchlistName(name)
+	if err != nil {
+		return err
+	}
+
+	watchlists, err := svcCtx.Dao.QueryWatchlists(ctx, userAddress)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, w := range watchlists {
+		if w.ID == id {
+			found = true
+		} else if strings.EqualFold(w.Name, name) {
+			return errcode.NewCustomErr(fmt.Sprintf("watchlist %s already exists", name))
+		}
+	}
+	if !found {
+		return ErrWatchlistNotFound
+	}
+
+	return svcCtx.Dao.RenameWatchlist(ctx, userAddress, id, name)
+}
+
+// normalizeWatchlistName 去除名称首尾空白并校验长度
+func normalizeWatchlistName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errcode.NewCustomErr("watchlist name is required")
+	}
+	if utf8.RuneCountInString(name) > maxWatchlistNameLen {
+		return "", errcode.NewCustomErr(fmt.Sprintf("watchlist name must be at most %d characters", maxWatchlistNameLen))
+	}
+	return name, nil
+}
+
+// DeleteWatchlist 删除自选股列表
+func DeleteWatchlist(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64) error {
+	if _, err := getOwnedWatchlist(ctx, svcCtx, userAddress, id); err != nil {
+		return err
+	}
+
+	return svcCtx.Dao.DeleteWatchlist(ctx, userAddress, id)
+}
+
+// ReorderWatchlists 重排用户的自选股列表，ids需包含用户的全部列表
+func ReorderWatchlists(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, ids []int64) error {
+	watchlists, err := svcCtx.Dao.QueryWatchlists(ctx, userAddress)
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[int64]bool, len(watchlists))
+	for _, w := range watchlists {
+		owned[w.ID] = true
+	}
+	if len(ids) != len(owned) {
+		return errcode.NewCustomErr("ids must contain every watchlist exactly once")
+	}
+	for _, id := range ids {
+		if !owned[id] {
+			return errcode.NewCustomErr("ids must contain every watchlist exactly once")
+		}
+		delete(owned, id)
+	}
+
+	return svcCtx.Dao.ReorderWatchlists(ctx, userAddress, ids)
+}
+
+// SetWatchlistTickers 整体设置列表中的股票及顺序
+func SetWatchlistTickers(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64, tickers []string) (*types.Watchlist, error) {
+	if _, err := getOwnedWatchlist(ctx, svcCtx, userAddress, id); err != nil {
+		return nil, err
+	}
+
+	normalized, err := normalizeTickers(svcCtx, tickers)
+	if err != nil {
+		return nil, err
+	}
+	if err := svcCtx.Dao.ReplaceWatchlistItems(ctx, id, normalized); err != nil {
+		return nil, err
+	}
+
+	return GetWatchlist(ctx, svcCtx, userAddress, id)
+}
+
+// AddWatchlistTicker 将股票追加到列表末尾，已存在时不做处理
+func AddWatchlistTicker(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64, ticker string) (*types.Watchlist, error) {
+	tickers, err := getWatchlistTickers(ctx, svcCtx, userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetWatchlistTickers(ctx, svcCtx, userAddress, id, append(tickers, ticker))
+}
+
+// RemoveWatchlistTicker 从列表中移除股票
+func RemoveWatchlistTicker(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64, ticker string) (*types.Watchlist, error) {
+	tickers, err := getWatchlistTickers(ctx, svcCtx, userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make([]string, 0, len(tickers))
+	for _, t := range tickers {
+		if !strings.EqualFold(t, ticker) {
+			remaining = append(remaining, t)
+		}
+	}
+	return SetWatchlistTickers(ctx, svcCtx, userAddress, id, remaining)
+}
+
+func getOwnedWatchlist(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64) (*dao.Watchlist, error) {
+	watchlist, err := svcCtx.Dao.GetWatchlist(ctx, userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if watchlist == nil {
+		return nil, ErrWatchlistNotFound
+	}
+	return watchlist, nil
+}
+
+func getWatchlistTickers(ctx context.Context, svcCtx *svc.ServerCtx, userAddress string, id int64) ([]string, error) {
+	if _, err := getOwnedWatchlist(ctx, svcCtx, userAddress, id); err != nil {
+		return nil, err
+	}
+
+	items, err := svcCtx.Dao.QueryWatchlistItems(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]string, 0, len(items))
+	for _, item := range items {
+		tickers = append(tickers, item.Ticker)
+	}
+	return tickers, nil
+}
+
+// normalizeTickers 统一为大写并去重（保留首次出现的顺序），且必须是已收录的股票
+func normalizeTickers(svcCtx *svc.ServerCtx, tickers []string) ([]string, error) {
+	normalized := uniqueTickers(tickers)
+	if len(normalized) > maxWatchlistTickers {
+		return nil, errcode.NewCustomErr(fmt.Sprintf("at most %d tickers are allowed in a watchlist", maxWatchlistTickers))
+	}
+	if len(normalized) == 0 {
+		return normalized, nil
+	}
+
+	stocks, err := svcCtx.Dao.GetByTickers(normalized)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed on query stocks")
+	}
+	known := make(map[string]bool, len(stocks))
+	for _, stock := range stocks {
+		known[stock.Ticker] = true
+	}
+	for _, ticker := range normalized {
+		if !known[ticker] {
+			return nil, errcode.NewCustomErr(fmt.Sprintf("unknown ticker: %s", ticker))
+		}
+	}
+
+	return normalized, nil
+}
+
+// uniqueTickers 去掉空白并统一为大写，按首次出现的顺序去重
+func uniqueTickers(tickers []string) []string {
+	seen := make(map[string]bool, len(tickers))
+	unique := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		unique = append(unique, ticker)
+	}
+	return unique
+}
+
+// buildWatchlists 组装列表及股票摘要，所有列表的股票一次查询、一次批量读取链上发行量
+func buildWatchlists(ctx context.Context, svcCtx *svc.ServerCtx, watchlists []dao.Watchlist) ([]types.Watchlist, error) {
+	ids := make([]int64, 0, len(watchlists))
+	for _, w := range watchlists {
+		ids = append(ids, w.ID)
+	}
+	items, err := svcCtx.Dao.QueryWatchlistItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tickersByList := make(map[int64][]string, len(watchlists))
+	tickerSet := make(map[string]bool)
+	allTickers := make([]string, 0, len(items))
+	for _, item := range items {
+		tickersByList[item.WatchlistID] = append(tickersByList[item.WatchlistID], item.Ticker)
+		if !tickerSet[item.Ticker] {
+			tickerSet[item.Ticker] = true
+			allTickers = append(allTickers, item.Ticker)
+		}
+	}
+
+	summaryByTicker := make(map[string]StockSummary, len(allTickers))
+	if len(allTickers) > 0 {
+		stocks, err := svcCtx.Dao.GetByTickers(allTickers)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed on query stocks")
+		}
+		summaries, err := buildStockSummaries(ctx, svcCtx, stocks)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range summaries {
+			summaryByTicker[summary.Ticker] = summary
+		}
+	}
+
+	res := make([]types.Watchlist, 0, len(watchlists))
+	for _, w := range watchlists {
+		tickers := tickersByList[w.ID]
+		if tickers == nil {
+			tickers = []string{}
+		}
+		stocks := make([]StockSummary, 0, len(tickers))
+		for _, ticker := range tickers {
+			if summary, ok := summaryByTicker[ticker]; ok {
+				stocks = append(stocks, summary)
+			}
+		}
+		res = append(res, types.Watchlist{
+			ID:      w.ID,
+			Name:    w.Name,
+			Sort:    w.Sort,
+			Tickers: tickers,
+			Stocks:  stocks,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/locey/CryptoStock/StockCoinBase/errcode"
+)
+
+func TestUniqueTickers(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"empty", nil, []string{}},
+		{"uppercase and trim", []string{" aapl ", "Tsla"}, []string{"AAPL", "TSLA"}},
+		{"keep first occurrence order", []string{"msft", "AAPL", "MSFT", "aapl", "nvda"}, []string{"MSFT", "AAPL", "NVDA"}},
+		{"skip blanks", []string{"", "  ", "goog"}, []string{"GOOG"}},
+	}
+	for _, tt := range tests {
+		if got := uniqueTickers(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: uniqueTickers(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTickers(t *testing.T) {
+	// 去重后为空时不查询股票
+	normalized, err := normalizeTickers(nil, []string{" ", ""})
+	if err != nil || len(normalized) != 0 {
+		t.Errorf("normalizeTickers(blank) = %q, %v, want empty", normalized, err)
+	}
+
+	// 超出数量上限时直接返回校验错误，重复的代码只计一次
+	tickers := make([]string, 0, maxWatchlistTickers*2)
+	for i := 0; i < maxWatchlistTickers; i++ {
+		tickers = append(tickers, fmt.Sprintf("t%d", i), fmt.Sprintf("T%d", i))
+	}
+	if got := uniqueTickers(tickers); len(got) != maxWatchlistTickers {
+		t.Fatalf("expected %d unique tickers, got %d", maxWatchlistTickers, len(got))
+	}
+	tickers = append(tickers, "extra")
+	if _, err := normalizeTickers(nil, tickers); err == nil || !errcode.IsErr(err) {
+		t.Errorf("normalizeTickers over the limit = %v, want a validation error", err)
+	}
+}
+
+func TestNormalizeWatchlistName(t *testing.T) {
+	name, err := normalizeWatchlistName("  Tech  ")
+	if err != nil || name != "Tech" {
+		t.Errorf("normalizeWatchlistName(trim) = %q, %v, want Tech", name, err)
+	}
+
+	// 长度按字符计算，64个中文字符是合法的
+	long := strings.Repeat("科", maxWatchlistNameLen)
+	if name, err := normalizeWatchlistName(long); err != nil || name != long {
+		t.Errorf("normalizeWatchlistName(%d runes) = %v, want ok", maxWatchlistNameLen, err)
+	}
+
+	for _, in := range []string{"", "   ", long + "a"} {
+		if _, err := normalizeWatchlistName(in); err == nil || !errcode.IsErr(err) {
+			t.Errorf("normalizeWatchlistName(%q) = %v, want a validation error", in, err)
+		}
+	}
+}
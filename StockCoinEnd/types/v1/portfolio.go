@@ -178,3 +178,93 @@ type MultichainCollection struct {
 	CollectionAddress string `json:"collection_address"`
 	Chain             string `json:"chain"`
 }
+
+// StockHolding 用户持有的单个股票代币，多个地址的持仓合并计算
+type StockHolding struct {
+	Ticker               string          `json:"ticker"`
+	Name                 string          `json:"name"`
+	Logo                 string          `json:"logo"`
+	TokenAddress         string          `json:"token_address"`
+	Balance              decimal.Decimal `json:"balance"`                // 持有数量
+	UnknownCostQuantity  decimal.Decimal `json:"unknown_cost_quantity"`  // 超出买入数量、成本未知的数量（如转入的代币）
+	Price                decimal.Decimal `json:"price"`                  // 当前价格
+	Value                decimal.Decimal `json:"value"`                  // 当前市值
+	AvgCost              decimal.Decimal `json:"avg_cost"`               // 平均成本
+	CostBasis            decimal.Decimal `json:"cost_basis"`             // 持仓成本
+	UnrealizedPnl        decimal.Decimal `json:"unrealized_pnl"`         // 未实现盈亏
+	UnrealizedPnlPercent float64         `json:"unrealized_pnl_percent"` // 未实现盈亏百分比
+	RealizedPnl          decimal.Decimal `json:"realized_pnl"`           // 已实现盈亏
+	FeesPaid             decimal.Decimal `json:"fees_paid"`              // 累计手续费
+	Allocation           float64         `json:"allocation"`             // 占总市值的比例(0-1)
+}
+
+type StockPortfolioResp struct {
+	Addresses          []string        `json:"addresses"`
+	Holdings           []StockHolding  `json:"holdings"`
+	TotalValue         decimal.Decimal `json:"total_value"`
+	TotalCostBasis     decimal.Decimal `json:"total_cost_basis"`
+	TotalUnrealizedPnl decimal.Decimal `json:"total_unrealized_pnl"`
+	TotalRealizedPnl   decimal.Decimal `json:"total_realized_pnl"` // 包含已清仓股票的已实现盈亏
+	TotalFeesPaid      decimal.Decimal `json:"total_fees_paid"`
+	FxQuote
+}
+
+// 导出格式
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// 计算已实现收益时的成本结转方式
+const (
+	LotMethodFIFO    = "fifo"    // 先买先卖
+	LotMethodLIFO    = "lifo"    // 后买先卖
+	LotMethodAverage = "average" // 平均成本
+)
+
+// StockTrade 一笔股票代币买卖记录，金额均为USDT
+type StockTrade struct {
+	Time         int64           `json:"time"` // 成交时间（毫秒）
+	Side         string          `json:"side"` // buy / sell
+	Ticker       string          `json:"ticker"`
+	TokenAddress string          `json:"token_address"`
+	UserAddress  string          `json:"user_address"`
+	Quantity     decimal.Decimal `json:"quantity"` // 代币数量
+	Price        decimal.Decimal `json:"price"`    // 成交价格
+	Amount       decimal.Decimal `json:"amount"`   // 买入为支付的USDT，卖出为扣除手续费后得到的USDT
+	Fee          decimal.Decimal `json:"fee"`      // 手续费
+	BlockNumber  int64           `json:"block_number"`
+	TxHash       string          `json:"tx_hash"`
+}
+
+type StockTradesResp struct {
+	Addresses []string     `json:"addresses"`
+	From      int64        `json:"from"` // 毫秒
+	To        int64        `json:"to"`   // 毫秒
+	Trades    []StockTrade `json:"trades"`
+}
+
+// StockRealizedGain 一笔卖出按成本结转方式对应到的买入批次的已实现收益；平均成本方式下每笔卖出一条，没有买入时间
+type StockRealizedGain struct {
+	Ticker       string          `json:"ticker"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	AcquiredTime int64           `json:"acquired_time"` // 买入时间（毫秒）
+	AcquiredTx   string          `json:"acquired_tx"`
+	DisposedTime int64           `json:"disposed_time"` // 卖出时间（毫秒）
+	DisposedTx   string          `json:"disposed_tx"`
+	Proceeds     decimal.Decimal `json:"proceeds"`   // 卖出所得（已扣除手续费）
+	CostBasis    decimal.Decimal `json:"cost_basis"` // 成本（包含买入手续费）
+	Gain         decimal.Decimal `json:"gain"`
+}
+
+type StockRealizedGainsResp struct {
+	Addresses         []string                   `json:"addresses"`
+	Method            string                     `json:"method"`
+	From              int64                      `json:"from"` // 毫秒
+	To                int64                      `json:"to"`   // 毫秒
+	Gains             []StockRealizedGain        `json:"gains"`
+	TotalProceeds     decimal.Decimal            `json:"total_proceeds"`
+	TotalCostBasis    decimal.Decimal            `json:"total_cost_basis"`
+	TotalGain         decimal.Decimal            `json:"total_gain"`
+	UnmatchedQuantity map[string]decimal.Decimal `json:"unmatched_quantity"` // 卖出数量超过买入记录的部分（例如由转账获得），没有成本，不计入收益
+}
@@ -0,0 +1,45 @@
+package types
+
+import "github.com/shopspring/decimal"
+
+type CreatePriceAlertReq struct {
+	Ticker          string          `json:"ticker" binding:"required,max=20"`
+	Condition       string          `json:"condition" binding:"required,oneof=price_above price_below day_change"`
+	Threshold       decimal.Decimal `json:"threshold"`                                             // 价格阈值，day_change时为百分比（如5表示5%）
+	Mode            string          `json:"mode" binding:"omitempty,oneof=once recurring"`         // 默认once
+	CooldownSeconds *int64          `json:"cooldown_seconds" binding:"omitempty,min=0,max=604800"` // 不传时默认1小时，0表示不冷却
+}
+
+type SetPriceAlertActiveReq struct {
+	IsActive bool `json:"is_active"`
+}
+
+type PriceAlert struct {
+	ID              int64           `json:"id"`
+	Ticker          string          `json:"ticker"`
+	Condition       string          `json:"condition"`
+	Threshold       decimal.Decimal `json:"threshold"`
+	Mode            string          `json:"mode"`
+	CooldownSeconds int64           `json:"cooldown_seconds"`
+	IsActive        bool            `json:"is_active"`
+	LastTriggeredAt int64           `json:"last_triggered_at"`
+	TriggerCount    int64           `json:"trigger_count"`
+	CreatedAt       int64           `json:"created_at"`
+}
+
+type PriceAlertDelivery struct {
+	ID            int64           `json:"id"`
+	AlertID       int64           `json:"alert_id"`
+	Ticker        string          `json:"ticker"`
+	Condition     string          `json:"condition"`
+	Threshold     decimal.Decimal `json:"threshold"`
+	Price         decimal.Decimal `json:"price"`
+	ChangePercent float64         `json:"change_percent"`
+	TriggeredAt   int64           `json:"triggered_at"`
+	Status        string          `json:"status"`
+}
+
+type PriceAlertDeliveriesResp struct {
+	Result interface{} `json:"result"`
+	Count  int64       `json:"count"`
+}
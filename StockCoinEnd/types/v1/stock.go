@@ -0,0 +1,179 @@
+package types
+
+import "github.com/shopspring/decimal"
+
+const (
+	StockSortByMarketCap   = "market_cap"
+	StockSortByPrice       = "price"
+	StockSortByGainPercent = "gain_percent"
+	StockSortByVolume      = "volume"
+)
+
+// StockFilterParams 股票列表的搜索、筛选、排序及分页参数
+type StockFilterParams struct {
+	Keyword  string `json:"keyword"`   // 按股票代码或名称模糊搜索
+	Category string `json:"category"`  // 代币类别(stock/crypto/commodity/forex)
+	IsActive *bool  `json:"is_active"` // 为空时不按活跃状态筛选
+	SortBy   string `json:"sort_by"`   // market_cap/price/gain_percent/volume
+	Desc     bool   `json:"desc"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+type StockListResp struct {
+	Result interface{} `json:"result"`
+	Count  int64       `json:"count"`
+	FxQuote
+}
+
+type StockCandle struct {
+	Timestamp int64   `json:"timestamp"` // 开盘时间（毫秒）
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	ItemCount int64   `json:"item_count"`
+}
+
+type StockCandlesResp struct {
+	Ticker   string        `json:"ticker"`
+	Interval string        `json:"interval"`
+	Candles  []StockCandle `json:"candles"`
+}
+
+type CorporateAction struct {
+	Type       string          `json:"type"`        // split / dividend
+	ExDate     int64           `json:"ex_date"`     // 除权除息日（毫秒）
+	PayDate    int64           `json:"pay_date"`    // 派息日（毫秒），仅分红
+	SplitFrom  float64         `json:"split_from"`  // 拆股前股数
+	SplitTo    float64         `json:"split_to"`    // 拆股后股数
+	CashAmount decimal.Decimal `json:"cash_amount"` // 每股分红金额
+	Currency   string          `json:"currency"`
+	Upcoming   bool            `json:"upcoming"` // 是否尚未发生
+}
+
+type CorporateActionsResp struct {
+	Ticker  string            `json:"ticker"`
+	Actions []CorporateAction `json:"actions"`
+}
+
+// StockTokenInfo StockToken合约的交易参数及储备
+type StockTokenInfo struct {
+	Ticker         string          `json:"ticker"`
+	TokenAddress   string          `json:"token_address"`
+	MinTradeAmount decimal.Decimal `json:"min_trade_amount"` // 最小交易金额（USDT）
+	MaxSlippageBps int64           `json:"max_slippage_bps"` // 最大滑点（基点）
+	TradeFeeBps    int64           `json:"trade_fee_bps"`    // 交易手续费率（基点）
+	FeeReceiver    string          `json:"fee_receiver"`
+	Paused         bool            `json:"paused"`        // 是否暂停交易
+	TokenReserve   decimal.Decimal `json:"token_reserve"` // 合约持有的代币数量
+	USDTReserve    decimal.Decimal `json:"usdt_reserve"`  // 合约持有的USDT数量
+	UpdatedAt      int64           `json:"updated_at"`    // 链上读取时间（毫秒）
+}
+
+// 买卖预估方向
+const (
+	QuoteSideBuy  = "buy"  // 以USDT买入代币
+	QuoteSideSell = "sell" // 卖出代币换回USDT
+)
+
+// StockQuote 买卖预估结果，金额均为按精度换算后的数量
+type StockQuote struct {
+	Ticker         string          `json:"ticker"`
+	TokenAddress   string          `json:"token_address"`
+	Side           string          `json:"side"`
+	Amount         decimal.Decimal `json:"amount"`                     // 投入数量：买入为USDT，卖出为代币
+	ExpectedOutput decimal.Decimal `json:"expected_output"`            // 扣除手续费后可得数量：买入为代币，卖出为USDT
+	Fee            decimal.Decimal `json:"fee"`                        // 手续费，与expected_output同币种
+	Price          decimal.Decimal `json:"price"`                      // 预估使用的预言机价格（USDT）
+	SlippageBps    int64           `json:"slippage_bps"`               // 滑点容忍度（基点）
+	MinOutput      decimal.Decimal `json:"min_output"`                 // 按滑点容忍度计算的最少可得数量
+	MinTokenAmount string          `json:"min_token_amount,omitempty"` // 买入时传给buy的minTokenAmount（18位精度整数）
+	MinUsdtAmount  string          `json:"min_usdt_amount,omitempty"`  // 卖出时传给sell的minUsdtAmount（6位精度整数）
+	UpdateFee      string          `json:"update_fee"`                 // 交易时需随调用支付的预言机更新费用（wei）
+	Warnings       []string        `json:"warnings"`                   // 按当前合约状态交易可能失败的原因
+	QuotedAt       int64           `json:"quoted_at"`                  // 预估时间（毫秒）
+}
+
+// OracleUpdateData 调用getAggregatedPrice、buy/sell时需要的预言机更新数据
+type OracleUpdateData struct {
+	Ticker     string             `json:"ticker"`
+	Oracles    []OracleUpdateItem `json:"oracles"`     // 与PriceAggregator的预言机列表一一对应
+	UpdateData [][]string         `json:"update_data"` // 按预言机顺序排列的bytes[][]参数（0x前缀的hex）
+	Fee        string             `json:"fee"`         // 需随调用支付的msg.value（wei）
+	BuiltAt    int64              `json:"built_at"`    // 构造时间（毫秒）
+}
+
+type OracleUpdateItem struct {
+	OracleType string `json:"oracle_type"` // pyth / redstone
+	Address    string `json:"address"`
+	Fee        string `json:"fee"` // 该预言机需要的更新费用（wei）
+}
+
+// OverviewHistoryPoint 平台总览走势中的一个采样点
+type OverviewHistoryPoint struct {
+	Timestamp       int64           `json:"timestamp"` // 快照时间（毫秒）
+	TotalMarketCap  decimal.Decimal `json:"total_market_cap"`
+	PoolTokenValue  decimal.Decimal `json:"pool_token_value"`  // 币股池代币按当前价格折算的价值（USDT）
+	PoolUsdtReserve decimal.Decimal `json:"pool_usdt_reserve"` // 币股池持有的USDT
+	Tvl             decimal.Decimal `json:"tvl"`               // pool_token_value + pool_usdt_reserve
+	Volume24h       decimal.Decimal `json:"volume_24h"`        // 近24小时链上成交额（USDT）
+	StockCount      int64           `json:"stock_count"`
+}
+
+type OverviewHistoryResp struct {
+	Range  string                 `json:"range"`
+	From   int64                  `json:"from"`
+	To     int64                  `json:"to"`
+	Points []OverviewHistoryPoint `json:"points"`
+	FxQuote
+}
+
+// FxQuote 金额换算使用的汇率：换算后金额 = 美元金额 * fx_rate
+type FxQuote struct {
+	Currency string          `json:"currency"`
+	Rate     decimal.Decimal `json:"fx_rate"`
+	AsOf     int64           `json:"fx_rate_as_of"` // 汇率更新时间（毫秒）
+}
+
+// StockHolder 股票代币持有人
+type StockHolder struct {
+	Address string          `json:"address"`
+	Balance decimal.Decimal `json:"balance"`
+	Share   float64         `json:"share"` // 占总供应量的比例(0-1)
+}
+
+type StockHoldersResp struct {
+	Ticker       string          `json:"ticker"`
+	TokenAddress string          `json:"token_address"`
+	HolderCount  int64           `json:"holder_count"`
+	TotalSupply  decimal.Decimal `json:"total_supply"`
+	Holders      []StockHolder   `json:"holders"`
+}
+
+// StockHolderCountPoint 某天的持有人数量
+type StockHolderCountPoint struct {
+	Day         int64 `json:"day"` // 当天0点(UTC)的时间戳（毫秒）
+	HolderCount int64 `json:"holder_count"`
+}
+
+type StockHolderCountHistoryResp struct {
+	Ticker string                  `json:"ticker"`
+	Range  string                  `json:"range"`
+	Points []StockHolderCountPoint `json:"points"`
+}
+
+// StockOnChainStats 股票代币最近1小时、24小时、7天的链上成交统计，成交额为USDT，不按币种换算
+type StockOnChainStats struct {
+	Volume1h   decimal.Decimal `json:"volume_1h"`
+	Volume24h  decimal.Decimal `json:"volume_24h"`
+	Volume7d   decimal.Decimal `json:"volume_7d"`
+	Trades1h   int64           `json:"trades_1h"`
+	Trades24h  int64           `json:"trades_24h"`
+	Trades7d   int64           `json:"trades_7d"`
+	Traders1h  int64           `json:"traders_1h"`  // 交易地址数
+	Traders24h int64           `json:"traders_24h"` // 交易地址数
+	Traders7d  int64           `json:"traders_7d"`  // 交易地址数
+	UpdatedAt  int64           `json:"updated_at"`  // 统计时间（毫秒），为0表示尚无成交
+}
@@ -0,0 +1,32 @@
+package types
+
+type CreateWatchlistReq struct {
+	Name    string   `json:"name" binding:"required,max=64"`
+	Tickers []string `json:"tickers"`
+}
+
+type RenameWatchlistReq struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// ReorderWatchlistsReq 按ids的顺序重排用户的全部自选股列表
+type ReorderWatchlistsReq struct {
+	IDs []int64 `json:"ids" binding:"required"`
+}
+
+// WatchlistTickersReq 按tickers的顺序整体设置列表中的股票
+type WatchlistTickersReq struct {
+	Tickers []string `json:"tickers"`
+}
+
+type AddWatchlistTickerReq struct {
+	Ticker string `json:"ticker" binding:"required"`
+}
+
+type Watchlist struct {
+	ID      int64       `json:"id"`
+	Name    string      `json:"name"`
+	Sort    int         `json:"sort"`
+	Tickers []string    `json:"tickers"`
+	Stocks  interface{} `json:"stocks"` // 按列表顺序返回的股票摘要
+}
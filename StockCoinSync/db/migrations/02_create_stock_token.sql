@@ -0,0 +1,42 @@
+create table st_indexed_status
+(
+    id                 bigint auto_increment comment '主键'
+        primary key,
+    chain_id           bigint  default 1 not null comment '链id (1:以太坊, 11155111: sepolia)',
+    last_indexed_block bigint  default 0 null comment '下一个待同步的区块号',
+    last_indexed_time  bigint            null comment '最后同步时间戳',
+    index_type         tinyint default 0 not null comment '0:stocktoken买卖事件',
+    create_time        bigint            null,
+    update_time        bigint            null,
+    constraint index_chain_type
+        unique (chain_id, index_type)
+)
+    collate = utf8mb4_general_ci;
+
+create table st_activity_sepolia
+(
+    id              bigint auto_increment comment '主键'
+        primary key,
+    activity_type   tinyint                 not null comment '(1:Buy,2:Sell)',
+    user_address    varchar(42)             not null comment '买入或卖出的用户地址',
+    token_address   varchar(42) default ''  not null comment 'StockToken合约地址',
+    token_symbol    varchar(20)             not null comment '股票代码',
+    current_price   decimal(36) default 0   not null comment '成交价格(18位精度)',
+    token_amount    decimal(36) default 0   not null comment '代币数量(18位精度)',
+    currency_amount decimal(36) default 0   not null comment 'USDT数量(6位精度)',
+    block_number    bigint      default 0   not null comment '区块号',
+    tx_hash         varchar(66)             not null comment '交易hash',
+    log_index       int         default 0   not null comment '日志在区块中的序号',
+    event_time      bigint                  null comment '链上事件发生的时间',
+    create_time     bigint                  null comment '创建时间',
+    update_time     bigint                  null comment '更新时间',
+    constraint index_tx_log
+        unique (tx_hash, log_index)
+)
+    collate = utf8mb4_general_ci;
+
+create index index_user_symbol
+    on st_activity_sepolia (user_address, token_symbol);
+
+create index index_symbol_time
+    on st_activity_sepolia (token_symbol, event_time);
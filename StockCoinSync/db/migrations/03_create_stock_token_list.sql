@@ -0,0 +1,15 @@
+create table st_token_sepolia
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    token_address varchar(42)             not null comment 'StockToken合约地址',
+    name          varchar(100) default '' not null comment '代币名称',
+    symbol        varchar(20)             not null comment '股票代码',
+    block_number  bigint                  not null comment '创建区块号',
+    tx_hash       varchar(66)             not null comment '创建交易hash',
+    create_time   bigint                  null comment '创建时间',
+    update_time   bigint                  null comment '更新时间',
+    constraint index_token_address
+        unique (token_address)
+)
+    collate = utf8mb4_general_ci;
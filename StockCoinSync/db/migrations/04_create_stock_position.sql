@@ -0,0 +1,25 @@
+alter table st_activity_sepolia
+    add fee_amount decimal(36) default 0 not null comment '手续费折合USDT数量(6位精度)' after currency_amount;
+
+create table st_position_sepolia
+(
+    id                bigint auto_increment comment '主键'
+        primary key,
+    user_address      varchar(42)                not null comment '用户地址',
+    token_address     varchar(42)    default ''  not null comment 'StockToken合约地址',
+    token_symbol      varchar(20)                not null comment '股票代码',
+    quantity          decimal(36, 18) default 0  not null comment '持仓数量',
+    avg_cost          decimal(36, 18) default 0  not null comment '平均成本(USDT)',
+    cost_basis        decimal(36, 18) default 0  not null comment '持仓总成本(USDT)',
+    realized_pnl      decimal(36, 18) default 0  not null comment '已实现盈亏(USDT)',
+    fees_paid         decimal(36, 18) default 0  not null comment '累计手续费(USDT)',
+    buy_count         bigint          default 0  not null comment '买入次数',
+    sell_count        bigint          default 0  not null comment '卖出次数',
+    last_block_number bigint          default 0  not null comment '最后一次交易的区块号',
+    last_event_time   bigint          default 0  not null comment '最后一次交易的时间',
+    create_time       bigint                     null comment '创建时间',
+    update_time       bigint                     null comment '更新时间',
+    constraint index_user_symbol
+        unique (user_address, token_symbol)
+)
+    collate = utf8mb4_general_ci;
@@ -0,0 +1,57 @@
+create table st_transfer_sepolia
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    token_address varchar(42)           not null comment 'StockToken合约地址',
+    token_symbol  varchar(20)           not null comment '股票代码',
+    from_address  varchar(42)           not null comment '转出地址，铸造时为0地址',
+    to_address    varchar(42)           not null comment '转入地址，销毁时为0地址',
+    amount        decimal(65) default 0 not null comment '代币数量(18位精度)',
+    block_number  bigint                not null comment '区块号',
+    tx_hash       varchar(66)           not null comment '交易hash',
+    log_index     int         default 0 not null comment '日志在区块中的序号',
+    event_time    bigint                null comment '链上事件发生的时间',
+    create_time   bigint                null comment '创建时间',
+    update_time   bigint                null comment '更新时间',
+    constraint index_tx_log
+        unique (tx_hash, log_index)
+)
+    collate = utf8mb4_general_ci;
+
+create table st_balance_sepolia
+(
+    id                bigint auto_increment comment '主键'
+        primary key,
+    token_address     varchar(42)           not null comment 'StockToken合约地址',
+    token_symbol      varchar(20)           not null comment '股票代码',
+    holder_address    varchar(42)           not null comment '持有人地址',
+    balance           decimal(65) default 0 not null comment '余额(18位精度)',
+    last_block_number bigint      default 0 not null comment '最后一次变动的区块号',
+    create_time       bigint                null comment '创建时间',
+    update_time       bigint                null comment '更新时间',
+    constraint index_token_holder
+        unique (token_address, holder_address)
+)
+    collate = utf8mb4_general_ci;
+
+create index index_token_balance
+    on st_balance_sepolia (token_address, balance);
+
+create index index_holder
+    on st_balance_sepolia (holder_address);
+
+create table st_holder_count_sepolia
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    token_address varchar(42)      not null comment 'StockToken合约地址',
+    token_symbol  varchar(20)      not null comment '股票代码',
+    day           bigint           not null comment '当天0点(UTC)的时间戳',
+    holder_count  bigint default 0 not null comment '持有人数量',
+    block_number  bigint default 0 not null comment '统计时的区块号',
+    create_time   bigint           null comment '创建时间',
+    update_time   bigint           null comment '更新时间',
+    constraint index_token_day
+        unique (token_address, day)
+)
+    collate = utf8mb4_general_ci;
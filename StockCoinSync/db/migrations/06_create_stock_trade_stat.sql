@@ -0,0 +1,25 @@
+create table st_trade_stat_sepolia
+(
+    id            bigint auto_increment comment '主键'
+        primary key,
+    token_address varchar(42)     default '' not null comment 'StockToken合约地址',
+    token_symbol  varchar(20)                not null comment '股票代码',
+    volume_1h     decimal(36, 6)  default 0  not null comment '1小时成交额(USDT)',
+    volume_24h    decimal(36, 6)  default 0  not null comment '24小时成交额(USDT)',
+    volume_7d     decimal(36, 6)  default 0  not null comment '7天成交额(USDT)',
+    trades_1h     bigint          default 0  not null comment '1小时成交笔数',
+    trades_24h    bigint          default 0  not null comment '24小时成交笔数',
+    trades_7d     bigint          default 0  not null comment '7天成交笔数',
+    traders_1h    bigint          default 0  not null comment '1小时交易地址数',
+    traders_24h   bigint          default 0  not null comment '24小时交易地址数',
+    traders_7d    bigint          default 0  not null comment '7天交易地址数',
+    stat_time     bigint          default 0  not null comment '统计截止时间',
+    create_time   bigint                     null comment '创建时间',
+    update_time   bigint                     null comment '更新时间',
+    constraint index_symbol
+        unique (token_symbol)
+)
+    collate = utf8mb4_general_ci;
+
+create index index_event_time
+    on st_activity_sepolia (event_time);
@@ -29,6 +29,10 @@ type ContractCfg struct {
 	EthAddress  string `toml:"eth_address" mapstructure:"eth_address" json:"eth_address"`
 	WethAddress string `toml:"weth_address" mapstructure:"weth_address" json:"weth_address"`
 	DexAddress  string `toml:"dex_address" mapstructure:"dex_address" json:"dex_address"`
+	// TokenFactory合约地址，索引器通过其TokenCreated事件发现所有StockToken
+	TokenFactoryAddress string `toml:"token_factory_address" mapstructure:"token_factory_address" json:"token_factory_address"`
+	// StockToken买卖事件的起始同步区块（应不晚于TokenFactory的部署区块），仅在首次创建同步状态时使用
+	StockTokenStartBlock int64 `toml:"stock_token_start_block" mapstructure:"stock_token_start_block" json:"stock_token_start_block"`
 }
 
 type Monitor struct {
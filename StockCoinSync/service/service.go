@@ -17,6 +17,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/locey/CryptoStock/StockCoinSync/service/orderbookindexer"
+	"github.com/locey/CryptoStock/StockCoinSync/service/stocktokenindexer"
 
 	"github.com/locey/CryptoStock/StockCoinSync/model"
 	"github.com/locey/CryptoStock/StockCoinSync/service/collectionfilter"
@@ -24,13 +25,14 @@ import (
 )
 
 type Service struct {
-	ctx              context.Context
-	config           *config.Config
-	kvStore          *xkv.Store
-	db               *gorm.DB
-	wg               *sync.WaitGroup
-	collectionFilter *collectionfilter.Filter
-	orderbookIndexer *orderbookindexer.Service
+	ctx               context.Context
+	config            *config.Config
+	kvStore           *xkv.Store
+	db                *gorm.DB
+	wg                *sync.WaitGroup
+	collectionFilter  *collectionfilter.Filter
+	orderbookIndexer  *orderbookindexer.Service
+	stockTokenIndexer *stocktokenindexer.Service
 	//orderManager     *ordermanager.OrderManager
 }
 
@@ -54,6 +56,7 @@ func New(ctx context.Context, cfg *config.Config) (*Service, error) {
 	collectionFilter := collectionfilter.New(ctx, db, cfg.ChainCfg.Name, cfg.ProjectCfg.Name)
 	//orderManager := ordermanager.New(ctx, db, kvStore, cfg.ChainCfg.Name, cfg.ProjectCfg.Name)
 	var orderbookSyncer *orderbookindexer.Service
+	var stockTokenSyncer *stocktokenindexer.Service
 	var chainClient chainclient.ChainClient
 	fmt.Println("chainClient url:" + cfg.AnkrCfg.HttpsUrl + cfg.AnkrCfg.ApiKey)
 
@@ -66,17 +69,19 @@ func New(ctx context.Context, cfg *config.Config) (*Service, error) {
 	case chain.EthChainID, chain.OptimismChainID, chain.SepoliaChainID:
 		//orderbookSyncer = orderbookindexer.New(ctx, cfg, db, kvStore, chainClient, cfg.ChainCfg.ID, cfg.ChainCfg.Name, orderManager)
 		orderbookSyncer = orderbookindexer.New(ctx, cfg, db, kvStore, chainClient, cfg.ChainCfg.ID, cfg.ChainCfg.Name)
+		stockTokenSyncer = stocktokenindexer.New(ctx, cfg, db, kvStore, chainClient, cfg.ChainCfg.ID, cfg.ChainCfg.Name)
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed on create trade info server")
 	}
 	manager := Service{
-		ctx:              ctx,
-		config:           cfg,
-		db:               db,
-		kvStore:          kvStore,
-		collectionFilter: collectionFilter,
-		orderbookIndexer: orderbookSyncer,
+		ctx:               ctx,
+		config:            cfg,
+		db:                db,
+		kvStore:           kvStore,
+		collectionFilter:  collectionFilter,
+		orderbookIndexer:  orderbookSyncer,
+		stockTokenIndexer: stockTokenSyncer,
 		//orderManager:     orderManager,
 		wg: &sync.WaitGroup{},
 	}
@@ -90,6 +95,7 @@ func (s *Service) Start() error {
 	}
 
 	s.orderbookIndexer.Start()
+	s.stockTokenIndexer.Start()
 	//s.orderManager.Start()
 	return nil
 }
@@ -0,0 +1,179 @@
+package stocktokenindexer
+
+import (
+	"math/big"
+
+	"github.com/locey/CryptoStock/StockCoinBase/logger/xzap"
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	stockTokenDecimals = 18
+	usdtDecimals       = 6
+	priceDecimals      = 18
+
+	rebuildBatchSize = 1000
+)
+
+// 代币数量(18位)*价格(18位)换算为USDT(6位)时需要除以的精度
+var tradeScale = new(big.Int).Exp(big.NewInt(10), big.NewInt(stockTokenDecimals+priceDecimals-usdtDecimals), nil)
+
+// tradeFee 根据事件中的成交数量及价格还原手续费，折合为USDT（6位精度）
+// 买入手续费以代币收取：fee = usdtAmount*1e30/price - tokenAmount，再按成交价折合USDT；
+// 卖出手续费以USDT收取：fee = tokenAmount*price/1e30 - usdtAmount
+func tradeFee(activityType int, event *tradeEvent) *big.Int {
+	fee := new(big.Int)
+	if event.Price == nil || event.Price.Sign() <= 0 {
+		return fee
+	}
+
+	switch activityType {
+	case multi.Buy:
+		grossTokens := new(big.Int).Mul(event.UsdtAmount, tradeScale)
+		grossTokens.Quo(grossTokens, event.Price)
+		feeTokens := grossTokens.Sub(grossTokens, event.TokenAmount)
+		fee.Mul(feeTokens, event.Price)
+		fee.Quo(fee, tradeScale)
+	case multi.Sell:
+		fee.Mul(event.TokenAmount, event.Price)
+		fee.Quo(fee, tradeScale)
+		fee.Sub(fee, event.UsdtAmount)
+	}
+	if fee.Sign() < 0 {
+		return new(big.Int)
+	}
+	return fee
+}
+
+// applyTrade 将一笔买卖计入持仓，买入时成本包含支付的全部USDT（含手续费），
+// 卖出时按平均成本结转已实现盈亏；卖出数量超过已知持仓（例如代币由转账获得）时，
+// 超出部分没有成本记录，只按已知持仓部分计算盈亏
+func applyTrade(position *multi.Position, activity *multi.Activity) {
+	tokenAmount := activity.TokenAmount.Shift(-stockTokenDecimals)
+	usdtAmount := activity.CurrencyAmount.Shift(-usdtDecimals)
+	fee := activity.FeeAmount.Shift(-usdtDecimals)
+
+	switch activity.ActivityType {
+	case multi.Buy:
+		position.Quantity = position.Quantity.Add(tokenAmount)
+		position.CostBasis = position.CostBasis.Add(usdtAmount)
+		position.BuyCount++
+	case multi.Sell:
+		if tokenAmount.IsPositive() && position.Quantity.IsPositive() {
+			covered := decimal.Min(tokenAmount, position.Quantity)
+			costSold := position.AvgCost.Mul(covered)
+			proceeds := usdtAmount.Mul(covered).Div(tokenAmount)
+			position.RealizedPnl = position.RealizedPnl.Add(proceeds.Sub(costSold))
+			position.Quantity = position.Quantity.Sub(covered)
+			position.CostBasis = position.CostBasis.Sub(costSold)
+		}
+		position.SellCount++
+	}
+	position.FeesPaid = position.FeesPaid.Add(fee)
+
+	if position.Quantity.IsPositive() {
+		position.AvgCost = position.CostBasis.Div(position.Quantity)
+	} else {
+		position.Quantity = decimal.Zero
+		position.CostBasis = decimal.Zero
+		position.AvgCost = decimal.Zero
+	}
+	position.TokenAddress = activity.TokenAddress
+	position.LastBlockNumber = activity.BlockNumber
+	position.LastEventTime = activity.EventTime
+}
+
+// saveActivity 写入买卖记录并在同一事务中更新持仓；记录已存在时说明该事件已处理过，不再重复计入持仓
+func (s *Service) saveActivity(activity *multi.Activity) error {
+	return s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(multi.ActivityTableName(s.chain)).Clauses(clause.OnConflict{
+			DoNothing: true,
+		}).Create(activity)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "failed on create activity")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return s.updatePosition(tx, activity)
+	})
+}
+
+func (s *Service) updatePosition(tx *gorm.DB, activity *multi.Activity) error {
+	var position multi.Position
+	err := tx.Table(multi.PositionTableName(s.chain)).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_address = ? and token_symbol = ?", activity.UserAddress, activity.TokenSymbol).
+		First(&position).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Wrap(err, "failed on get position")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		position = multi.Position{
+			UserAddress: activity.UserAddress,
+			TokenSymbol: activity.TokenSymbol,
+		}
+	}
+
+	applyTrade(&position, activity)
+	if err := tx.Table(multi.PositionTableName(s.chain)).Save(&position).Error; err != nil {
+		return errors.Wrap(err, "failed on save position")
+	}
+	return nil
+}
+
+// rebuildPositions 持仓表为空而已有买卖记录时（例如持仓功能上线前已同步过的数据），按事件顺序重放生成持仓
+func (s *Service) rebuildPositions() error {
+	var positionCount int64
+	if err := s.db.WithContext(s.ctx).Table(multi.PositionTableName(s.chain)).
+		Count(&positionCount).Error; err != nil {
+		return errors.Wrap(err, "failed on count positions")
+	}
+	if positionCount > 0 {
+		return nil
+	}
+
+	return s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		positions := make(map[string]*multi.Position)
+		for offset := 0; ; offset += rebuildBatchSize {
+			var activities []multi.Activity
+			if err := tx.Table(multi.ActivityTableName(s.chain)).
+				Order("block_number asc, log_index asc, id asc").
+				Limit(rebuildBatchSize).Offset(offset).
+				Find(&activities).Error; err != nil {
+				return errors.Wrap(err, "failed on query activities")
+			}
+			for i := range activities {
+				activity := &activities[i]
+				key := activity.UserAddress + "/" + activity.TokenSymbol
+				position, ok := positions[key]
+				if !ok {
+					position = &multi.Position{UserAddress: activity.UserAddress, TokenSymbol: activity.TokenSymbol}
+					positions[key] = position
+				}
+				applyTrade(position, activity)
+			}
+			if len(activities) < rebuildBatchSize {
+				break
+			}
+		}
+		if len(positions) == 0 {
+			return nil
+		}
+
+		list := make([]*multi.Position, 0, len(positions))
+		for _, position := range positions {
+			list = append(list, position)
+		}
+		if err := tx.Table(multi.PositionTableName(s.chain)).CreateInBatches(list, 500).Error; err != nil {
+			return errors.Wrap(err, "failed on create positions")
+		}
+		xzap.WithContext(s.ctx).Info("rebuild stock positions from activities", zap.Int("count", len(list)))
+		return nil
+	})
+}
@@ -0,0 +1,119 @@
+package stocktokenindexer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/shopspring/decimal"
+)
+
+// units 将展示精度的数量转换为链上整数
+func units(amount string, decimals int32) *big.Int {
+	return decimal.RequireFromString(amount).Shift(decimals).BigInt()
+}
+
+func TestTradeFee(t *testing.T) {
+	tests := []struct {
+		name         string
+		activityType int
+		event        tradeEvent
+		want         string // USDT（6位精度）
+	}{
+		{
+			// 投入1000 USDT、价格200，未扣手续费应得5个代币，实得4.985个，手续费0.015个代币折合3 USDT
+			name:         "buy fee charged in tokens",
+			activityType: multi.Buy,
+			event:        tradeEvent{UsdtAmount: units("1000", usdtDecimals), TokenAmount: units("4.985", stockTokenDecimals), Price: units("200", priceDecimals)},
+			want:         "3",
+		},
+		{
+			name:         "sell fee charged in usdt",
+			activityType: multi.Sell,
+			event:        tradeEvent{UsdtAmount: units("997", usdtDecimals), TokenAmount: units("5", stockTokenDecimals), Price: units("200", priceDecimals)},
+			want:         "3",
+		},
+		{
+			name:         "missing price",
+			activityType: multi.Buy,
+			event:        tradeEvent{UsdtAmount: units("1000", usdtDecimals), TokenAmount: units("5", stockTokenDecimals)},
+			want:         "0",
+		},
+		{
+			// 成交价与实际数量不一致导致负数时按0处理
+			name:         "negative fee",
+			activityType: multi.Sell,
+			event:        tradeEvent{UsdtAmount: units("1001", usdtDecimals), TokenAmount: units("5", stockTokenDecimals), Price: units("200", priceDecimals)},
+			want:         "0",
+		},
+	}
+	for _, tt := range tests {
+		got := tradeFee(tt.activityType, &tt.event)
+		if want := units(tt.want, usdtDecimals); got.Cmp(want) != 0 {
+			t.Errorf("%s: tradeFee = %s, want %s", tt.name, got, want)
+		}
+	}
+}
+
+func testActivity(activityType int, tokenAmount, usdtAmount, fee string, blockNumber int64) *multi.Activity {
+	return &multi.Activity{
+		ActivityType:   activityType,
+		TokenAddress:   "0x0000000000000000000000000000000000000001",
+		TokenSymbol:    "AAPL",
+		TokenAmount:    decimal.RequireFromString(tokenAmount).Shift(stockTokenDecimals),
+		CurrencyAmount: decimal.RequireFromString(usdtAmount).Shift(usdtDecimals),
+		FeeAmount:      decimal.RequireFromString(fee).Shift(usdtDecimals),
+		BlockNumber:    blockNumber,
+		EventTime:      blockNumber * 12,
+	}
+}
+
+func TestApplyTrade(t *testing.T) {
+	steps := []struct {
+		name        string
+		activity    *multi.Activity
+		quantity    string
+		costBasis   string
+		avgCost     string
+		realizedPnl string
+		feesPaid    string
+	}{
+		{"first buy includes fee in cost", testActivity(multi.Buy, "10", "1000", "3", 1), "10", "1000", "100", "0", "3"},
+		{"second buy averages cost", testActivity(multi.Buy, "10", "1500", "4.5", 2), "20", "2500", "125", "0", "7.5"},
+		{"partial sell realizes pnl at average cost", testActivity(multi.Sell, "5", "750", "2", 3), "15", "1875", "125", "125", "9.5"},
+		// 卖出20个但只有15个已知持仓，超出部分没有成本，只按15个计算盈亏
+		{"sell more than holdings", testActivity(multi.Sell, "20", "2000", "6", 4), "0", "0", "0", "-250", "15.5"},
+		{"sell without holdings only counts fee", testActivity(multi.Sell, "1", "100", "0.3", 5), "0", "0", "0", "-250", "15.8"},
+	}
+
+	position := &multi.Position{UserAddress: "0xuser", TokenSymbol: "AAPL"}
+	for _, step := range steps {
+		applyTrade(position, step.activity)
+
+		for _, check := range []struct {
+			field string
+			got   decimal.Decimal
+			want  string
+		}{
+			{"quantity", position.Quantity, step.quantity},
+			{"cost basis", position.CostBasis, step.costBasis},
+			{"avg cost", position.AvgCost, step.avgCost},
+			{"realized pnl", position.RealizedPnl, step.realizedPnl},
+			{"fees paid", position.FeesPaid, step.feesPaid},
+		} {
+			if !check.got.Equal(decimal.RequireFromString(check.want)) {
+				t.Errorf("%s: %s = %s, want %s", step.name, check.field, check.got, check.want)
+			}
+		}
+		if position.LastBlockNumber != step.activity.BlockNumber || position.LastEventTime != step.activity.EventTime {
+			t.Errorf("%s: last block %d/%d not updated", step.name, position.LastBlockNumber, position.LastEventTime)
+		}
+	}
+
+	if position.BuyCount != 2 || position.SellCount != 3 {
+		t.Errorf("buy/sell count = %d/%d, want 2/3", position.BuyCount, position.SellCount)
+	}
+	if position.TokenAddress != "0x0000000000000000000000000000000000000001" {
+		t.Errorf("token address not recorded: %s", position.TokenAddress)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -12,8 +13,8 @@ import (
 	"github.com/locey/CryptoStock/StockCoinBase/chain/chainclient"
 	"github.com/locey/CryptoStock/StockCoinBase/chain/types"
 	"github.com/locey/CryptoStock/StockCoinBase/logger/xzap"
+	"github.com/pkg/errors"
 
-	//"github.com/locey/CryptoStock/StockCoinBase/ordermanager"
 	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/base"
 	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
 	"github.com/locey/CryptoStock/StockCoinBase/stores/xkv"
@@ -27,40 +28,39 @@ import (
 )
 
 const (
-	EventIndexType    = 0  //合约的事件类型 0为StockToken合约事件
-	SleepInterval     = 10 // in seconds
-	SyncBlockPeriod   = 10
-	LogPurchasedTopic = "0x56a4595ce78f0d5bdfa9a7bae14f8fc1a12cc8dc813877b02629396412a1599f" //keccak256("TokenPurchased(address,string,uint256,uint256,uint256)");
-	LogTokenSoldTopic = "0x1bd9eda01c8e8bf59e44853566034cca1fd20f1138ac11e9a2405999592ec0d7" ///keccak256("TokenSold(address,string,uint256,uint256,uint256)");
-	contractAbi       = `[{"inputs":[],"name":"CannotFindNextEmptyKey","type":"error"},{"inputs":[],"name":"CannotFindPrevEmptyKey","type":"error"},{"inputs":[{"internalType":"OrderKey","name":"orderKey","type":"bytes32"}],"name":"CannotInsertDuplicateOrder","type":"error"},{"inputs":[],"name":"CannotInsertEmptyKey","type":"error"},{"inputs":[],"name":"CannotInsertExistingKey","type":"error"},{"inputs":[],"name":"CannotRemoveEmptyKey","type":"error"},{"inputs":[],"name":"CannotRemoveMissingKey","type":"error"},{"inputs":[],"name":"EnforcedPause","type":"error"},{"inputs":[],"name":"ExpectedPause","type":"error"},{"inputs":[],"name":"InvalidInitialization","type":"error"},{"inputs":[],"name":"NotInitializing","type":"error"},{"inputs":[{"internalType":"address","name":"owner","type":"address"}],"name":"OwnableInvalidOwner","type":"error"},{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"OwnableUnauthorizedAccount","type":"error"},{"inputs":[],"name":"ReentrancyGuardReentrantCall","type":"error"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint256","name":"offset","type":"uint256"},{"indexed":false,"internalType":"bytes","name":"msg","type":"bytes"}],"name":"BatchMatchInnerError","type":"event"},{"anonymous":false,"inputs":[],"name":"EIP712DomainChanged","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint64","name":"version","type":"uint64"}],"name":"Initialized","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"OrderKey","name":"orderKey","type":"bytes32"},{"indexed":true,"internalType":"address","name":"maker","type":"address"}],"name":"LogCancel","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"OrderKey","name":"orderKey","type":"bytes32"},{"indexed":true,"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"indexed":true,"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"indexed":true,"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"indexed":false,"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"indexed":false,"internalType":"Price","name":"price","type":"uint128"},{"indexed":false,"internalType":"uint64","name":"expiry","type":"uint64"},{"indexed":false,"internalType":"uint64","name":"salt","type":"uint64"}],"name":"LogMake","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"OrderKey","name":"makeOrderKey","type":"bytes32"},{"indexed":true,"internalType":"OrderKey","name":"takeOrderKey","type":"bytes32"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"indexed":false,"internalType":"structLibOrder.Order","name":"makeOrder","type":"tuple"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"indexed":false,"internalType":"structLibOrder.Order","name":"takeOrder","type":"tuple"},{"indexed":false,"internalType":"uint128","name":"fillPrice","type":"uint128"}],"name":"LogMatch","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"OrderKey","name":"orderKey","type":"bytes32"},{"indexed":false,"internalType":"uint64","name":"salt","type":"uint64"}],"name":"LogSkipOrder","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"uint128","name":"newProtocolShare","type":"uint128"}],"name":"LogUpdatedProtocolShare","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"address","name":"recipient","type":"address"},{"indexed":false,"internalType":"uint256","name":"amount","type":"uint256"}],"name":"LogWithdrawETH","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"previousOwner","type":"address"},{"indexed":true,"internalType":"address","name":"newOwner","type":"address"}],"name":"OwnershipTransferred","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"address","name":"account","type":"address"}],"name":"Paused","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"address","name":"account","type":"address"}],"name":"Unpaused","type":"event"},{"inputs":[{"internalType":"OrderKey[]","name":"orderKeys","type":"bytes32[]"}],"name":"cancelOrders","outputs":[{"internalType":"bool[]","name":"successes","type":"bool[]"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"components":[{"internalType":"OrderKey","name":"oldOrderKey","type":"bytes32"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"newOrder","type":"tuple"}],"internalType":"structLibOrder.EditDetail[]","name":"editDetails","type":"tuple[]"}],"name":"editOrders","outputs":[{"internalType":"OrderKey[]","name":"newOrderKeys","type":"bytes32[]"}],"stateMutability":"payable","type":"function"},{"inputs":[],"name":"eip712Domain","outputs":[{"internalType":"bytes1","name":"fields","type":"bytes1"},{"internalType":"string","name":"name","type":"string"},{"internalType":"string","name":"version","type":"string"},{"internalType":"uint256","name":"chainId","type":"uint256"},{"internalType":"address","name":"verifyingContract","type":"address"},{"internalType":"bytes32","name":"salt","type":"bytes32"},{"internalType":"uint256[]","name":"extensions","type":"uint256[]"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"OrderKey","name":"","type":"bytes32"}],"name":"filledAmount","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"}],"name":"getBestOrder","outputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"orderResult","type":"tuple"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"collection","type":"address"},{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"}],"name":"getBestPrice","outputs":[{"internalType":"Price","name":"price","type":"uint128"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"collection","type":"address"},{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"Price","name":"price","type":"uint128"}],"name":"getNextBestPrice","outputs":[{"internalType":"Price","name":"nextBestPrice","type":"uint128"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"uint256","name":"count","type":"uint256"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"OrderKey","name":"firstOrderKey","type":"bytes32"}],"name":"getOrders","outputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order[]","name":"resultOrders","type":"tuple[]"},{"internalType":"OrderKey","name":"nextOrderKey","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint128","name":"newProtocolShare","type":"uint128"},{"internalType":"address","name":"newVault","type":"address"},{"internalType":"string","name":"EIP712Name","type":"string"},{"internalType":"string","name":"EIP712Version","type":"string"}],"name":"initialize","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order[]","name":"newOrders","type":"tuple[]"}],"name":"makeOrders","outputs":[{"internalType":"OrderKey[]","name":"newOrderKeys","type":"bytes32[]"}],"stateMutability":"payable","type":"function"},{"inputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"sellOrder","type":"tuple"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"buyOrder","type":"tuple"}],"name":"matchOrder","outputs":[],"stateMutability":"payable","type":"function"},{"inputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"sellOrder","type":"tuple"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"buyOrder","type":"tuple"},{"internalType":"uint256","name":"msgValue","type":"uint256"}],"name":"matchOrderWithoutPayback","outputs":[{"internalType":"uint128","name":"costValue","type":"uint128"}],"stateMutability":"payable","type":"function"},{"inputs":[{"components":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"sellOrder","type":"tuple"},{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"buyOrder","type":"tuple"}],"internalType":"structLibOrder.MatchDetail[]","name":"matchDetails","type":"tuple[]"}],"name":"matchOrders","outputs":[{"internalType":"bool[]","name":"successes","type":"bool[]"}],"stateMutability":"payable","type":"function"},{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"enumLibOrder.Side","name":"","type":"uint8"},{"internalType":"Price","name":"","type":"uint128"}],"name":"orderQueues","outputs":[{"internalType":"OrderKey","name":"head","type":"bytes32"},{"internalType":"OrderKey","name":"tail","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"OrderKey","name":"","type":"bytes32"}],"name":"orders","outputs":[{"components":[{"internalType":"enumLibOrder.Side","name":"side","type":"uint8"},{"internalType":"enumLibOrder.SaleKind","name":"saleKind","type":"uint8"},{"internalType":"address","name":"maker","type":"address"},{"components":[{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"collection","type":"address"},{"internalType":"uint96","name":"amount","type":"uint96"}],"internalType":"structLibOrder.Asset","name":"nft","type":"tuple"},{"internalType":"Price","name":"price","type":"uint128"},{"internalType":"uint64","name":"expiry","type":"uint64"},{"internalType":"uint64","name":"salt","type":"uint64"}],"internalType":"structLibOrder.Order","name":"order","type":"tuple"},{"internalType":"OrderKey","name":"next","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"owner","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"paused","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"enumLibOrder.Side","name":"","type":"uint8"}],"name":"priceTrees","outputs":[{"internalType":"Price","name":"root","type":"uint128"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"protocolShare","outputs":[{"internalType":"uint128","name":"","type":"uint128"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"renounceOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint128","name":"newProtocolShare","type":"uint128"}],"name":"setProtocolShare","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"newVault","type":"address"}],"name":"setVault","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"newOwner","type":"address"}],"name":"transferOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"unpause","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"recipient","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"withdrawETH","outputs":[],"stateMutability":"nonpayable","type":"function"},{"stateMutability":"payable","type":"receive"}]`
-	HexPrefix         = "0x"
-	ZeroAddress       = "0x0000000000000000000000000000000000000000"
+	EventIndexType       = base.TypeStockTokenEventIndex //合约的事件类型 0为StockToken合约事件
+	SleepInterval        = 10                            // in seconds
+	SyncBlockPeriod      = 10
+	LogPurchasedTopic    = "0x56a4595ce78f0d5bdfa9a7bae14f8fc1a12cc8dc813877b02629396412a1599f" //keccak256("TokenPurchased(address,string,uint256,uint256,uint256)");
+	LogTokenSoldTopic    = "0x1bd9eda01c8e8bf59e44853566034cca1fd20f1138ac11e9a2405999592ec0d7" //keccak256("TokenSold(address,string,uint256,uint256,uint256)");
+	LogTokenCreatedTopic = "0xffc04f682c7b287e4b552dacd4b833d7c33dc0549cd6da84388408e4830c0562" //keccak256("TokenCreated(address,string,string)");
+	LogTransferTopic     = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" //keccak256("Transfer(address,address,uint256)");
+	// StockToken及TokenFactory中需要解析的事件
+	contractAbi = `[{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"buyer","type":"address"},{"indexed":false,"internalType":"string","name":"stockSymbol","type":"string"},{"indexed":false,"internalType":"uint256","name":"usdtAmount","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"tokenAmount","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"price","type":"uint256"}],"name":"TokenPurchased","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"seller","type":"address"},{"indexed":false,"internalType":"string","name":"stockSymbol","type":"string"},{"indexed":false,"internalType":"uint256","name":"tokenAmount","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"usdtAmount","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"price","type":"uint256"}],"name":"TokenSold","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"tokenAddress","type":"address"},{"indexed":false,"internalType":"string","name":"name","type":"string"},{"indexed":false,"internalType":"string","name":"symbol","type":"string"}],"name":"TokenCreated","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"from","type":"address"},{"indexed":true,"internalType":"address","name":"to","type":"address"},{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+	HexPrefix   = "0x"
+	ZeroAddress = "0x0000000000000000000000000000000000000000"
 )
 
-type Order struct {
-	Side     uint8
-	SaleKind uint8
-	Maker    common.Address
-	Nft      struct {
-		TokenId        *big.Int
-		CollectionAddr common.Address
-		Amount         *big.Int
-	}
-	Price  *big.Int
-	Expiry uint64
-	Salt   uint64
+// tradeEvent TokenPurchased/TokenSold的非indexed字段
+type tradeEvent struct {
+	StockSymbol string
+	UsdtAmount  *big.Int
+	TokenAmount *big.Int
+	Price       *big.Int
 }
 
 type Service struct {
-	ctx context.Context
-	cfg *config.Config
-	db  *gorm.DB
-	kv  *xkv.Store
-	//orderManager *ordermanager.OrderManager
+	ctx         context.Context
+	cfg         *config.Config
+	db          *gorm.DB
+	kv          *xkv.Store
 	chainClient chainclient.ChainClient
 	chainId     int64
 	chain       string
 	parsedAbi   abi.ABI
+
+	mu     sync.RWMutex
+	tokens map[common.Address]string // 已发现的StockToken合约地址 -> 股票代码
 }
 
 var MultiChainMaxBlockDifference = map[string]uint64{
@@ -72,7 +72,6 @@ var MultiChainMaxBlockDifference = map[string]uint64{
 	"zksync-era": 2,
 }
 
-// func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, chainClient chainclient.ChainClient, chainId int64, chain string, orderManager *ordermanager.OrderManager) *Service {
 func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, chainClient chainclient.ChainClient, chainId int64, chain string) *Service {
 	parsedAbi, _ := abi.JSON(strings.NewReader(contractAbi)) // 通过ABI实例化
 	return &Service{
@@ -81,28 +80,65 @@ func New(ctx context.Context, cfg *config.Config, db *gorm.DB, xkv *xkv.Store, c
 		db:          db,
 		kv:          xkv,
 		chainClient: chainClient,
-		//orderManager: orderManager,
-		chain:     chain,
-		chainId:   chainId,
-		parsedAbi: parsedAbi,
+		chain:       chain,
+		chainId:     chainId,
+		parsedAbi:   parsedAbi,
+		tokens:      make(map[common.Address]string),
 	}
 }
 
 func (s *Service) Start() {
 	threading.GoSafe(s.SyncStockTokenEventLoop)
+	threading.GoSafe(s.TradeStatLoop)
+}
+
+// TokenAddresses 返回当前已发现的所有StockToken合约地址
+func (s *Service) TokenAddresses() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	addresses := make([]string, 0, len(s.tokens))
+	for address := range s.tokens {
+		addresses = append(addresses, address.String())
+	}
+	return addresses
 }
 
 func (s *Service) SyncStockTokenEventLoop() {
-	var indexedStatus base.IndexedStatus
+	// 未配置TokenFactory时无法发现任何代币，若继续推进同步区块会跳过TokenCreated事件所在区块
+	if s.cfg.ContractCfg.TokenFactoryAddress == "" {
+		xzap.WithContext(s.ctx).Error("token factory address is not configured, stock token indexer not started")
+		return
+	}
+
+	// 首次启动时创建本索引器的同步状态，从配置的起始区块开始同步
+	indexedStatus := base.IndexedStatus{
+		ChainId:          int(s.chainId),
+		IndexType:        EventIndexType,
+		LastIndexedBlock: s.cfg.ContractCfg.StockTokenStartBlock,
+	}
 	if err := s.db.WithContext(s.ctx).Table(base.IndexedStatusTableName()).
 		Where("chain_id = ? and index_type = ?", s.chainId, EventIndexType).
-		First(&indexedStatus).Error; err != nil {
-		xzap.WithContext(s.ctx).Error("failed on get listing index status",
+		FirstOrCreate(&indexedStatus).Error; err != nil {
+		xzap.WithContext(s.ctx).Error("failed on get stock token index status",
 			zap.Error(err))
 		return
 	}
 
+	if err := s.loadTokens(); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on load stock tokens", zap.Error(err))
+		return
+	}
+
+	if err := s.rebuildPositions(); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on rebuild stock positions", zap.Error(err))
+		return
+	}
+
 	lastSyncBlock := uint64(indexedStatus.LastIndexedBlock)
+	if err := s.backfillBalances(lastSyncBlock); err != nil {
+		xzap.WithContext(s.ctx).Error("failed on backfill stock token balances", zap.Error(err))
+		return
+	}
 	for {
 		select {
 		case <-s.ctx.Done():
@@ -129,122 +165,189 @@ func (s *Service) SyncStockTokenEventLoop() {
 			endBlock = currentBlockNum - MultiChainMaxBlockDifference[s.chain]
 		}
 
-		query := types.FilterQuery{
-			FromBlock: new(big.Int).SetUint64(startBlock),
-			ToBlock:   new(big.Int).SetUint64(endBlock),
-			Addresses: []string{s.cfg.ContractCfg.DexAddress},
-		}
-
-		logs, err := s.chainClient.FilterLogs(s.ctx, query) //同时获取多个（SyncBlockPeriod）区块的日志
-		if err != nil {
-			xzap.WithContext(s.ctx).Error("failed on get log", zap.Error(err))
+		// 任一步骤失败都不推进同步区块，下一轮重新处理整个区间，写入均为幂等操作
+		if err := s.syncBlocks(startBlock, endBlock); err != nil {
+			xzap.WithContext(s.ctx).Error("failed on sync stock token event",
+				zap.Uint64("start_block", startBlock),
+				zap.Uint64("end_block", endBlock),
+				zap.Error(err))
 			time.Sleep(SleepInterval * time.Second)
 			continue
 		}
 
-		for _, log := range logs { // 遍历日志，根据不同的topic处理不同的事件
-			ethLog := log.(ethereumTypes.Log)
-			switch ethLog.Topics[0].String() {
-			case LogPurchasedTopic:
-				s.handlePurchasedEvent(ethLog)
-			case LogTokenSoldTopic:
-				s.handleTokenSoldEvent(ethLog)
-			default:
-			}
-		}
-
 		lastSyncBlock = endBlock + 1 // 更新最后同步的区块高度
 		if err := s.db.WithContext(s.ctx).Table(base.IndexedStatusTableName()).
 			Where("chain_id = ? and index_type = ?", s.chainId, EventIndexType).
-			Update("last_indexed_block", lastSyncBlock).Error; err != nil {
-			xzap.WithContext(s.ctx).Error("failed on update orderbook event sync block number",
+			Updates(map[string]interface{}{
+				"last_indexed_block": lastSyncBlock,
+				"last_indexed_time":  time.Now().Unix(),
+			}).Error; err != nil {
+			xzap.WithContext(s.ctx).Error("failed on update stock token event sync block number",
 				zap.Error(err))
 			return
 		}
 
-		xzap.WithContext(s.ctx).Info("sync orderbook event ...",
+		xzap.WithContext(s.ctx).Info("sync stock token event ...",
 			zap.Uint64("start_block", startBlock),
 			zap.Uint64("end_block", endBlock))
 	}
 }
 
-// 处理挂单事件
-func (s *Service) handlePurchasedEvent(log ethereumTypes.Log) {
-	var event struct {
-		UsdtAmount  *big.Int
-		TokenAmount *big.Int
-		Price       *big.Int
+// syncBlocks 先处理TokenFactory的TokenCreated事件更新代币集合，再拉取所有代币的买卖及转账事件，
+// 保证同一区间内新创建代币的交易也能被同步
+func (s *Service) syncBlocks(startBlock, endBlock uint64) error {
+	blockTimes := make(map[uint64]uint64)
+
+	factoryLogs, err := s.chainClient.FilterLogs(s.ctx, types.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(startBlock),
+		ToBlock:   new(big.Int).SetUint64(endBlock),
+		Addresses: []string{s.cfg.ContractCfg.TokenFactoryAddress},
+		Topics:    [][]string{{LogTokenCreatedTopic}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed on get token factory log")
+	}
+	for _, log := range factoryLogs {
+		if err := s.handleTokenCreatedEvent(log.(ethereumTypes.Log)); err != nil {
+			return err
+		}
 	}
 
-	// Unpack data
-	err := s.parsedAbi.UnpackIntoInterface(&event, "TokenPurchased", log.Data) // 通过ABI解析日志数据
+	addresses := s.TokenAddresses()
+	if len(addresses) == 0 {
+		return nil
+	}
+	logs, err := s.chainClient.FilterLogs(s.ctx, types.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(startBlock),
+		ToBlock:   new(big.Int).SetUint64(endBlock),
+		Addresses: addresses,
+		Topics:    [][]string{{LogPurchasedTopic, LogTokenSoldTopic, LogTransferTopic}},
+	}) //同时获取多个（SyncBlockPeriod）区块的日志
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error unpacking TokenPurchased event:", zap.Error(err))
-		return
+		return errors.Wrap(err, "failed on get stock token log")
+	}
+
+	touched := make(map[common.Address]*touchedToken)
+	for _, log := range logs { // 遍历日志，根据不同的topic处理不同的事件
+		ethLog := log.(ethereumTypes.Log)
+		if ethLog.Removed {
+			continue
+		}
+		var err error
+		switch ethLog.Topics[0].String() {
+		case LogPurchasedTopic:
+			err = s.handleTradeEvent(ethLog, "TokenPurchased", multi.Buy, blockTimes)
+		case LogTokenSoldTopic:
+			err = s.handleTradeEvent(ethLog, "TokenSold", multi.Sell, blockTimes)
+		case LogTransferTopic:
+			err = s.handleTransferEvent(ethLog, blockTimes, touched)
+		default:
+		}
+		if err != nil {
+			return err
+		}
 	}
-	// Extract indexed fields from topics
-	buyer := common.BytesToAddress(log.Topics[1].Bytes())
-	stockSymbol := string(log.Topics[2].Bytes())
-	blockTime, err := s.chainClient.BlockTimeByNumber(s.ctx, big.NewInt(int64(log.BlockNumber)))
+	return s.updateHolderCounts(touched)
+}
+
+// blockTime 获取区块时间，同一区间内的区块时间只查询一次
+func (s *Service) blockTime(blockNumber uint64, blockTimes map[uint64]uint64) (uint64, error) {
+	if blockTime, ok := blockTimes[blockNumber]; ok {
+		return blockTime, nil
+	}
+	blockTime, err := s.chainClient.BlockTimeByNumber(s.ctx, new(big.Int).SetUint64(blockNumber))
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("failed to get block time", zap.Error(err))
-		return
+		return 0, errors.Wrap(err, "failed to get block time")
 	}
-	newActivity := multi.Activity{ // 将订单信息存入活动表
-		ActivityType:   multi.Buy,
-		UserAddress:    buyer.String(),
-		TokenSymbol:    stockSymbol,
-		CurrentPrice:   decimal.NewFromBigInt(event.Price, 0),
-		TokenAmount:    decimal.NewFromBigInt(event.TokenAmount, 0),
-		CurrencyAmount: decimal.NewFromBigInt(event.UsdtAmount, 0),
-		BlockNumber:    int64(log.BlockNumber),
-		TxHash:         log.TxHash.String(),
-		EventTime:      int64(blockTime),
+	blockTimes[blockNumber] = blockTime
+	return blockTime, nil
+}
+
+// loadTokens 加载之前已发现的StockToken
+func (s *Service) loadTokens() error {
+	var tokens []multi.Token
+	if err := s.db.WithContext(s.ctx).Table(multi.TokenTableName(s.chain)).
+		Find(&tokens).Error; err != nil {
+		return errors.Wrap(err, "failed on query stock tokens")
 	}
-	if err := s.db.WithContext(s.ctx).Table(multi.ActivityTableName(s.chain)).Clauses(clause.OnConflict{
-		DoNothing: true,
-	}).Create(&newActivity).Error; err != nil {
-		xzap.WithContext(s.ctx).Warn("failed on create activity",
-			zap.Error(err))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokens {
+		s.tokens[common.HexToAddress(token.TokenAddress)] = token.Symbol
 	}
+	return nil
 }
 
-func (s *Service) handleTokenSoldEvent(log ethereumTypes.Log) {
+// handleTokenCreatedEvent 记录TokenFactory新创建的代币
+func (s *Service) handleTokenCreatedEvent(log ethereumTypes.Log) error {
+	if log.Removed || len(log.Topics) < 2 {
+		return nil
+	}
+
 	var event struct {
-		UsdtAmount  *big.Int
-		TokenAmount *big.Int
-		Price       *big.Int
+		Name   string
+		Symbol string
 	}
+	if err := s.parsedAbi.UnpackIntoInterface(&event, "TokenCreated", log.Data); err != nil {
+		return errors.Wrap(err, "failed on unpack TokenCreated event")
+	}
+	tokenAddress := common.BytesToAddress(log.Topics[1].Bytes())
 
-	// Unpack data
-	err := s.parsedAbi.UnpackIntoInterface(&event, "TokenPurchased", log.Data) // 通过ABI解析日志数据
-	if err != nil {
-		xzap.WithContext(s.ctx).Error("Error unpacking TokenPurchased event:", zap.Error(err))
-		return
+	token := multi.Token{
+		TokenAddress: tokenAddress.String(),
+		Name:         event.Name,
+		Symbol:       event.Symbol,
+		BlockNumber:  int64(log.BlockNumber),
+		TxHash:       log.TxHash.String(),
+	}
+	if err := s.db.WithContext(s.ctx).Table(multi.TokenTableName(s.chain)).Clauses(clause.OnConflict{
+		DoNothing: true,
+	}).Create(&token).Error; err != nil {
+		return errors.Wrap(err, "failed on create stock token")
+	}
+
+	s.mu.Lock()
+	s.tokens[tokenAddress] = event.Symbol
+	s.mu.Unlock()
+
+	xzap.WithContext(s.ctx).Info("found new stock token",
+		zap.String("token_address", token.TokenAddress),
+		zap.String("symbol", token.Symbol))
+	return nil
+}
+
+// handleTradeEvent 处理买入、卖出事件，写入活动表并更新用户持仓
+func (s *Service) handleTradeEvent(log ethereumTypes.Log, eventName string, activityType int, blockTimes map[uint64]uint64) error {
+	if len(log.Topics) < 2 {
+		return nil
+	}
+
+	var event tradeEvent
+	if err := s.parsedAbi.UnpackIntoInterface(&event, eventName, log.Data); err != nil { // 通过ABI解析日志数据
+		return errors.Wrapf(err, "failed on unpack %s event", eventName)
 	}
-	// Extract indexed fields from topics
-	seller := common.BytesToAddress(log.Topics[1].Bytes())
-	stockSymbol := string(log.Topics[2].Bytes())
-	blockTime, err := s.chainClient.BlockTimeByNumber(s.ctx, big.NewInt(int64(log.BlockNumber)))
+	// buyer/seller为indexed字段，stockSymbol在data中
+	user := common.BytesToAddress(log.Topics[1].Bytes())
+
+	blockTime, err := s.blockTime(log.BlockNumber, blockTimes)
 	if err != nil {
-		xzap.WithContext(s.ctx).Error("failed to get block time", zap.Error(err))
-		return
+		return err
 	}
-	newActivity := multi.Activity{ // 将股票信息存入活动表
-		ActivityType:   multi.Sell,
-		UserAddress:    seller.String(),
-		TokenSymbol:    stockSymbol,
+
+	newActivity := multi.Activity{ // 将买卖信息存入活动表
+		ActivityType:   activityType,
+		UserAddress:    user.String(),
+		TokenAddress:   log.Address.String(),
+		TokenSymbol:    event.StockSymbol,
 		CurrentPrice:   decimal.NewFromBigInt(event.Price, 0),
 		TokenAmount:    decimal.NewFromBigInt(event.TokenAmount, 0),
 		CurrencyAmount: decimal.NewFromBigInt(event.UsdtAmount, 0),
+		FeeAmount:      decimal.NewFromBigInt(tradeFee(activityType, &event), 0),
 		BlockNumber:    int64(log.BlockNumber),
 		TxHash:         log.TxHash.String(),
+		LogIndex:       int64(log.Index),
 		EventTime:      int64(blockTime),
 	}
-	if err := s.db.WithContext(s.ctx).Table(multi.ActivityTableName(s.chain)).Clauses(clause.OnConflict{
-		DoNothing: true,
-	}).Create(&newActivity).Error; err != nil {
-		xzap.WithContext(s.ctx).Warn("failed on create activity",
-			zap.Error(err))
-	}
+	return s.saveActivity(&newActivity)
 }
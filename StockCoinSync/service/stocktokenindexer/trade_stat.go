@@ -0,0 +1,144 @@
+package stocktokenindexer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/locey/CryptoStock/StockCoinBase/logger/xzap"
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	TradeStatInterval = 60 // in seconds
+
+	// 链上成交统计的时间窗口（秒）
+	tradeStatHour = 3600
+	tradeStatDay  = 24 * tradeStatHour
+	tradeStatWeek = 7 * tradeStatDay
+)
+
+// TradeStatLoop 定时汇总每个股票代币最近1小时、24小时、7天的链上成交额、成交笔数及交易地址数
+func (s *Service) TradeStatLoop() {
+	ticker := time.NewTicker(TradeStatInterval * time.Second)
+	defer ticker.Stop()
+	for {
+		if err := s.updateTradeStats(time.Now().Unix()); err != nil {
+			xzap.WithContext(s.ctx).Error("failed on update stock trade stats", zap.Error(err))
+		}
+
+		select {
+		case <-s.ctx.Done():
+			xzap.WithContext(s.ctx).Info("TradeStatLoop stopped due to context cancellation")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateTradeStats 按now之前的买卖记录重新统计各时间窗口，最近7天没有成交的代币统计清零
+func (s *Service) updateTradeStats(now int64) error {
+	var activities []multi.Activity
+	if err := s.db.WithContext(s.ctx).Table(multi.ActivityTableName(s.chain)).
+		Select("token_symbol, token_address, user_address, currency_amount, event_time").
+		Where("event_time > ? and event_time <= ?", now-tradeStatWeek, now).
+		Find(&activities).Error; err != nil {
+		return errors.Wrap(err, "failed on query activities")
+	}
+
+	var previous []multi.TradeStat
+	if err := s.db.WithContext(s.ctx).Table(multi.TradeStatTableName(s.chain)).
+		Select("token_symbol, token_address").
+		Find(&previous).Error; err != nil {
+		return errors.Wrap(err, "failed on query trade stats")
+	}
+
+	stats := aggregateTradeStats(activities, previous, now)
+	if len(stats) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(s.ctx).Table(multi.TradeStatTableName(s.chain)).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token_symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"token_address", "volume_1h", "volume_24h", "volume_7d",
+			"trades_1h", "trades_24h", "trades_7d",
+			"traders_1h", "traders_24h", "traders_7d",
+			"stat_time", "update_time",
+		}),
+	}).Create(&stats).Error; err != nil {
+		return errors.Wrap(err, "failed on save trade stats")
+	}
+	return nil
+}
+
+// aggregateTradeStats 将最近7天的买卖记录按(now-窗口, now]汇总为各代币的1小时、24小时、7天统计，
+// previous中已有统计但窗口内没有成交的代币返回清零的统计；结果按代币代码排序
+func aggregateTradeStats(activities []multi.Activity, previous []multi.TradeStat, now int64) []multi.TradeStat {
+	type traders struct {
+		hour, day, week map[string]struct{}
+	}
+
+	stats := make(map[string]*multi.TradeStat)
+	users := make(map[string]*traders)
+	for _, stat := range previous {
+		stats[stat.TokenSymbol] = &multi.TradeStat{TokenSymbol: stat.TokenSymbol, TokenAddress: stat.TokenAddress}
+	}
+
+	for _, activity := range activities {
+		if activity.EventTime <= now-tradeStatWeek || activity.EventTime > now {
+			continue
+		}
+		stat, ok := stats[activity.TokenSymbol]
+		if !ok {
+			stat = &multi.TradeStat{TokenSymbol: activity.TokenSymbol}
+			stats[activity.TokenSymbol] = stat
+		}
+		if activity.TokenAddress != "" {
+			stat.TokenAddress = activity.TokenAddress
+		}
+		u, ok := users[activity.TokenSymbol]
+		if !ok {
+			u = &traders{hour: map[string]struct{}{}, day: map[string]struct{}{}, week: map[string]struct{}{}}
+			users[activity.TokenSymbol] = u
+		}
+
+		stat.Volume7d = stat.Volume7d.Add(activity.CurrencyAmount)
+		stat.Trades7d++
+		u.week[activity.UserAddress] = struct{}{}
+		if activity.EventTime > now-tradeStatDay {
+			stat.Volume24h = stat.Volume24h.Add(activity.CurrencyAmount)
+			stat.Trades24h++
+			u.day[activity.UserAddress] = struct{}{}
+		}
+		if activity.EventTime > now-tradeStatHour {
+			stat.Volume1h = stat.Volume1h.Add(activity.CurrencyAmount)
+			stat.Trades1h++
+			u.hour[activity.UserAddress] = struct{}{}
+		}
+	}
+
+	list := make([]multi.TradeStat, 0, len(stats))
+	for symbol, stat := range stats {
+		if u, ok := users[symbol]; ok {
+			stat.Traders1h, stat.Traders24h, stat.Traders7d = int64(len(u.hour)), int64(len(u.day)), int64(len(u.week))
+		}
+		list = append(list, *stat)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].TokenSymbol < list[j].TokenSymbol
+	})
+	normalizeTradeStats(list, now)
+	return list
+}
+
+// normalizeTradeStats 将汇总出的成交额由链上原始精度换算为USDT，并记录统计时间
+func normalizeTradeStats(stats []multi.TradeStat, now int64) {
+	for i := range stats {
+		stats[i].Volume1h = stats[i].Volume1h.Shift(-usdtDecimals)
+		stats[i].Volume24h = stats[i].Volume24h.Shift(-usdtDecimals)
+		stats[i].Volume7d = stats[i].Volume7d.Shift(-usdtDecimals)
+		stats[i].StatTime = now
+	}
+}
@@ -0,0 +1,117 @@
+package stocktokenindexer
+
+import (
+	"testing"
+
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeTradeStats(t *testing.T) {
+	stats := []multi.TradeStat{
+		{
+			TokenSymbol: "AAPL",
+			Volume1h:    decimal.RequireFromString("1500000"),
+			Volume24h:   decimal.RequireFromString("2000123456"),
+			Volume7d:    decimal.RequireFromString("99000000000"),
+			Trades1h:    1,
+			Trades24h:   3,
+			Trades7d:    40,
+		},
+		{TokenSymbol: "TSLA", Volume1h: decimal.Zero, Volume24h: decimal.Zero, Volume7d: decimal.RequireFromString("1")},
+	}
+	now := int64(1700000000)
+
+	normalizeTradeStats(stats, now)
+
+	expected := []struct {
+		volume1h, volume24h, volume7d string
+	}{
+		{"1.5", "2000.123456", "99000"},
+		{"0", "0", "0.000001"},
+	}
+	for i, want := range expected {
+		stat := stats[i]
+		if !stat.Volume1h.Equal(decimal.RequireFromString(want.volume1h)) ||
+			!stat.Volume24h.Equal(decimal.RequireFromString(want.volume24h)) ||
+			!stat.Volume7d.Equal(decimal.RequireFromString(want.volume7d)) {
+			t.Errorf("%s: volumes = %s/%s/%s, want %s/%s/%s", stat.TokenSymbol,
+				stat.Volume1h, stat.Volume24h, stat.Volume7d, want.volume1h, want.volume24h, want.volume7d)
+		}
+		if stat.StatTime != now {
+			t.Errorf("%s: stat time = %d, want %d", stat.TokenSymbol, stat.StatTime, now)
+		}
+	}
+	// 成交笔数不做换算
+	if stats[0].Trades1h != 1 || stats[0].Trades24h != 3 || stats[0].Trades7d != 40 {
+		t.Errorf("trade counts changed: %d/%d/%d", stats[0].Trades1h, stats[0].Trades24h, stats[0].Trades7d)
+	}
+}
+
+func testTradeActivity(symbol, user, amount string, eventTime int64) multi.Activity {
+	return multi.Activity{
+		TokenSymbol:    symbol,
+		TokenAddress:   "0x" + symbol,
+		UserAddress:    user,
+		CurrencyAmount: decimal.RequireFromString(amount).Shift(usdtDecimals),
+		EventTime:      eventTime,
+	}
+}
+
+func TestAggregateTradeStats(t *testing.T) {
+	now := int64(1700000000)
+	activities := []multi.Activity{
+		testTradeActivity("AAPL", "alice", "10", now),                 // 窗口右边界计入所有窗口
+		testTradeActivity("AAPL", "alice", "20", now-tradeStatHour+1), // 1小时内
+		testTradeActivity("AAPL", "bob", "30", now-tradeStatHour),     // 恰好1小时前，不计入1小时
+		testTradeActivity("AAPL", "carol", "40", now-tradeStatDay),    // 恰好24小时前，只计入7天
+		testTradeActivity("AAPL", "bob", "50", now-tradeStatWeek+1),   // 7天内
+		testTradeActivity("AAPL", "dave", "1000", now-tradeStatWeek),  // 恰好7天前，不计入
+		testTradeActivity("AAPL", "erin", "1000", now+1),              // 晚于统计时间，不计入
+		testTradeActivity("TSLA", "alice", "5", now-2*tradeStatHour),
+	}
+	previous := []multi.TradeStat{
+		{TokenSymbol: "AAPL", TokenAddress: "0xAAPL"},
+		{TokenSymbol: "MSFT", TokenAddress: "0xMSFT"}, // 最近7天没有成交，统计清零
+	}
+
+	stats := aggregateTradeStats(activities, previous, now)
+
+	expected := []struct {
+		symbol, address                  string
+		volume1h, volume24h, volume7d    string
+		trades1h, trades24h, trades7d    int64
+		traders1h, traders24h, traders7d int64
+	}{
+		{"AAPL", "0xAAPL", "30", "60", "150", 2, 3, 5, 1, 2, 3},
+		{"MSFT", "0xMSFT", "0", "0", "0", 0, 0, 0, 0, 0, 0},
+		{"TSLA", "0xTSLA", "0", "5", "5", 0, 1, 1, 0, 1, 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("got %d stats, want %d", len(stats), len(expected))
+	}
+	for i, want := range expected {
+		stat := stats[i]
+		if stat.TokenSymbol != want.symbol || stat.TokenAddress != want.address {
+			t.Errorf("stat %d = %s/%s, want %s/%s", i, stat.TokenSymbol, stat.TokenAddress, want.symbol, want.address)
+			continue
+		}
+		if !stat.Volume1h.Equal(decimal.RequireFromString(want.volume1h)) ||
+			!stat.Volume24h.Equal(decimal.RequireFromString(want.volume24h)) ||
+			!stat.Volume7d.Equal(decimal.RequireFromString(want.volume7d)) {
+			t.Errorf("%s: volumes = %s/%s/%s, want %s/%s/%s", want.symbol,
+				stat.Volume1h, stat.Volume24h, stat.Volume7d, want.volume1h, want.volume24h, want.volume7d)
+		}
+		if stat.Trades1h != want.trades1h || stat.Trades24h != want.trades24h || stat.Trades7d != want.trades7d {
+			t.Errorf("%s: trades = %d/%d/%d, want %d/%d/%d", want.symbol,
+				stat.Trades1h, stat.Trades24h, stat.Trades7d, want.trades1h, want.trades24h, want.trades7d)
+		}
+		if stat.Traders1h != want.traders1h || stat.Traders24h != want.traders24h || stat.Traders7d != want.traders7d {
+			t.Errorf("%s: traders = %d/%d/%d, want %d/%d/%d", want.symbol,
+				stat.Traders1h, stat.Traders24h, stat.Traders7d, want.traders1h, want.traders24h, want.traders7d)
+		}
+		if stat.StatTime != now {
+			t.Errorf("%s: stat time = %d, want %d", want.symbol, stat.StatTime, now)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package stocktokenindexer
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/locey/CryptoStock/StockCoinBase/chain/types"
+	"github.com/locey/CryptoStock/StockCoinBase/logger/xzap"
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backfillBlockPeriod 回填余额时每次拉取日志的区块数
+const backfillBlockPeriod = 2000
+
+// touchedToken 同步区间内发生过转账的代币，用于更新持有人数量
+type touchedToken struct {
+	symbol      string
+	blockNumber uint64
+	eventTime   uint64
+}
+
+// balanceDelta 一笔转账对某个持有人余额的影响
+type balanceDelta struct {
+	holder common.Address
+	amount decimal.Decimal
+}
+
+// transferDeltas 计算转账对双方余额的影响；自己转给自己不改变余额，铸造、销毁时的0地址不计入余额
+func transferDeltas(from, to common.Address, amount decimal.Decimal) []balanceDelta {
+	if from == to {
+		return nil
+	}
+	var deltas []balanceDelta
+	if from != (common.Address{}) {
+		deltas = append(deltas, balanceDelta{holder: from, amount: amount.Neg()})
+	}
+	if to != (common.Address{}) {
+		deltas = append(deltas, balanceDelta{holder: to, amount: amount})
+	}
+	return deltas
+}
+
+// parseTransfer 将Transfer日志解析为转账记录，非标准的Transfer日志返回nil
+func (s *Service) parseTransfer(log ethereumTypes.Log, blockTimes map[uint64]uint64) (*multi.Transfer, error) {
+	if len(log.Topics) < 3 {
+		return nil, nil
+	}
+
+	var event struct {
+		Value *big.Int
+	}
+	if err := s.parsedAbi.UnpackIntoInterface(&event, "Transfer", log.Data); err != nil {
+		return nil, errors.Wrap(err, "failed on unpack Transfer event")
+	}
+	from := common.BytesToAddress(log.Topics[1].Bytes())
+	to := common.BytesToAddress(log.Topics[2].Bytes())
+
+	blockTime, err := s.blockTime(log.BlockNumber, blockTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	symbol := s.tokens[log.Address]
+	s.mu.RUnlock()
+
+	return &multi.Transfer{
+		TokenAddress: log.Address.String(),
+		TokenSymbol:  symbol,
+		FromAddress:  from.String(),
+		ToAddress:    to.String(),
+		Amount:       decimal.NewFromBigInt(event.Value, 0),
+		BlockNumber:  int64(log.BlockNumber),
+		TxHash:       log.TxHash.String(),
+		LogIndex:     int64(log.Index),
+		EventTime:    int64(blockTime),
+	}, nil
+}
+
+// handleTransferEvent 写入转账记录并在同一事务中更新双方余额，记录已存在时不重复计入余额
+func (s *Service) handleTransferEvent(log ethereumTypes.Log, blockTimes map[uint64]uint64, touched map[common.Address]*touchedToken) error {
+	transfer, err := s.parseTransfer(log, blockTimes)
+	if err != nil || transfer == nil {
+		return err
+	}
+
+	if err := s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(multi.TransferTableName(s.chain)).Clauses(clause.OnConflict{
+			DoNothing: true,
+		}).Create(transfer)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "failed on create transfer")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		deltas := transferDeltas(common.HexToAddress(transfer.FromAddress), common.HexToAddress(transfer.ToAddress), transfer.Amount)
+		for _, delta := range deltas {
+			if err := s.addBalance(tx, transfer, delta.holder, delta.amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	touched[log.Address] = &touchedToken{symbol: transfer.TokenSymbol, blockNumber: log.BlockNumber, eventTime: uint64(transfer.EventTime)}
+	return nil
+}
+
+func (s *Service) addBalance(tx *gorm.DB, transfer *multi.Transfer, holder common.Address, delta decimal.Decimal) error {
+	balance := multi.Balance{
+		TokenAddress:    transfer.TokenAddress,
+		TokenSymbol:     transfer.TokenSymbol,
+		HolderAddress:   holder.String(),
+		Balance:         delta,
+		LastBlockNumber: transfer.BlockNumber,
+	}
+	if err := tx.Table(multi.BalanceTableName(s.chain)).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token_address"}, {Name: "holder_address"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"balance":           gorm.Expr("balance + ?", delta),
+			"last_block_number": transfer.BlockNumber,
+			"update_time":       time.Now().UnixMilli(),
+		}),
+	}).Create(&balance).Error; err != nil {
+		return errors.Wrap(err, "failed on update balance")
+	}
+	return nil
+}
+
+// accumulateBalances 按顺序累计转账记录得到各持有人余额，key为代币地址/持有人地址
+func accumulateBalances(transfers []*multi.Transfer) map[string]*multi.Balance {
+	balances := make(map[string]*multi.Balance)
+	for _, transfer := range transfers {
+		deltas := transferDeltas(common.HexToAddress(transfer.FromAddress), common.HexToAddress(transfer.ToAddress), transfer.Amount)
+		for _, delta := range deltas {
+			key := transfer.TokenAddress + "/" + delta.holder.String()
+			balance, ok := balances[key]
+			if !ok {
+				balance = &multi.Balance{
+					TokenAddress:  transfer.TokenAddress,
+					TokenSymbol:   transfer.TokenSymbol,
+					HolderAddress: delta.holder.String(),
+				}
+				balances[key] = balance
+			}
+			balance.Balance = balance.Balance.Add(delta.amount)
+			balance.LastBlockNumber = transfer.BlockNumber
+		}
+	}
+	return balances
+}
+
+// backfillBalances 余额表为空而已有代币时（例如转账索引上线前已同步过买卖事件），从最早的代币创建区块起
+// 重新扫描已同步区间[代币创建区块, endBlock)内的Transfer事件，在同一事务中写入转账记录并生成余额
+func (s *Service) backfillBalances(endBlock uint64) error {
+	addresses := s.TokenAddresses()
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	var balanceCount int64
+	if err := s.db.WithContext(s.ctx).Table(multi.BalanceTableName(s.chain)).
+		Count(&balanceCount).Error; err != nil {
+		return errors.Wrap(err, "failed on count balances")
+	}
+	if balanceCount > 0 {
+		return nil
+	}
+
+	var startBlock int64
+	if err := s.db.WithContext(s.ctx).Table(multi.TokenTableName(s.chain)).
+		Select("COALESCE(MIN(block_number), 0)").Scan(&startBlock).Error; err != nil {
+		return errors.Wrap(err, "failed on query stock token start block")
+	}
+	if uint64(startBlock) >= endBlock {
+		return nil
+	}
+
+	blockTimes := make(map[uint64]uint64)
+	var transfers []*multi.Transfer
+	for fromBlock := uint64(startBlock); fromBlock < endBlock; fromBlock += backfillBlockPeriod {
+		toBlock := min(fromBlock+backfillBlockPeriod-1, endBlock-1)
+		logs, err := s.chainClient.FilterLogs(s.ctx, types.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(fromBlock),
+			ToBlock:   new(big.Int).SetUint64(toBlock),
+			Addresses: addresses,
+			Topics:    [][]string{{LogTransferTopic}},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed on get stock token transfer log")
+		}
+		for _, log := range logs {
+			ethLog := log.(ethereumTypes.Log)
+			if ethLog.Removed {
+				continue
+			}
+			transfer, err := s.parseTransfer(ethLog, blockTimes)
+			if err != nil {
+				return err
+			}
+			if transfer != nil {
+				transfers = append(transfers, transfer)
+			}
+		}
+	}
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	balances := accumulateBalances(transfers)
+	if err := s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(multi.TransferTableName(s.chain)).Clauses(clause.OnConflict{
+			DoNothing: true,
+		}).CreateInBatches(transfers, 500).Error; err != nil {
+			return errors.Wrap(err, "failed on create transfers")
+		}
+		list := make([]*multi.Balance, 0, len(balances))
+		for _, balance := range balances {
+			list = append(list, balance)
+		}
+		if err := tx.Table(multi.BalanceTableName(s.chain)).CreateInBatches(list, 500).Error; err != nil {
+			return errors.Wrap(err, "failed on create balances")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	touched := make(map[common.Address]*touchedToken)
+	for _, transfer := range transfers {
+		touched[common.HexToAddress(transfer.TokenAddress)] = &touchedToken{
+			symbol:      transfer.TokenSymbol,
+			blockNumber: uint64(transfer.BlockNumber),
+			eventTime:   uint64(transfer.EventTime),
+		}
+	}
+	xzap.WithContext(s.ctx).Info("backfill stock token balances from transfers",
+		zap.Int("transfers", len(transfers)), zap.Int("balances", len(balances)))
+	return s.updateHolderCounts(touched)
+}
+
+// updateHolderCounts 重新统计本次同步中发生过转账的代币的持有人数量，记入最后一次转账当天的历史；
+// 代币合约自身持有的是买卖池库存，不计为持有人
+func (s *Service) updateHolderCounts(touched map[common.Address]*touchedToken) error {
+	for token, info := range touched {
+		var count int64
+		if err := s.db.WithContext(s.ctx).Table(multi.BalanceTableName(s.chain)).
+			Where("token_address = ? and holder_address <> ? and balance > 0", token.String(), token.String()).
+			Count(&count).Error; err != nil {
+			return errors.Wrap(err, "failed on count holders")
+		}
+
+		day := time.Unix(int64(info.eventTime), 0).UTC().Truncate(24 * time.Hour).Unix()
+		holderCount := multi.HolderCount{
+			TokenAddress: token.String(),
+			TokenSymbol:  info.symbol,
+			Day:          day,
+			HolderCount:  count,
+			BlockNumber:  int64(info.blockNumber),
+		}
+		if err := s.db.WithContext(s.ctx).Table(multi.HolderCountTableName(s.chain)).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token_address"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"holder_count": count,
+				"block_number": info.blockNumber,
+				"update_time":  time.Now().UnixMilli(),
+			}),
+		}).Create(&holderCount).Error; err != nil {
+			return errors.Wrap(err, "failed on update holder count")
+		}
+	}
+	return nil
+}
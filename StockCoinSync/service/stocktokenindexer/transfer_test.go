@@ -0,0 +1,98 @@
+package stocktokenindexer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/locey/CryptoStock/StockCoinBase/stores/gdb/stocktokenmodel/multi"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	testToken = common.HexToAddress("0xa3BCE5f241991cc4c0FeBFE33C14e7cFaE864a9c")
+	testAlice = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testBob   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func TestTransferDeltas(t *testing.T) {
+	amount := decimal.RequireFromString("5")
+	tests := []struct {
+		name     string
+		from, to common.Address
+		want     map[common.Address]string
+	}{
+		{"mint", common.Address{}, testAlice, map[common.Address]string{testAlice: "5"}},
+		{"burn", testAlice, common.Address{}, map[common.Address]string{testAlice: "-5"}},
+		{"transfer", testAlice, testBob, map[common.Address]string{testAlice: "-5", testBob: "5"}},
+		{"self transfer", testAlice, testAlice, map[common.Address]string{}},
+	}
+	for _, tt := range tests {
+		deltas := transferDeltas(tt.from, tt.to, amount)
+		if len(deltas) != len(tt.want) {
+			t.Errorf("%s: got %d deltas, want %d", tt.name, len(deltas), len(tt.want))
+			continue
+		}
+		for _, delta := range deltas {
+			want, ok := tt.want[delta.holder]
+			if !ok {
+				t.Errorf("%s: unexpected holder %s", tt.name, delta.holder)
+				continue
+			}
+			if !delta.amount.Equal(decimal.RequireFromString(want)) {
+				t.Errorf("%s: %s delta = %s, want %s", tt.name, delta.holder, delta.amount, want)
+			}
+		}
+	}
+}
+
+func testTransfer(from, to common.Address, amount string, blockNumber int64) *multi.Transfer {
+	return &multi.Transfer{
+		TokenAddress: testToken.String(),
+		TokenSymbol:  "AAPL",
+		FromAddress:  from.String(),
+		ToAddress:    to.String(),
+		Amount:       decimal.RequireFromString(amount),
+		BlockNumber:  blockNumber,
+	}
+}
+
+func TestAccumulateBalances(t *testing.T) {
+	transfers := []*multi.Transfer{
+		testTransfer(common.Address{}, testToken, "100", 1), // 铸造到代币合约作为买卖池库存
+		testTransfer(testToken, testAlice, "10", 2),
+		testTransfer(testAlice, testBob, "4", 3),
+		testTransfer(testBob, testBob, "4", 4),
+		testTransfer(testAlice, common.Address{}, "1", 5),
+	}
+
+	balances := accumulateBalances(transfers)
+
+	want := map[common.Address]struct {
+		balance   string
+		lastBlock int64
+	}{
+		testToken: {"90", 2},
+		testAlice: {"5", 5},
+		testBob:   {"4", 3},
+	}
+	if len(balances) != len(want) {
+		t.Fatalf("got %d balances, want %d", len(balances), len(want))
+	}
+	for holder, w := range want {
+		balance, ok := balances[testToken.String()+"/"+holder.String()]
+		if !ok {
+			t.Errorf("missing balance for %s", holder)
+			continue
+		}
+		if !balance.Balance.Equal(decimal.RequireFromString(w.balance)) || balance.LastBlockNumber != w.lastBlock {
+			t.Errorf("%s: balance = %s at block %d, want %s at block %d",
+				holder, balance.Balance, balance.LastBlockNumber, w.balance, w.lastBlock)
+		}
+		if balance.TokenSymbol != "AAPL" {
+			t.Errorf("%s: symbol = %q, want AAPL", holder, balance.TokenSymbol)
+		}
+	}
+	if _, ok := balances[testToken.String()+"/"+common.Address{}.String()]; ok {
+		t.Errorf("zero address must not have a balance")
+	}
+}